@@ -0,0 +1,265 @@
+//+build integration
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/hmoragrega/paybile/repository/postgres"
+	_ "github.com/lib/pq"
+)
+
+const (
+	dbImage        = "postgres:9.6"
+	migrationsPath = "../../migrations"
+)
+
+func TestMigrator(t *testing.T) {
+	db := setupTestDB(t)
+	m, src, err := newMigrate(db, migrationsPath)
+	if err != nil {
+		t.Fatalf("cannot instantiate migrator: %v", err)
+	}
+
+	var (
+		latest  = uint(postgres.SchemaVersion)
+		to      = func(v int) optionalInt { return optionalInt{value: v, set: true} }
+		version = func(t *testing.T) state {
+			t.Helper()
+			s, err := currentState(m)
+			if err != nil {
+				t.Fatalf("cannot get the current state: %v", err)
+			}
+			return s
+		}
+		exec = func(t *testing.T, query string) {
+			t.Helper()
+			if _, err := db.Exec(query); err != nil {
+				t.Fatalf("cannot execute %q: %v", query, err)
+			}
+		}
+	)
+
+	// The steps run in order on the same database.
+	steps := []struct {
+		name       string
+		setup      func(t *testing.T)
+		config     config
+		want       state
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "status of an empty database",
+			config:     config{direction: directionUp, status: true},
+			wantOutput: "version: none\ndirty: false\npending:\n  1/u",
+		},
+		{
+			name:       "dry run does not migrate",
+			config:     config{direction: directionUp, steps: 1, dryRun: true},
+			wantOutput: "-- 1/u",
+		},
+		{
+			name:   "up steps",
+			config: config{direction: directionUp, steps: 2},
+			want:   state{version: 2, migrated: true},
+		},
+		{
+			name:   "up to version",
+			config: config{direction: directionUp, toVersion: to(5)},
+			want:   state{version: 5, migrated: true},
+		},
+		{
+			name:   "down steps",
+			config: config{direction: directionDown, steps: 1},
+			want:   state{version: 4, migrated: true},
+		},
+		{
+			name:   "up all",
+			config: config{direction: directionUp},
+			want:   state{version: latest, migrated: true},
+		},
+		{
+			name:       "status of an up to date database",
+			config:     config{direction: directionUp, status: true},
+			want:       state{version: latest, migrated: true},
+			wantOutput: fmt.Sprintf("version: %d\ndirty: false\npending: none\n", latest),
+		},
+		{
+			name:    "dirty database",
+			setup:   func(t *testing.T) { exec(t, `UPDATE schema_migrations SET dirty = true`) },
+			config:  config{direction: directionDown},
+			want:    state{version: latest, migrated: true, dirty: true},
+			wantErr: true,
+		},
+		{
+			name:   "force clears the dirty flag",
+			config: config{direction: directionUp, force: to(int(latest))},
+			want:   state{version: latest, migrated: true},
+		},
+		{
+			name:   "down to version",
+			config: config{direction: directionUp, toVersion: to(3)},
+			want:   state{version: 3, migrated: true},
+		},
+		{
+			name:   "down all",
+			config: config{direction: directionDown, all: true},
+			want:   state{},
+		},
+	}
+	for _, step := range steps {
+		if step.setup != nil {
+			step.setup(t)
+		}
+
+		var out bytes.Buffer
+		err := run(m, src, step.config, &out)
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if got := version(t); got != step.want {
+			t.Fatalf("%s: unexpected state: \n got:  %+v \n want: %+v", step.name, got, step.want)
+		}
+		if got := out.String(); !strings.Contains(got, step.wantOutput) {
+			t.Fatalf("%s: unexpected output: \n got:  %q \n want: %q", step.name, got, step.wantOutput)
+		}
+	}
+
+	// Every down migration has been applied.
+	var tables int
+	err = db.QueryRow(`SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'public' AND table_name != 'schema_migrations'`).Scan(&tables)
+	if err != nil {
+		t.Fatalf("cannot count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Fatalf("unexpected tables after migrating down: %d", tables)
+	}
+}
+
+// setupTestDB spins up a new empty instance of the
+// database. Returns an open connection to it.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	var (
+		user    = "test"
+		pass    = "test"
+		dbName  = "test"
+		timeout = time.Second * 10
+	)
+
+	host, port := createDBContainer(t, user, pass, timeout)
+	dsn := fmt.Sprintf(
+		"postgresql://%s:%s@%s:%s/%s?sslmode=disable",
+		user, pass, host, port, dbName,
+	)
+
+	return connectTestDB(t, dsn, timeout)
+}
+
+// createDBContainer creates a new DB docker container.
+// Returns the host address and port.
+func createDBContainer(t *testing.T, user, pass string, timeout time.Duration) (string, string) {
+	t.Helper()
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		t.Fatalf("cannot get a docker client instance: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c, err := cli.ContainerCreate(ctx,
+		&container.Config{
+			Image: dbImage,
+			Env:   []string{"POSTGRES_USER=" + user, "POSTGRES_PASSWORD=" + pass},
+			ExposedPorts: nat.PortSet{
+				"5432/tcp": struct{}{},
+			},
+		},
+		&container.HostConfig{
+			PublishAllPorts: true,
+		},
+		nil,
+		"",
+	)
+	if err != nil {
+		t.Fatalf("cannot create db container: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		}); err != nil {
+			t.Fatalf("cannot remove db container: %v", err)
+		}
+	})
+
+	if err = cli.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+		t.Fatalf("cannot start db container: %v", err)
+	}
+
+	l, err := cli.ContainerLogs(
+		ctx,
+		c.ID,
+		types.ContainerLogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Follow:     true,
+		},
+	)
+	go func() { _, _ = io.Copy(os.Stdout, l) }()
+
+	i, err := cli.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("cannot retrieve db container information: %v", err)
+	}
+	pm := i.NetworkSettings.Ports["5432/tcp"]
+
+	return pm[0].HostIP, pm[0].HostPort
+}
+
+// connectTestDB establishes a connection to the test database.
+func connectTestDB(t *testing.T, dsn string, timeout time.Duration) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("cannot open connection to db: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tick := time.NewTicker(time.Millisecond * 100)
+	for {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timeout connecting to db: %v", err)
+		case <-tick.C:
+			if err = db.PingContext(ctx); err == nil {
+				return db
+			}
+		}
+	}
+}
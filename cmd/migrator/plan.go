@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// step migration that will be applied in one direction.
+type step struct {
+	version    uint
+	up         bool
+	identifier string
+}
+
+func (s step) String() string {
+	d := "d"
+	if s.up {
+		d = "u"
+	}
+	if s.identifier == "" {
+		return fmt.Sprintf("%d/%s", s.version, d)
+	}
+	return fmt.Sprintf("%d/%s %s", s.version, d, s.identifier)
+}
+
+// state current version of the database schema.
+type state struct {
+	version uint
+	// migrated false if no migration has been applied.
+	migrated bool
+	dirty    bool
+}
+
+func currentState(m *migrate.Migrate) (state, error) {
+	v, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return state{}, nil
+	}
+	if err != nil {
+		return state{}, fmt.Errorf("cannot get schema version: %w", err)
+	}
+
+	return state{version: v, migrated: true, dirty: dirty}, nil
+}
+
+// plan returns the migrations that the config would apply
+// from the current state, in the order they are applied.
+func plan(src source.Driver, s state, c config) ([]step, error) {
+	if s.dirty {
+		return nil, fmt.Errorf("the schema is dirty at version %d, fix it and use -force", s.version)
+	}
+
+	if c.toVersion.set {
+		target := uint(c.toVersion.value)
+		if _, _, err := readStep(src, step{version: target, up: true}); err != nil {
+			return nil, fmt.Errorf("version %d not found: %w", target, err)
+		}
+		switch {
+		case !s.migrated || target > s.version:
+			return planUp(src, s, func(v uint, _ int) bool { return v <= target })
+		case target < s.version:
+			return planDown(src, s, func(v uint, _ int) bool { return v > target })
+		default:
+			return nil, nil
+		}
+	}
+
+	within := func(_ uint, n int) bool { return c.steps == 0 || n < c.steps }
+	if c.direction == directionDown {
+		return planDown(src, s, within)
+	}
+	return planUp(src, s, within)
+}
+
+// planUp returns the pending migrations while the
+// version and the number of steps are within limits.
+func planUp(src source.Driver, s state, within func(v uint, n int) bool) ([]step, error) {
+	var (
+		v   uint
+		err error
+	)
+	if s.migrated {
+		v, err = src.Next(s.version)
+	} else {
+		v, err = src.First()
+	}
+
+	var steps []step
+	for ; err == nil && within(v, len(steps)); v, err = src.Next(v) {
+		steps = append(steps, step{version: v, up: true})
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cannot read migrations: %w", err)
+	}
+
+	return identify(src, steps)
+}
+
+// planDown returns the applied migrations, from the current one
+// backwards, while the version and the number of steps are within limits.
+func planDown(src source.Driver, s state, within func(v uint, n int) bool) ([]step, error) {
+	if !s.migrated {
+		return nil, nil
+	}
+
+	var steps []step
+	for v := s.version; within(v, len(steps)); {
+		steps = append(steps, step{version: v})
+
+		prev, err := src.Prev(v)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migrations: %w", err)
+		}
+		v = prev
+	}
+
+	return identify(src, steps)
+}
+
+// identify sets the identifier of the steps.
+func identify(src source.Driver, steps []step) ([]step, error) {
+	for i, s := range steps {
+		r, id, err := readStep(src, s)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migration %d: %w", s.version, err)
+		}
+		_ = r.Close()
+		steps[i].identifier = id
+	}
+
+	return steps, nil
+}
+
+func readStep(src source.Driver, s step) (io.ReadCloser, string, error) {
+	if s.up {
+		return src.ReadUp(s.version)
+	}
+	return src.ReadDown(s.version)
+}
+
+// printStatus prints the current version of the schema and the pending migrations.
+func printStatus(w io.Writer, src source.Driver, s state) error {
+	if s.migrated {
+		fmt.Fprintf(w, "version: %d\n", s.version)
+	} else {
+		fmt.Fprintln(w, "version: none")
+	}
+	fmt.Fprintf(w, "dirty: %t\n", s.dirty)
+
+	pending, err := planUp(src, s, func(uint, int) bool { return true })
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Fprintln(w, "pending: none")
+		return nil
+	}
+	fmt.Fprintln(w, "pending:")
+	for _, p := range pending {
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+
+	return nil
+}
+
+// printPlan prints the SQL of the steps without applying them.
+func printPlan(w io.Writer, src source.Driver, steps []step) error {
+	if len(steps) == 0 {
+		fmt.Fprintln(w, "-- no migrations to apply")
+		return nil
+	}
+	for _, s := range steps {
+		fmt.Fprintf(w, "-- %s\n", s)
+		r, _, err := readStep(src, s)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(w, "-- no SQL to apply")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read migration %d: %w", s.version, err)
+		}
+		b, err := ioutil.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			return fmt.Errorf("cannot read migration %d: %w", s.version, err)
+		}
+		fmt.Fprintf(w, "%s\n", b)
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// testSource opens a source with the migrations 1, 2 and 3,
+// and the migration 5 which cannot be migrated down.
+func testSource(t *testing.T) source.Driver {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "migrations")
+	if err != nil {
+		t.Fatalf("cannot create migrations dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	files := map[string]string{
+		"001_users.up.sql":     "CREATE TABLE users (id int);",
+		"001_users.down.sql":   "DROP TABLE users;",
+		"002_wallets.up.sql":   "CREATE TABLE wallets (id int);",
+		"002_wallets.down.sql": "DROP TABLE wallets;",
+		"003_index.up.sql":     "CREATE INDEX wallets_id ON wallets (id);",
+		"003_index.down.sql":   "DROP INDEX wallets_id;",
+		"005_data.up.sql":      "INSERT INTO users VALUES (1);",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("cannot write migration: %v", err)
+		}
+	}
+
+	src, err := source.Open("file://" + dir)
+	if err != nil {
+		t.Fatalf("cannot open migrations: %v", err)
+	}
+	t.Cleanup(func() { _ = src.Close() })
+
+	return src
+}
+
+func TestPlan(t *testing.T) {
+	var (
+		src  = testSource(t)
+		at   = func(v uint) state { return state{version: v, migrated: true} }
+		up   = func(v uint, id string) step { return step{version: v, up: true, identifier: id} }
+		down = func(v uint, id string) step { return step{version: v, identifier: id} }
+		to   = func(v int) optionalInt { return optionalInt{value: v, set: true} }
+	)
+
+	tt := []struct {
+		name    string
+		state   state
+		config  config
+		want    []step
+		wantErr bool
+	}{
+		{
+			name:   "up from scratch",
+			config: config{direction: directionUp},
+			want:   []step{up(1, "users"), up(2, "wallets"), up(3, "index"), up(5, "data")},
+		},
+		{
+			name:   "up steps",
+			config: config{direction: directionUp, steps: 2},
+			want:   []step{up(1, "users"), up(2, "wallets")},
+		},
+		{
+			name:   "up pending",
+			state:  at(2),
+			config: config{direction: directionUp},
+			want:   []step{up(3, "index"), up(5, "data")},
+		},
+		{
+			name:   "up to date",
+			state:  at(5),
+			config: config{direction: directionUp},
+		},
+		{
+			name:   "down all",
+			state:  at(3),
+			config: config{direction: directionDown},
+			want:   []step{down(3, "index"), down(2, "wallets"), down(1, "users")},
+		},
+		{
+			name:   "down steps",
+			state:  at(5),
+			config: config{direction: directionDown, steps: 2},
+			want:   []step{down(5, ""), down(3, "index")},
+		},
+		{
+			name:   "down from scratch",
+			config: config{direction: directionDown},
+		},
+		{
+			name:   "up to version",
+			config: config{direction: directionUp, toVersion: to(3)},
+			want:   []step{up(1, "users"), up(2, "wallets"), up(3, "index")},
+		},
+		{
+			name:   "down to version",
+			state:  at(3),
+			config: config{direction: directionUp, toVersion: to(1)},
+			want:   []step{down(3, "index"), down(2, "wallets")},
+		},
+		{
+			name:   "at version",
+			state:  at(3),
+			config: config{direction: directionUp, toVersion: to(3)},
+		},
+		{
+			name:    "unknown version",
+			config:  config{direction: directionUp, toVersion: to(4)},
+			wantErr: true,
+		},
+		{
+			name:    "dirty",
+			state:   state{version: 2, migrated: true, dirty: true},
+			config:  config{direction: directionUp},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := plan(src, tc.state, tc.config)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected plan: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrintStatus(t *testing.T) {
+	src := testSource(t)
+
+	tt := []struct {
+		name  string
+		state state
+		want  string
+	}{
+		{
+			name: "not migrated",
+			want: "version: none\ndirty: false\npending:\n  1/u users\n  2/u wallets\n  3/u index\n  5/u data\n",
+		},
+		{
+			name:  "dirty",
+			state: state{version: 3, migrated: true, dirty: true},
+			want:  "version: 3\ndirty: true\npending:\n  5/u data\n",
+		},
+		{
+			name:  "up to date",
+			state: state{version: 5, migrated: true},
+			want:  "version: 5\ndirty: false\npending: none\n",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := printStatus(&b, src, tc.state); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := b.String(); got != tc.want {
+				t.Fatalf("unexpected status: \n got:  %q \n want: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	src := testSource(t)
+
+	var b bytes.Buffer
+	err := printPlan(&b, src, []step{
+		{version: 5},
+		{version: 3, identifier: "index"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "-- 5/d\n-- no SQL to apply\n-- 3/d index\nDROP INDEX wallets_id;\n"
+	if got := b.String(); got != want {
+		t.Fatalf("unexpected plan: \n got:  %q \n want: %q", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name    string
+		config  config
+		wantErr bool
+	}{
+		{
+			name:   "up",
+			config: config{direction: directionUp},
+		},
+		{
+			name:   "down steps",
+			config: config{direction: directionDown, steps: 1},
+		},
+		{
+			name:   "down to version",
+			config: config{direction: directionDown, toVersion: optionalInt{value: 3, set: true}},
+		},
+		{
+			name:   "down all",
+			config: config{direction: directionDown, all: true},
+		},
+		{
+			name:    "down without steps",
+			config:  config{direction: directionDown},
+			wantErr: true,
+		},
+		{
+			name:   "down dry run",
+			config: config{direction: directionDown, dryRun: true},
+		},
+		{
+			name:    "all going up",
+			config:  config{direction: directionUp, all: true},
+			wantErr: true,
+		},
+		{
+			name:    "all and steps",
+			config:  config{direction: directionDown, steps: 1, all: true},
+			wantErr: true,
+		},
+		{
+			name:    "unknown direction",
+			config:  config{direction: "sideways"},
+			wantErr: true,
+		},
+		{
+			name:    "negative steps",
+			config:  config{direction: directionUp, steps: -1},
+			wantErr: true,
+		},
+		{
+			name:    "version and steps",
+			config:  config{direction: directionUp, steps: 1, toVersion: optionalInt{value: 3, set: true}},
+			wantErr: true,
+		},
+		{
+			name:   "force nil version",
+			config: config{direction: directionUp, force: optionalInt{value: -1, set: true}},
+		},
+		{
+			name:    "force and dry run",
+			config:  config{direction: directionUp, force: optionalInt{value: 3, set: true}, dryRun: true},
+			wantErr: true,
+		},
+		{
+			name:    "status and version",
+			config:  config{direction: directionUp, status: true, toVersion: optionalInt{value: 3, set: true}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.config.validate(); (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
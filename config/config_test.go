@@ -0,0 +1,253 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/config"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("cannot write config file: %v", err)
+		}
+		return path
+	}
+	file := writeFile("config.yml", `
+http:
+  port: "8081"
+  handler_timeout: 10s
+db:
+  max_open_conns: 50
+features:
+  grpc: false
+`)
+
+	tt := []struct {
+		name    string
+		path    string
+		env     map[string]string
+		want    func(c *config.Config)
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: func(c *config.Config) {},
+		},
+		{
+			name: "file",
+			path: file,
+			want: func(c *config.Config) {
+				c.HTTP.Port = "8081"
+				c.HTTP.HandlerTimeout = 10 * time.Second
+				c.DB.MaxOpenConns = 50
+				c.Features.GRPC = false
+			},
+		},
+		{
+			name: "environment overrides the file",
+			path: file,
+			env: map[string]string{
+				"PAYBILE_HTTP_PORT":                           "8082",
+				"PAYBILE_DB_CONN_MAX_LIFETIME":                "1h",
+				"PAYBILE_FEATURES_RATE_LIMIT":                 "false",
+				"PAYBILE_LIMITS_TRANSFER_RATE_LIMIT_REQUESTS": "3",
+				"PAYBILE_LOG_FORMAT":                          "json",
+			},
+			want: func(c *config.Config) {
+				c.HTTP.Port = "8082"
+				c.HTTP.HandlerTimeout = 10 * time.Second
+				c.DB.MaxOpenConns = 50
+				c.DB.ConnMaxLifetime = time.Hour
+				c.Features.GRPC = false
+				c.Features.RateLimit = false
+				c.Limits.TransferRateLimit.Requests = 3
+				c.Log.Format = config.LogJSON
+			},
+		},
+		{
+			name:    "invalid environment variable",
+			env:     map[string]string{"PAYBILE_DB_MAX_OPEN_CONNS": "many"},
+			wantErr: "invalid environment variable PAYBILE_DB_MAX_OPEN_CONNS",
+		},
+		{
+			name:    "unknown key",
+			path:    writeFile("unknown.yml", "http:\n  prot: \"8081\"\n"),
+			wantErr: "field prot not found",
+		},
+		{
+			name:    "missing file",
+			path:    filepath.Join(dir, "missing.yml"),
+			wantErr: "cannot open config file",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			lookup := func(key string) (string, bool) {
+				v, ok := tc.env[key]
+				return v, ok
+			}
+			got, err := config.Load(tc.path, lookup)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("unexpected error: \n got:  %v \n want: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := config.Default()
+			tc.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("unexpected config: \n got:  %+v \n want: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name    string
+		modify  func(c *config.Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *config.Config) {},
+		},
+		{
+			name: "invalid values",
+			modify: func(c *config.Config) {
+				c.HTTP.Port = "http"
+				c.HTTP.HandlerTimeout = 0
+				c.DB.MaxIdleConns = 30
+				c.Log.Level = "loud"
+				c.Tracing.Exporter = "jaeger"
+				c.Limits.DefaultPerPage = 100
+				c.Limits.BatchRateLimit.Period = 0
+				c.Limits.IPRateLimit.Requests = -1
+			},
+			wantErr: `invalid config: ` +
+				`http.port "http" is not a valid port; ` +
+				`http.handler_timeout must be positive; ` +
+				`db.max_idle_conns cannot be greater than db.max_open_conns; ` +
+				`log.level "loud" is not valid; ` +
+				`tracing.exporter "jaeger" is not valid, use none, stdout or otlp; ` +
+				`limits.default_per_page must be between 1 and limits.max_per_page; ` +
+				`limits.batch_rate_limit.period must be positive; ` +
+				`limits.ip_rate_limit.requests cannot be negative`,
+		},
+		{
+			name: "same ports",
+			modify: func(c *config.Config) {
+				c.GRPC.Port = c.HTTP.Port
+			},
+			wantErr: `invalid config: grpc.port cannot be the same as http.port`,
+		},
+		{
+			name: "admin port",
+			modify: func(c *config.Config) {
+				c.Admin.Port = c.GRPC.Port
+			},
+			wantErr: `invalid config: admin.port cannot be the same as grpc.port`,
+		},
+		{
+			name: "admin port shared with the API",
+			modify: func(c *config.Config) {
+				c.Admin.Port = c.HTTP.Port
+			},
+			wantErr: `invalid config: admin.port cannot be the same as http.port`,
+		},
+		{
+			name: "gRPC port ignored when disabled",
+			modify: func(c *config.Config) {
+				c.Features.GRPC = false
+				c.GRPC.Port = ""
+			},
+		},
+		{
+			name: "otlp without endpoint",
+			modify: func(c *config.Config) {
+				c.Tracing.Exporter = "otlp"
+				c.Tracing.OTLPEndpoint = ""
+			},
+			wantErr: `invalid config: tracing.otlp_endpoint is required with the otlp exporter`,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.Default()
+			tc.modify(&c)
+
+			var got string
+			if err := c.Validate(); err != nil {
+				got = err.Error()
+			}
+			if got != tc.wantErr {
+				t.Fatalf("unexpected error: \n got:  %s \n want: %s", got, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	tt := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{
+			name: "url",
+			dsn:  "postgres://paybile:secret@db:5432/paybile?sslmode=disable",
+			want: "postgres://paybile:xxxxx@db:5432/paybile?sslmode=disable",
+		},
+		{
+			name: "url without password",
+			dsn:  "postgres://paybile@db:5432/paybile",
+			want: "postgres://paybile@db:5432/paybile",
+		},
+		{
+			name: "key value",
+			dsn:  "host=db user=paybile password=secret dbname=paybile",
+			want: "host=db user=paybile password=xxxxx dbname=paybile",
+		},
+		{
+			name: "quoted key value",
+			dsn:  "host=db password='my secret' dbname=paybile",
+			want: "host=db password=xxxxx dbname=paybile",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.Default()
+			c.DB.DSN = tc.dsn
+
+			if got := c.Redacted().DB.DSN; got != tc.want {
+				t.Fatalf("unexpected DSN: \n got:  %s \n want: %s", got, tc.want)
+			}
+			if c.DB.DSN != tc.dsn {
+				t.Fatalf("the original config has been modified")
+			}
+			if strings.Contains(c.String(), "secret") {
+				t.Fatalf("the printed config contains the secret: %s", c.String())
+			}
+		})
+	}
+}
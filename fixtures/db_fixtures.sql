@@ -1,4 +1,5 @@
 TRUNCATE users CASCADE; -- delete everything in cascade
+TRUNCATE journal_entries CASCADE; -- the ledger entries do not belong to any user
 
 INSERT INTO users (id, login, hashed_password)
 VALUES ('bbc00191-b064-4655-9075-261ccef978cb', 'user_a', 'user_a_pass'),
@@ -15,11 +16,27 @@ VALUES ('2f9b76dd-f689-456e-9080-6789718018a5', 'bbc00191-b064-4655-9075-261ccef
 INSERT INTO transfers (id, issuer_id, origin_wallet_id, destination_wallet_id, amount, date, message)
 VALUES ('97ca2b73-7988-4247-82d4-f6ba723a99c9', 'bbc00191-b064-4655-9075-261ccef978cb', '2f9b76dd-f689-456e-9080-6789718018a5', '4e1d841d-e53f-4785-ba4d-99df05f11eee', 7.25, '2020-09-19 10:00:02+00:00', 'dinner');
 
-INSERT INTO transactions (id, wallet_id, amount, balance, date, transaction_type, reference_id)
-VALUES ('9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc', '2f9b76dd-f689-456e-9080-6789718018a5', 20.00, 20.00, '2020-09-20 10:00:00+00:00', 'deposit', NULL),
-       ('7b63d966-a700-4d4f-b12c-e490bc96fd8c', '4e1d841d-e53f-4785-ba4d-99df05f11eee', 45.00, 45.00, '2020-09-20 10:00:01+00:00', 'deposit', NULL),
-       ('72db21de-9a63-40c6-b666-d35cf8437fd5', 'f889299f-41c4-4e58-96c2-7451c8276842',  9.50,  9.50, '2020-09-20 10:00:01+00:00', 'deposit', NULL),
-       ('be3c602a-4410-475b-b39f-016c451726a1', 'f889299f-41c4-4e58-96c2-7451c8276842', 08.50, 18.00, '2020-09-20 10:00:02+00:00', 'deposit', NULL),
-       ('ccf28188-92c7-4a30-8f60-70345694f893', 'f889299f-41c4-4e58-96c2-7451c8276842', 12.50, 30.50, '2020-09-20 10:00:02+00:00', 'deposit', NULL),
-       ('4bce4401-6b35-4fa1-94b9-ac5ce05d29b1', '2f9b76dd-f689-456e-9080-6789718018a5', -7.25, 12.75, '2020-09-20 11:10:00+00:00', 'transfer', '97ca2b73-7988-4247-82d4-f6ba723a99c9'),
-       ('60978032-b118-4727-a123-4468dced4104', '4e1d841d-e53f-4785-ba4d-99df05f11eee',  7.25, 52.25, '2020-09-20 11:10:00+00:00', 'transfer', '97ca2b73-7988-4247-82d4-f6ba723a99c9');
+INSERT INTO transfers (id, issuer_id, origin_wallet_id, destination_wallet_id, amount, date, message, status, execute_at)
+VALUES ('5b1d4e0c-3f3e-4c2a-9a43-2a3f1c9e8d71', 'bbc00191-b064-4655-9075-261ccef978cb', '2f9b76dd-f689-456e-9080-6789718018a5', '4e1d841d-e53f-4785-ba4d-99df05f11eee', 5.00, '2020-09-21 09:00:00+00:00', 'rent', 'scheduled', '2099-01-01 00:00:00+00:00');
+
+INSERT INTO journal_entries (id, entry_type, reference_id, date)
+VALUES ('9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc', 'deposit', NULL, '2020-09-20 10:00:00+00:00'),
+       ('7b63d966-a700-4d4f-b12c-e490bc96fd8c', 'deposit', NULL, '2020-09-20 10:00:01+00:00'),
+       ('72db21de-9a63-40c6-b666-d35cf8437fd5', 'deposit', NULL, '2020-09-20 10:00:01+00:00'),
+       ('be3c602a-4410-475b-b39f-016c451726a1', 'deposit', NULL, '2020-09-20 10:00:02+00:00'),
+       ('ccf28188-92c7-4a30-8f60-70345694f893', 'deposit', NULL, '2020-09-20 10:00:02+00:00'),
+       ('4bce4401-6b35-4fa1-94b9-ac5ce05d29b1', 'transfer', '97ca2b73-7988-4247-82d4-f6ba723a99c9', '2020-09-20 11:10:00+00:00');
+
+INSERT INTO postings (id, journal_entry_id, wallet_id, system_account, amount, balance)
+VALUES ('9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc', '9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc', '2f9b76dd-f689-456e-9080-6789718018a5', NULL, 20.00, 20.00),
+       ('0c1f4f3e-5a0e-4b59-9f6a-0d8e6f1f2a01', '9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc', NULL, 'cash_in', -20.00, NULL),
+       ('7b63d966-a700-4d4f-b12c-e490bc96fd8c', '7b63d966-a700-4d4f-b12c-e490bc96fd8c', '4e1d841d-e53f-4785-ba4d-99df05f11eee', NULL, 45.00, 45.00),
+       ('0c1f4f3e-5a0e-4b59-9f6a-0d8e6f1f2a02', '7b63d966-a700-4d4f-b12c-e490bc96fd8c', NULL, 'cash_in', -45.00, NULL),
+       ('72db21de-9a63-40c6-b666-d35cf8437fd5', '72db21de-9a63-40c6-b666-d35cf8437fd5', 'f889299f-41c4-4e58-96c2-7451c8276842', NULL,  9.50,  9.50),
+       ('0c1f4f3e-5a0e-4b59-9f6a-0d8e6f1f2a03', '72db21de-9a63-40c6-b666-d35cf8437fd5', NULL, 'cash_in', -9.50, NULL),
+       ('be3c602a-4410-475b-b39f-016c451726a1', 'be3c602a-4410-475b-b39f-016c451726a1', 'f889299f-41c4-4e58-96c2-7451c8276842', NULL, 08.50, 18.00),
+       ('0c1f4f3e-5a0e-4b59-9f6a-0d8e6f1f2a04', 'be3c602a-4410-475b-b39f-016c451726a1', NULL, 'cash_in', -8.50, NULL),
+       ('ccf28188-92c7-4a30-8f60-70345694f893', 'ccf28188-92c7-4a30-8f60-70345694f893', 'f889299f-41c4-4e58-96c2-7451c8276842', NULL, 12.50, 30.50),
+       ('0c1f4f3e-5a0e-4b59-9f6a-0d8e6f1f2a05', 'ccf28188-92c7-4a30-8f60-70345694f893', NULL, 'cash_in', -12.50, NULL),
+       ('4bce4401-6b35-4fa1-94b9-ac5ce05d29b1', '4bce4401-6b35-4fa1-94b9-ac5ce05d29b1', '2f9b76dd-f689-456e-9080-6789718018a5', NULL, -7.25, 12.75),
+       ('60978032-b118-4727-a123-4468dced4104', '4bce4401-6b35-4fa1-94b9-ac5ce05d29b1', '4e1d841d-e53f-4785-ba4d-99df05f11eee', NULL,  7.25, 52.25);
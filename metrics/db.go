@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: "db",
+	Name:      "query_duration_seconds",
+	Help:      "Duration of the database queries by query name and operation.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"query", "operation"})
+
+// unnamedQuery label of the queries executed without a name.
+const unnamedQuery = "unnamed"
+
+// queryCtxKey context key that holds the name of the queries.
+type queryCtxKey struct{}
+
+// WithQuery returns a context that labels the queries executed with it
+// with the given name, like the repository method that runs them, so
+// the queries with the same SQL command can be told apart.
+func WithQuery(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryCtxKey{}, name)
+}
+
+// queryName returns the name of the queries executed with the context.
+func queryName(ctx context.Context) string {
+	if name, ok := ctx.Value(queryCtxKey{}).(string); ok && name != "" {
+		return name
+	}
+	return unnamedQuery
+}
+
+// RegisterDB registers the connection pool stats of the database.
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// InstrumentConnector returns a connector whose connections record
+// the duration of the queries and statements executed by the
+// repositories, labelled by the name of the query given with
+// WithQuery and the SQL operation.
+func InstrumentConnector(c driver.Connector) driver.Connector {
+	return &connector{Connector: c}
+}
+
+type connector struct {
+	driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{Conn: cn}, nil
+}
+
+// conn forwards the calls to the driver connection, measuring the
+// queries. Prepared statements are not measured.
+type conn struct {
+	driver.Conn
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, errors.New("the driver does not support transaction options")
+	}
+
+	return c.Conn.Begin()
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer observeQuery(ctx, query, time.Now())
+
+	return q.QueryContext(ctx, query, args)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer observeQuery(ctx, query, time.Now())
+
+	return e.ExecContext(ctx, query, args)
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func observeQuery(ctx context.Context, query string, start time.Time) {
+	dbQueryDuration.WithLabelValues(queryName(ctx), queryOperation(query)).Observe(time.Since(start).Seconds())
+}
+
+// queryOperation returns the SQL command of the query, the
+// label is bounded to the commands the repositories use.
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other"
+	}
+
+	switch op := strings.ToLower(fields[0]); op {
+	case "select", "insert", "update", "delete", "with", "begin", "commit", "rollback", "notify", "listen":
+		return op
+	default:
+		return "other"
+	}
+}
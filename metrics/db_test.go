@@ -0,0 +1,132 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentConnector(t *testing.T) {
+	db := sql.OpenDB(metrics.InstrumentConnector(fakeConnector{}))
+	defer db.Close()
+
+	if err := metrics.RegisterDB(db, "fake"); err != nil {
+		t.Fatalf("cannot register the database: %v", err)
+	}
+
+	ctx := metrics.WithQuery(context.Background(), "FooRepository.CreateFoo")
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("cannot begin transaction: %v", err)
+	}
+	if _, err = tx.ExecContext(ctx, "INSERT INTO foo VALUES ($1)", 1); err != nil {
+		t.Fatalf("cannot exec: %v", err)
+	}
+	rows, err := tx.QueryContext(ctx, "\n\tSELECT id FROM foo")
+	if err != nil {
+		t.Fatalf("cannot query: %v", err)
+	}
+	_ = rows.Close()
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("cannot commit: %v", err)
+	}
+	if _, err = db.ExecContext(context.Background(), "VACUUM"); err != nil {
+		t.Fatalf("cannot exec: %v", err)
+	}
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("cannot gather metrics: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, mf := range mfs {
+		if mf.GetName() != "paybile_db_query_duration_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			got[labels["query"]+" "+labels["operation"]] = true
+		}
+	}
+	// the named insert and select and the unnamed other.
+	for _, want := range []string{
+		"FooRepository.CreateFoo insert",
+		"FooRepository.CreateFoo select",
+		"unnamed other",
+	} {
+		if !got[want] {
+			t.Fatalf("missing query series %q, got: %v", want, got)
+		}
+	}
+	if n, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "paybile_db_query_duration_seconds"); err != nil || n != 3 {
+		t.Fatalf("unexpected number of query series: %d, err: %v", n, err)
+	}
+	if n, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "go_sql_open_connections"); err != nil || n != 1 {
+		t.Fatalf("unexpected number of pool series: %d, err: %v", n, err)
+	}
+}
+
+type fakeConnector struct{}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+func (fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (fakeRows) Close() error {
+	return nil
+}
+
+func (fakeRows) Next([]driver.Value) error {
+	return io.EOF
+}
@@ -0,0 +1,43 @@
+package metrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPC(t *testing.T) {
+	interceptor := metrics.GRPC()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req == "missing" {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/paybile.v1.WalletService/GetWallet"}
+
+	for _, req := range []string{"a", "b", "missing"} {
+		_, _ = interceptor(context.Background(), req, info, handler)
+	}
+
+	want := `
+# HELP paybile_grpc_requests_total Number of gRPC calls by method and status code.
+# TYPE paybile_grpc_requests_total counter
+paybile_grpc_requests_total{code="NotFound",method="/paybile.v1.WalletService/GetWallet"} 1
+paybile_grpc_requests_total{code="OK",method="/paybile.v1.WalletService/GetWallet"} 2
+`
+	err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), "paybile_grpc_requests_total")
+	if err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+	if n, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "paybile_grpc_request_duration_seconds"); err != nil || n != 2 {
+		t.Fatalf("unexpected number of duration series: %d, err: %v", n, err)
+	}
+}
@@ -0,0 +1,45 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTP(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/wallet/{walletID}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "walletID") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	h := metrics.HTTP(r)
+
+	for _, path := range []string{"/wallet/a", "/wallet/b", "/wallet/missing", "/foo"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := `
+# HELP paybile_http_requests_total Number of HTTP requests by route, method and status.
+# TYPE paybile_http_requests_total counter
+paybile_http_requests_total{method="GET",route="/wallet/{walletID}",status="200"} 2
+paybile_http_requests_total{method="GET",route="/wallet/{walletID}",status="404"} 1
+paybile_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), "paybile_http_requests_total")
+	if err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+	if n, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "paybile_http_request_duration_seconds"); err != nil || n != 3 {
+		t.Fatalf("unexpected number of duration series: %d, err: %v", n, err)
+	}
+}
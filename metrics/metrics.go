@@ -0,0 +1,16 @@
+// Package metrics records the Prometheus metrics of the HTTP and gRPC APIs,
+// the transfers and the database.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "paybile"
+
+// Handler returns the handler that exposes the metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/hmoragrega/paybile/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Number of transfer requests by outcome.",
+	}, []string{"outcome"})
+
+	transferAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount_total",
+		Help:      "Sum of the amounts of the transfer requests by outcome.",
+	}, []string{"outcome"})
+)
+
+// Transfers records the outcome of the transfer requests.
+type Transfers struct{}
+
+// ObserveTransfer implements service.TransferObserver.
+func (Transfers) ObserveTransfer(req service.TransferRequest, err error) {
+	outcome := transferOutcome(req, err)
+
+	transfers.WithLabelValues(outcome).Inc()
+	if req.Amount > 0 {
+		transferAmount.WithLabelValues(outcome).Add(req.Amount)
+	}
+}
+
+func transferOutcome(req service.TransferRequest, err error) string {
+	switch {
+	case err == nil && req.ExecuteAt != nil:
+		return "scheduled"
+	case err == nil:
+		return "completed"
+	case errors.Is(err, service.ErrInsufficientFunds):
+		return "insufficient_funds"
+	case errors.Is(err, service.ErrInvalidTransactionAmount):
+		return "invalid_amount"
+	case errors.Is(err, service.ErrSameTransferWallets):
+		return "same_wallets"
+	case errors.Is(err, service.ErrInvalidExecutionDate):
+		return "invalid_execution_date"
+	case errors.Is(err, service.ErrWalletNotFound):
+		return "wallet_not_found"
+	case errors.Is(err, service.ErrWalletAccessDenied):
+		return "wallet_access_denied"
+	default:
+		return "error"
+	}
+}
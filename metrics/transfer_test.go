@@ -0,0 +1,45 @@
+package metrics_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransfers(t *testing.T) {
+	executeAt := time.Now().Add(time.Hour)
+
+	var o metrics.Transfers
+	o.ObserveTransfer(service.TransferRequest{Amount: 10}, nil)
+	o.ObserveTransfer(service.TransferRequest{Amount: 2.5}, nil)
+	o.ObserveTransfer(service.TransferRequest{Amount: 5, ExecuteAt: &executeAt}, nil)
+	o.ObserveTransfer(service.TransferRequest{Amount: 100}, fmt.Errorf("cannot transfer funds: %w", service.ErrInsufficientFunds))
+	o.ObserveTransfer(service.TransferRequest{Amount: -1}, service.ErrInvalidTransactionAmount)
+	o.ObserveTransfer(service.TransferRequest{Amount: 1}, fmt.Errorf("dummy"))
+
+	want := `
+# HELP paybile_transfers_total Number of transfer requests by outcome.
+# TYPE paybile_transfers_total counter
+paybile_transfers_total{outcome="completed"} 2
+paybile_transfers_total{outcome="error"} 1
+paybile_transfers_total{outcome="insufficient_funds"} 1
+paybile_transfers_total{outcome="invalid_amount"} 1
+paybile_transfers_total{outcome="scheduled"} 1
+# HELP paybile_transfer_amount_total Sum of the amounts of the transfer requests by outcome.
+# TYPE paybile_transfer_amount_total counter
+paybile_transfer_amount_total{outcome="completed"} 12.5
+paybile_transfer_amount_total{outcome="error"} 1
+paybile_transfer_amount_total{outcome="insufficient_funds"} 100
+paybile_transfer_amount_total{outcome="scheduled"} 5
+`
+	err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), "paybile_transfers_total", "paybile_transfer_amount_total")
+	if err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+}
@@ -0,0 +1,6 @@
+DROP INDEX transfers_scheduled_idx;
+ALTER TABLE transfers
+    DROP COLUMN status,
+    DROP COLUMN execute_at,
+    DROP COLUMN failure_reason;
+DROP TYPE transfer_status;
@@ -0,0 +1,8 @@
+CREATE TYPE transfer_status AS ENUM ('completed', 'scheduled', 'cancelled', 'failed');
+
+ALTER TABLE transfers
+    ADD COLUMN status         transfer_status NOT NULL DEFAULT 'completed',
+    ADD COLUMN execute_at     TIMESTAMPTZ NULL DEFAULT NULL, -- due date of scheduled transfers.
+    ADD COLUMN failure_reason TEXT NULL        DEFAULT NULL;
+
+CREATE INDEX transfers_scheduled_idx ON transfers USING btree (execute_at, id) WHERE status = 'scheduled';
@@ -0,0 +1,7 @@
+DROP INDEX standing_order_runs_order_idx;
+DROP TABLE standing_order_runs;
+DROP INDEX standing_orders_next_run_idx;
+DROP INDEX standing_orders_origin_idx;
+DROP TABLE standing_orders;
+DROP TYPE recurrence_frequency;
+DROP TYPE standing_order_status;
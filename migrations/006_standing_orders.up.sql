@@ -0,0 +1,44 @@
+CREATE TYPE standing_order_status AS ENUM ('active', 'cancelled', 'finished');
+CREATE TYPE recurrence_frequency AS ENUM ('daily', 'weekly', 'monthly');
+
+CREATE TABLE IF NOT EXISTS standing_orders
+(
+    id                    UUID                  DEFAULT uuid_generate_v4(),
+    issuer_id             UUID,
+    origin_wallet_id      UUID,
+    destination_wallet_id UUID,
+    amount                DECIMAL(19, 4),
+    message               TEXT,
+    frequency             recurrence_frequency,
+    day_of_month          SMALLINT              DEFAULT 0, -- only used by monthly recurrences.
+    start_date            TIMESTAMPTZ,
+    end_date              TIMESTAMPTZ NULL      DEFAULT NULL,
+    count                 INTEGER NULL          DEFAULT NULL, -- maximum number of executions.
+    status                standing_order_status DEFAULT 'active',
+    runs                  INTEGER               DEFAULT 0, -- number of executions.
+    next_run_at           TIMESTAMPTZ NULL      DEFAULT NULL,
+    created_at            TIMESTAMPTZ           DEFAULT NOW(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (issuer_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (origin_wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (destination_wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX standing_orders_origin_idx ON standing_orders USING btree (origin_wallet_id, created_at, id);
+CREATE INDEX standing_orders_next_run_idx ON standing_orders USING btree (next_run_at, id) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS standing_order_runs
+(
+    id                UUID        DEFAULT uuid_generate_v4(),
+    standing_order_id UUID,
+    transfer_id       UUID NULL   DEFAULT NULL, -- empty if the execution failed.
+    scheduled_at      TIMESTAMPTZ,
+    date              TIMESTAMPTZ DEFAULT NOW(),
+    status            transfer_status,
+    failure_reason    TEXT NULL   DEFAULT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (standing_order_id) REFERENCES standing_orders (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (transfer_id) REFERENCES transfers (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX standing_order_runs_order_idx ON standing_order_runs USING btree (standing_order_id, date, id);
@@ -0,0 +1,6 @@
+DROP INDEX transfers_batch_idx;
+ALTER TABLE transfers
+    DROP COLUMN batch_id,
+    DROP COLUMN batch_index;
+DROP TABLE batches;
+DROP TYPE batch_mode;
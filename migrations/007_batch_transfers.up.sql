@@ -0,0 +1,20 @@
+CREATE TYPE batch_mode AS ENUM ('atomic', 'best_effort');
+
+CREATE TABLE IF NOT EXISTS batches
+(
+    id               UUID        DEFAULT uuid_generate_v4(),
+    issuer_id        UUID,
+    origin_wallet_id UUID,
+    mode             batch_mode,
+    date             TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (issuer_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (origin_wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+ALTER TABLE transfers
+    ADD COLUMN batch_id    UUID NULL    DEFAULT NULL,
+    ADD COLUMN batch_index INTEGER NULL DEFAULT NULL, -- position of the transfer in the batch.
+    ADD FOREIGN KEY (batch_id) REFERENCES batches (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE INDEX transfers_batch_idx ON transfers USING btree (batch_id, batch_index) WHERE batch_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX payment_requests_expiration_idx;
+DROP INDEX payment_requests_requester_idx;
+DROP INDEX payment_requests_payer_idx;
+DROP TABLE payment_requests;
+DROP TYPE payment_request_status;
@@ -0,0 +1,27 @@
+CREATE TYPE payment_request_status AS ENUM ('pending', 'accepted', 'declined', 'expired');
+
+CREATE TABLE IF NOT EXISTS payment_requests
+(
+    id                  UUID                   DEFAULT uuid_generate_v4(),
+    requester_id        UUID,
+    requester_wallet_id UUID, -- wallet that receives the funds.
+    payer_id            UUID,
+    payer_wallet_id     UUID, -- wallet that sends the funds.
+    amount              DECIMAL(19, 4),
+    message             TEXT,
+    status              payment_request_status DEFAULT 'pending',
+    transfer_id         UUID NULL              DEFAULT NULL, -- set once accepted.
+    expires_at          TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ            DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ            DEFAULT NOW(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (requester_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (requester_wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (payer_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (payer_wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (transfer_id) REFERENCES transfers (id) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+CREATE INDEX payment_requests_payer_idx ON payment_requests USING btree (payer_id, created_at DESC, id);
+CREATE INDEX payment_requests_requester_idx ON payment_requests USING btree (requester_id, created_at DESC, id);
+CREATE INDEX payment_requests_expiration_idx ON payment_requests USING btree (expires_at) WHERE status = 'pending';
@@ -0,0 +1,35 @@
+CREATE TYPE transaction_type AS ENUM ('deposit', 'transfer', 'fee');
+
+CREATE TABLE IF NOT EXISTS transactions
+(
+    id               UUID        DEFAULT uuid_generate_v4(),
+    wallet_id        UUID,
+    amount           NUMERIC(19, 4),
+    balance          NUMERIC(19, 4), -- balance after the transaction.
+    date             TIMESTAMPTZ DEFAULT NOW(),
+    transaction_type transaction_type,
+    reference_id     UUID NULL   DEFAULT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX transactions_date_idx ON transactions USING btree (date, id);
+
+-- Only the wallet postings are kept, the system accounts are lost.
+INSERT INTO transactions (id, wallet_id, amount, balance, date, transaction_type, reference_id)
+SELECT p.id, p.wallet_id, p.amount, p.balance, j.date, j.entry_type::TEXT::transaction_type, j.reference_id
+FROM postings p
+         JOIN journal_entries j ON j.id = p.journal_entry_id
+WHERE p.wallet_id IS NOT NULL;
+
+DROP TRIGGER postings_balance_check ON postings;
+DROP FUNCTION check_journal_entry_balance();
+DROP INDEX postings_wallet_idx;
+DROP INDEX postings_sequence_idx;
+DROP INDEX postings_journal_entry_idx;
+DROP TABLE postings;
+DROP INDEX journal_entries_reference_idx;
+DROP INDEX journal_entries_date_idx;
+DROP TABLE journal_entries;
+DROP TYPE journal_entry_type;
+DROP TYPE system_account;
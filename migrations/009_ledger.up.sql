@@ -0,0 +1,87 @@
+CREATE TYPE system_account AS ENUM ('cash_in', 'fees', 'suspense');
+CREATE TYPE journal_entry_type AS ENUM ('deposit', 'transfer', 'fee');
+
+CREATE TABLE IF NOT EXISTS journal_entries
+(
+    id           UUID        DEFAULT uuid_generate_v4(),
+    entry_type   journal_entry_type,
+    reference_id UUID NULL   DEFAULT NULL,
+    date         TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX journal_entries_date_idx ON journal_entries USING btree (date, id);
+CREATE INDEX journal_entries_reference_idx ON journal_entries USING btree (reference_id) WHERE reference_id IS NOT NULL;
+
+-- The postings are ordered by sequence, the journal entry date is the start of its
+-- DB transaction and does not tell apart the postings of the same DB transaction.
+CREATE TABLE IF NOT EXISTS postings
+(
+    id               UUID                DEFAULT uuid_generate_v4(),
+    sequence         BIGSERIAL,
+    journal_entry_id UUID,
+    wallet_id        UUID NULL           DEFAULT NULL,
+    system_account   system_account NULL DEFAULT NULL,
+    amount           NUMERIC(19, 4),
+    balance          NUMERIC(19, 4) NULL DEFAULT NULL, -- wallet balance after the posting.
+    PRIMARY KEY (id),
+    FOREIGN KEY (journal_entry_id) REFERENCES journal_entries (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CHECK ((wallet_id IS NULL) <> (system_account IS NULL)) -- either a wallet or a system account.
+);
+
+CREATE INDEX postings_journal_entry_idx ON postings USING btree (journal_entry_id);
+CREATE UNIQUE INDEX postings_sequence_idx ON postings USING btree (sequence);
+CREATE INDEX postings_wallet_idx ON postings USING btree (wallet_id, sequence) WHERE wallet_id IS NOT NULL;
+
+-- The postings of a journal entry must balance to zero when the DB transaction is committed.
+CREATE FUNCTION check_journal_entry_balance() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount), 0) FROM postings WHERE journal_entry_id = NEW.journal_entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % does not balance', NEW.journal_entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER postings_balance_check
+    AFTER INSERT OR UPDATE
+    ON postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE PROCEDURE check_journal_entry_balance();
+
+-- Move the wallet transactions to the ledger keeping their IDs, both legs
+-- of a transfer go to the same journal entry, the rest have their own.
+CREATE TEMPORARY TABLE ledger_migration AS
+SELECT t.*,
+       FIRST_VALUE(t.id) OVER (
+           PARTITION BY CASE WHEN t.transaction_type = 'transfer' AND t.reference_id IS NOT NULL THEN t.reference_id ELSE t.id END
+           ORDER BY t.date, t.id
+           ) AS entry_id
+FROM transactions t;
+
+INSERT INTO journal_entries (id, entry_type, reference_id, date)
+SELECT id, transaction_type::TEXT::journal_entry_type, reference_id, date
+FROM ledger_migration
+WHERE id = entry_id;
+
+INSERT INTO postings (id, journal_entry_id, wallet_id, amount, balance)
+SELECT id, entry_id, wallet_id, amount, balance
+FROM ledger_migration
+ORDER BY date, id;
+
+-- Deposits are funded by the cash-in account, transfers that don't net to zero are sent to suspense.
+INSERT INTO postings (journal_entry_id, system_account, amount)
+SELECT entry_id,
+       CASE WHEN MIN(transaction_type::TEXT) = 'deposit' THEN 'cash_in' ELSE 'suspense' END::system_account,
+       -SUM(amount)
+FROM ledger_migration
+GROUP BY entry_id
+HAVING SUM(amount) <> 0;
+
+DROP TABLE ledger_migration;
+DROP INDEX transactions_date_idx;
+DROP TABLE transactions;
+DROP TYPE transaction_type;
@@ -0,0 +1,8 @@
+DROP INDEX webhook_delivery_attempts_delivery_idx;
+DROP TABLE webhook_delivery_attempts;
+DROP INDEX webhook_deliveries_subscription_idx;
+DROP INDEX webhook_deliveries_pending_idx;
+DROP TABLE webhook_deliveries;
+DROP INDEX webhook_subscriptions_user_idx;
+DROP TABLE webhook_subscriptions;
+DROP TYPE webhook_delivery_status;
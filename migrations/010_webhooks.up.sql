@@ -0,0 +1,51 @@
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'dead');
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id          UUID        DEFAULT uuid_generate_v4(),
+    user_id     UUID,
+    url         TEXT,
+    secret      TEXT,
+    event_types TEXT[],
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX webhook_subscriptions_user_idx ON webhook_subscriptions USING btree (user_id, created_at, id);
+
+-- Outbox of the webhook deliveries, one per subscription and event, written
+-- in the same DB transaction as the operation that originated the event.
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              UUID                    DEFAULT uuid_generate_v4(),
+    subscription_id UUID,
+    event_id        UUID,
+    event_type      TEXT,
+    payload         JSONB,
+    status          webhook_delivery_status DEFAULT 'pending',
+    attempts        INT                     DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ             DEFAULT NOW(),
+    created_at      TIMESTAMPTZ             DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ NULL        DEFAULT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries USING btree (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_idx ON webhook_deliveries USING btree (subscription_id, created_at DESC, id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts
+(
+    id          UUID        DEFAULT uuid_generate_v4(),
+    delivery_id UUID,
+    attempt     INT,
+    status_code INT NULL    DEFAULT NULL,
+    error       TEXT NULL   DEFAULT NULL,
+    duration_ms INT,
+    date        TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_idx ON webhook_delivery_attempts USING btree (delivery_id, attempt);
@@ -0,0 +1,4 @@
+DROP INDEX webhook_deliveries_event_idx;
+DROP INDEX events_unpublished_idx;
+DROP INDEX events_sequence_idx;
+DROP TABLE events;
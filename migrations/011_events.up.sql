@@ -0,0 +1,22 @@
+-- Outbox of the domain events, written in the same DB transaction as the
+-- operation. The sequence orders the events of each wallet in commit order
+-- because they are inserted while holding the lock of the wallet row.
+CREATE TABLE IF NOT EXISTS events
+(
+    id           UUID        DEFAULT uuid_generate_v4(),
+    sequence     BIGSERIAL,
+    wallet_id    UUID,
+    event_type   TEXT,
+    payload      JSONB,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    published_at TIMESTAMPTZ NULL DEFAULT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE UNIQUE INDEX events_sequence_idx ON events USING btree (sequence);
+CREATE INDEX events_unpublished_idx ON events USING btree (wallet_id, sequence) WHERE published_at IS NULL;
+
+-- The webhook deliveries are enqueued by relaying the events, which are
+-- published at least once, the index discards the duplicated deliveries.
+CREATE UNIQUE INDEX webhook_deliveries_event_idx ON webhook_deliveries USING btree (subscription_id, event_id);
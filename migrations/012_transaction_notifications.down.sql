@@ -0,0 +1,2 @@
+DROP TRIGGER postings_notify_wallet_transaction ON postings;
+DROP FUNCTION notify_wallet_transaction();
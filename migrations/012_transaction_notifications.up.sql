@@ -0,0 +1,17 @@
+-- Notifies the wallet transactions on the wallet_transactions channel,
+-- the notifications are only delivered once the DB transaction commits.
+CREATE FUNCTION notify_wallet_transaction() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('wallet_transactions',
+                      json_build_object('wallet_id', NEW.wallet_id, 'transaction_id', NEW.id)::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER postings_notify_wallet_transaction
+    AFTER INSERT
+    ON postings
+    FOR EACH ROW
+    WHEN (NEW.wallet_id IS NOT NULL)
+EXECUTE PROCEDURE notify_wallet_transaction();
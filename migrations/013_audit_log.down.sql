@@ -0,0 +1,7 @@
+DROP TRIGGER audit_log_no_truncate ON audit_log;
+DROP TRIGGER audit_log_append_only ON audit_log;
+DROP FUNCTION audit_log_append_only();
+DROP INDEX audit_log_unchained_idx;
+DROP INDEX audit_log_position_idx;
+DROP INDEX audit_log_sequence_idx;
+DROP TABLE audit_log;
@@ -0,0 +1,56 @@
+-- Append-only log of the security relevant actions. Every entry stores the
+-- hash of the previous one, so a modified or deleted entry breaks the chain.
+--
+-- The entries are appended without hashes, so the requests do not wait for
+-- each other, and a single chainer sets their position in the chain and
+-- their hashes shortly after.
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id         UUID,
+    sequence   BIGSERIAL,
+    actor      TEXT        NOT NULL,
+    action     TEXT        NOT NULL,
+    resource   TEXT        NOT NULL,
+    outcome    TEXT        NOT NULL,
+    ip         TEXT        NOT NULL,
+    user_agent TEXT        NOT NULL,
+    request_id TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    position   BIGINT,
+    prev_hash  TEXT,
+    hash       TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX audit_log_sequence_idx ON audit_log USING btree (sequence);
+CREATE UNIQUE INDEX audit_log_position_idx ON audit_log USING btree (position);
+CREATE INDEX audit_log_unchained_idx ON audit_log USING btree (sequence) WHERE hash IS NULL;
+
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    -- The chain of an entry is set once, without changing the entry.
+    IF TG_OP = 'UPDATE' THEN
+        IF OLD.hash IS NULL AND NEW.hash IS NOT NULL
+            AND (NEW.id, NEW.sequence, NEW.actor, NEW.action, NEW.resource, NEW.outcome,
+                 NEW.ip, NEW.user_agent, NEW.request_id, NEW.created_at)
+                = (OLD.id, OLD.sequence, OLD.actor, OLD.action, OLD.resource, OLD.outcome,
+                   OLD.ip, OLD.user_agent, OLD.request_id, OLD.created_at) THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE PROCEDURE audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE audit_log_append_only();
@@ -0,0 +1,8 @@
+-- Failed login attempts per login or IP, the key is prefixed with the kind.
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key          TEXT,
+    failures     INT         NOT NULL,
+    last_failure TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key)
+);
@@ -0,0 +1,43 @@
+// Package migrations embeds the SQL migrations of the database schema.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// FS the migrations, named like "001_users.up.sql".
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Latest returns the version of the latest migration.
+func Latest() (uint, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, fmt.Errorf("cannot list migrations: %v", err)
+	}
+
+	var latest uint
+	for _, name := range files {
+		i := strings.IndexByte(name, '_')
+		if i < 0 {
+			return 0, fmt.Errorf("migration %q has no version", name)
+		}
+		v, err := strconv.ParseUint(name[:i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %q has an invalid version: %v", name, err)
+		}
+		if uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("there are no migrations")
+	}
+
+	return latest, nil
+}
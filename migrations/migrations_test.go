@@ -0,0 +1,31 @@
+package migrations_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/hmoragrega/paybile/migrations"
+)
+
+func TestLatest(t *testing.T) {
+	ups, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatalf("cannot list up migrations: %v", err)
+	}
+	for _, up := range ups {
+		down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+		if _, err := fs.Stat(migrations.FS, down); err != nil {
+			t.Fatalf("migration %q has no down migration: %v", up, err)
+		}
+	}
+
+	// the versions are consecutive, the latest one is the number of migrations.
+	got, err := migrations.Latest()
+	if err != nil {
+		t.Fatalf("cannot get the latest migration: %v", err)
+	}
+	if want := uint(len(ups)); got != want {
+		t.Fatalf("unexpected latest migration: got %d, want %d", got, want)
+	}
+}
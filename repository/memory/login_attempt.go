@@ -0,0 +1,51 @@
+// Package memory implements the storage of the services in memory, it
+// is meant for tests and single instance deployments.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+)
+
+// LoginAttemptStore keeps the failed login attempts in memory.
+type LoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]service.LoginAttempts
+}
+
+func (s *LoginAttemptStore) GetAttempts(_ context.Context, key string) (service.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *LoginAttemptStore) AddFailure(_ context.Context, key string, at time.Time, window time.Duration) (service.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attempts == nil {
+		s.attempts = make(map[string]service.LoginAttempts)
+	}
+	a := s.attempts[key]
+	if at.Sub(a.LastFailure) >= window {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = at
+	s.attempts[key] = a
+
+	return a, nil
+}
+
+func (s *LoginAttemptStore) ResetAttempts(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
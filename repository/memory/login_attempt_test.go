@@ -0,0 +1,83 @@
+package memory_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/repository/memory"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestLoginAttemptStore(t *testing.T) {
+	var (
+		s      memory.LoginAttemptStore
+		ctx    = context.Background()
+		now    = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+		window = time.Minute
+	)
+
+	if got, _ := s.GetAttempts(ctx, "foo"); got != (service.LoginAttempts{}) {
+		t.Fatalf("unexpected attempts of an unknown key: %+v", got)
+	}
+
+	steps := []struct {
+		at   time.Time
+		want int
+	}{
+		{at: now, want: 1},
+		{at: now.Add(time.Second), want: 2},
+		{at: now.Add(time.Second + window), want: 1},
+	}
+	for _, step := range steps {
+		got, err := s.AddFailure(ctx, "foo", step.at, window)
+		if err != nil {
+			t.Fatalf("cannot add failure: %v", err)
+		}
+		want := service.LoginAttempts{Failures: step.want, LastFailure: step.at}
+		if got != want {
+			t.Fatalf("unexpected attempts: \n got:  %+v \n want: %+v", got, want)
+		}
+	}
+
+	if err := s.ResetAttempts(ctx, "foo"); err != nil {
+		t.Fatalf("cannot reset attempts: %v", err)
+	}
+	if got, _ := s.GetAttempts(ctx, "foo"); got != (service.LoginAttempts{}) {
+		t.Fatalf("unexpected attempts after reset: %+v", got)
+	}
+}
+
+func TestLoginAttemptStoreConcurrentFailures(t *testing.T) {
+	var (
+		s   memory.LoginAttemptStore
+		ctx = context.Background()
+		now = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+		n   = 50
+		wg  sync.WaitGroup
+		got = make([]int, n)
+	)
+
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			a, err := s.AddFailure(ctx, "foo", now, time.Minute)
+			if err != nil {
+				t.Errorf("cannot add failure: %v", err)
+			}
+			got[i] = a.Failures
+		}(i)
+	}
+	wg.Wait()
+
+	// every failure sees its own count.
+	sort.Ints(got)
+	for i, failures := range got {
+		if failures != i+1 {
+			t.Fatalf("unexpected failures count: got %d, want %d", failures, i+1)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+)
+
+// auditLockID key of the advisory lock that serializes the
+// chaining of the audit log, so every entry is chained to the
+// last one chained.
+const auditLockID = 0x61756469
+
+type AuditRepository struct {
+	DB *sql.DB
+}
+
+// Audit appends the entry to the audit log, it is chained later by
+// ChainAuditLog so the requests do not wait for each other.
+func (r *AuditRepository) Audit(ctx context.Context, e service.AuditEntry) error {
+	ctx = metrics.WithQuery(ctx, "AuditRepository.Audit")
+
+	e.ID = uuid.New()
+	// Postgres stores microseconds.
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO audit_log (id, actor, action, resource, outcome, ip, user_agent, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		e.ID, e.Actor, e.Action, e.Resource, e.Outcome, e.IP, e.UserAgent, e.RequestID, e.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot insert audit entry: %v", err)
+	}
+
+	return nil
+}
+
+// ChainAuditLog chains the pending entries, in the order they were
+// appended, to the last chained one. Only one chainer runs at a time,
+// the others return without chaining any entry.
+func (r *AuditRepository) ChainAuditLog(ctx context.Context, limit int) (chained int, err error) {
+	ctx = metrics.WithQuery(ctx, "AuditRepository.ChainAuditLog")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	var locked bool
+	if err = tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, auditLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("cannot lock audit log: %v", err)
+	}
+	if !locked {
+		// another chainer is running.
+		if err = tx.Commit(); err != nil {
+			return 0, fmt.Errorf("%w: %v", errTxCommit, err)
+		}
+		return 0, nil
+	}
+
+	var (
+		position int64
+		prevHash string
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT position, hash
+		FROM audit_log
+		WHERE position IS NOT NULL
+		ORDER BY position DESC
+		LIMIT 1`,
+	).Scan(&position, &prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("cannot get last chained audit entry: %v", err)
+	}
+
+	pending, err := pendingAuditEntries(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range pending {
+		hash, err := auditHash(prevHash, e)
+		if err != nil {
+			return 0, err
+		}
+		position++
+		_, err = tx.ExecContext(ctx, `
+			UPDATE audit_log
+			SET position = $1, prev_hash = $2, hash = $3
+			WHERE id = $4`,
+			position, prevHash, hash, e.ID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("cannot chain audit entry: %v", err)
+		}
+		prevHash = hash
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return len(pending), nil
+}
+
+// pendingAuditEntries returns the entries that have not been chained yet.
+func pendingAuditEntries(ctx context.Context, tx *sql.Tx, limit int) (list []service.AuditEntry, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, sequence, actor, action, resource, outcome, ip, user_agent, request_id, created_at
+		FROM audit_log
+		WHERE hash IS NULL
+		ORDER BY sequence
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query pending audit entries: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e service.AuditEntry
+		err := rows.Scan(
+			&e.ID, &e.Sequence, &e.Actor, &e.Action, &e.Resource, &e.Outcome,
+			&e.IP, &e.UserAgent, &e.RequestID, &e.CreatedAt,
+		)
+		if err != nil {
+			return list, fmt.Errorf("cannot scan audit entry: %v", err)
+		}
+		e.CreatedAt = e.CreatedAt.UTC()
+		list = append(list, e)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("cannot iterate pending audit entries: %v", err)
+	}
+
+	return list, nil
+}
+
+type AuditViolationType string
+
+const (
+	// AuditHashMismatch the entry does not match its hash, it has been modified.
+	AuditHashMismatch AuditViolationType = "hash_mismatch"
+	// AuditBrokenChain the entry is not chained to the previous one, an
+	// entry has been deleted or inserted before it.
+	AuditBrokenChain AuditViolationType = "broken_chain"
+)
+
+type AuditViolation struct {
+	Type     AuditViolationType `json:"type"`
+	EntryID  uuid.UUID          `json:"entry_id"`
+	Sequence int64              `json:"sequence"`
+}
+
+type AuditReport struct {
+	Entries int `json:"entries"`
+	// Pending entries that have not been chained yet, they are not verified.
+	Pending int `json:"pending"`
+	// Head hash of the last entry, keeping it elsewhere allows to
+	// detect that the latest entries have been deleted.
+	Head       string           `json:"head"`
+	Violations []AuditViolation `json:"violations"`
+}
+
+// Valid returns true if no violations were found.
+func (r AuditReport) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// VerifyAuditLog walks the chained entries of the audit log in chain
+// order checking that every entry matches its hash and is chained to
+// the previous one.
+func VerifyAuditLog(ctx context.Context, db *sql.DB) (report AuditReport, err error) {
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log WHERE hash IS NULL`).Scan(&report.Pending)
+	if err != nil {
+		return report, fmt.Errorf("cannot count pending audit entries: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, sequence, actor, action, resource, outcome, ip, user_agent, request_id, created_at, prev_hash, hash
+		FROM audit_log
+		WHERE hash IS NOT NULL
+		ORDER BY position`,
+	)
+	if err != nil {
+		return report, fmt.Errorf("cannot query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	report.Violations = []AuditViolation{}
+	for rows.Next() {
+		var (
+			e              service.AuditEntry
+			prevHash, hash string
+		)
+		err := rows.Scan(
+			&e.ID, &e.Sequence, &e.Actor, &e.Action, &e.Resource, &e.Outcome,
+			&e.IP, &e.UserAgent, &e.RequestID, &e.CreatedAt, &prevHash, &hash,
+		)
+		if err != nil {
+			return report, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan audit entry"), err)
+		}
+		e.CreatedAt = e.CreatedAt.UTC()
+		report.Entries++
+
+		if prevHash != report.Head {
+			report.Violations = append(report.Violations, AuditViolation{
+				Type:     AuditBrokenChain,
+				EntryID:  e.ID,
+				Sequence: e.Sequence,
+			})
+		}
+		expected, err := auditHash(prevHash, e)
+		if err != nil {
+			return report, err
+		}
+		if expected != hash {
+			report.Violations = append(report.Violations, AuditViolation{
+				Type:     AuditHashMismatch,
+				EntryID:  e.ID,
+				Sequence: e.Sequence,
+			})
+		}
+		report.Head = hash
+	}
+	if err = rows.Err(); err != nil {
+		return report, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate audit log"), err)
+	}
+
+	return report, nil
+}
+
+// auditHash returns the SHA-256 of the entry chained to the hash of the
+// previous one. The fields are hashed as a JSON array, the format cannot
+// change without breaking the verification of the existing entries.
+func auditHash(prevHash string, e service.AuditEntry) (string, error) {
+	b, err := json.Marshal([]string{
+		prevHash,
+		e.ID.String(),
+		e.Actor,
+		string(e.Action),
+		e.Resource,
+		string(e.Outcome),
+		e.IP,
+		e.UserAgent,
+		e.RequestID,
+		e.CreatedAt.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal audit entry: %v", err)
+	}
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
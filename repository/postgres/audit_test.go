@@ -0,0 +1,163 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestAuditLog(t *testing.T) {
+	db := setupTestDB(t)
+
+	tt := []struct {
+		name        string
+		tamper      []string
+		pending     int
+		want        []AuditViolationType
+		wantPending int
+	}{
+		{
+			name: "valid",
+			want: []AuditViolationType{},
+		},
+		{
+			name:        "pending entries are not verified",
+			pending:     2,
+			want:        []AuditViolationType{},
+			wantPending: 2,
+		},
+		{
+			name: "modified entry",
+			tamper: []string{
+				`UPDATE audit_log SET outcome = 'success' WHERE sequence = (SELECT MIN(sequence) + 1 FROM audit_log)`,
+			},
+			want: []AuditViolationType{AuditHashMismatch},
+		},
+		{
+			name: "deleted entry",
+			tamper: []string{
+				`DELETE FROM audit_log WHERE sequence = (SELECT MIN(sequence) + 1 FROM audit_log)`,
+			},
+			want: []AuditViolationType{AuditBrokenChain},
+		},
+		{
+			name: "rehashed entry",
+			tamper: []string{
+				`UPDATE audit_log SET hash = md5(hash) WHERE sequence = (SELECT MIN(sequence) FROM audit_log)`,
+			},
+			want: []AuditViolationType{AuditHashMismatch, AuditBrokenChain},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			execQueries(ctx, t, db, `ALTER TABLE audit_log DISABLE TRIGGER USER`, `TRUNCATE audit_log`, `ALTER TABLE audit_log ENABLE TRIGGER USER`)
+
+			r := AuditRepository{DB: db}
+			for _, outcome := range []service.AuditOutcome{service.AuditSuccess, service.AuditFailure, service.AuditSuccess} {
+				err := r.Audit(ctx, service.AuditEntry{
+					Actor:         userA.ID.String(),
+					Action:        service.AuditLogin,
+					Outcome:       outcome,
+					AuditMetadata: service.AuditMetadata{IP: "127.0.0.1", UserAgent: "test", RequestID: "test-request"},
+				})
+				if err != nil {
+					t.Fatalf("cannot audit: %v", err)
+				}
+			}
+			// the entries are chained in two batches.
+			for _, limit := range []int{2, 2} {
+				if _, err := r.ChainAuditLog(ctx, limit); err != nil {
+					t.Fatalf("cannot chain the audit log: %v", err)
+				}
+			}
+			for i := 0; i < tc.pending; i++ {
+				err := r.Audit(ctx, service.AuditEntry{Actor: userA.ID.String(), Action: service.AuditLogin, Outcome: service.AuditFailure})
+				if err != nil {
+					t.Fatalf("cannot audit: %v", err)
+				}
+			}
+			if len(tc.tamper) > 0 {
+				execQueries(ctx, t, db, append(append([]string{`ALTER TABLE audit_log DISABLE TRIGGER USER`}, tc.tamper...), `ALTER TABLE audit_log ENABLE TRIGGER USER`)...)
+			}
+
+			report, err := VerifyAuditLog(ctx, db)
+			if err != nil {
+				t.Fatalf("cannot verify the audit log: %v", err)
+			}
+			got := []AuditViolationType{}
+			for _, v := range report.Violations {
+				got = append(got, v.Type)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected violations: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+			if got, want := report.Valid(), len(tc.want) == 0; got != want {
+				t.Fatalf("unexpected valid report: got %v, want %v", got, want)
+			}
+			if report.Entries != 3 || report.Pending != tc.wantPending {
+				t.Fatalf("unexpected entries: got %d and %d pending, want 3 and %d pending", report.Entries, report.Pending, tc.wantPending)
+			}
+		})
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		execQueries(ctx, t, db, `ALTER TABLE audit_log DISABLE TRIGGER USER`, `TRUNCATE audit_log`, `ALTER TABLE audit_log ENABLE TRIGGER USER`)
+
+		r := AuditRepository{DB: db}
+		if err := r.Audit(ctx, service.AuditEntry{Actor: userA.ID.String(), Action: service.AuditLogin, Outcome: service.AuditFailure}); err != nil {
+			t.Fatalf("cannot audit: %v", err)
+		}
+		// a pending entry can be chained but not modified.
+		if _, err := db.ExecContext(ctx, `UPDATE audit_log SET outcome = 'success', hash = 'foo'`); err == nil {
+			t.Fatalf("a pending entry has been modified")
+		}
+		n, err := r.ChainAuditLog(ctx, 10)
+		if err != nil || n != 1 {
+			t.Fatalf("unexpected chained entries: %d, %v", n, err)
+		}
+		// the chained entries cannot be chained again.
+		if _, err := db.ExecContext(ctx, `UPDATE audit_log SET hash = 'foo'`); err == nil {
+			t.Fatalf("a chained entry has been modified")
+		}
+		if n, err = r.ChainAuditLog(ctx, 10); err != nil || n != 0 {
+			t.Fatalf("unexpected chained entries: %d, %v", n, err)
+		}
+	})
+
+	t.Run("append only", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		for _, query := range []string{
+			`UPDATE audit_log SET outcome = 'success'`,
+			`DELETE FROM audit_log`,
+			`TRUNCATE audit_log`,
+		} {
+			if _, err := db.ExecContext(ctx, query); err == nil {
+				t.Fatalf("the audit log has been modified with %q", query)
+			}
+		}
+	})
+}
+
+func execQueries(ctx context.Context, t *testing.T, db queryHandler, queries ...string) {
+	t.Helper()
+	for _, query := range queries {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("cannot execute %q: %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+)
+
+// CreateBatch executes all the transfers of the batch in the same DB transaction.
+// In best effort mode the transfers that the origin wallet cannot afford are
+// stored as failed, any other error aborts the whole batch.
+func (r *TransferRepository) CreateBatch(ctx context.Context, req service.BatchRequest) (b service.Batch, err error) {
+	ctx = metrics.WithQuery(ctx, "TransferRepository.CreateBatch")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return b, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	ids := []uuid.UUID{req.OriginWalletID}
+	for _, x := range req.Items {
+		ids = append(ids, x.DestinationWalletID)
+	}
+	wallets, err := r.WalletRepo.lockByID(ctx, tx, ids...)
+	if err != nil {
+		return b, err
+	}
+
+	origin, ok := wallets[req.OriginWalletID]
+	if !ok {
+		return b, fmt.Errorf("%w: origin wallet not found", service.ErrWalletNotFound)
+	}
+	if !req.Issuer.CanWrite(origin) {
+		return b, service.ErrWalletAccessDenied
+	}
+	var total float64
+	for i, x := range req.Items {
+		if _, ok := wallets[x.DestinationWalletID]; !ok {
+			return b, fmt.Errorf("%w: transfer %d: destination wallet not found", service.ErrWalletNotFound, i)
+		}
+		total += x.Amount
+	}
+	if req.Mode == service.BatchAtomic && origin.Balance < total {
+		return b, fmt.Errorf("%w: balance %.2f, batch total %.2f", service.ErrInsufficientFunds, origin.Balance, total)
+	}
+
+	b = service.Batch{
+		IssuerID:       req.Issuer.ID,
+		OriginWalletID: req.OriginWalletID,
+		Mode:           req.Mode,
+	}
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO batches (issuer_id, origin_wallet_id, mode)
+		VALUES ($1, $2, $3)
+		RETURNING id, date`,
+		b.IssuerID, b.OriginWalletID, b.Mode,
+	)
+	if err = row.Scan(&b.ID, &b.Date); err != nil {
+		return b, fmt.Errorf("cannot insert batch: %v", err)
+	}
+	b.Date = b.Date.UTC()
+
+	for i, x := range req.Items {
+		// wallets are updated on every transfer, so a destination
+		// that appears several times gets all its funds.
+		origin, destination := wallets[req.OriginWalletID], wallets[x.DestinationWalletID]
+
+		index := i
+		tr := transferRow{
+			status:     service.TransferCompleted,
+			batchID:    &b.ID,
+			batchIndex: &index,
+		}
+		if origin.Balance < x.Amount {
+			reason := fmt.Sprintf("%v: balance %.2f", service.ErrInsufficientFunds, origin.Balance)
+			tr.status = service.TransferFailed
+			tr.failureReason = &reason
+		}
+
+		t, err := r.insert(ctx, tx, service.TransferRequest{
+			Issuer:              req.Issuer,
+			OriginWalletID:      req.OriginWalletID,
+			DestinationWalletID: x.DestinationWalletID,
+			Amount:              x.Amount,
+			Message:             x.Message,
+		}, tr)
+		if err != nil {
+			return b, err
+		}
+		if t.Status == service.TransferCompleted {
+			if err = r.moveFunds(ctx, tx, t, origin, destination); err != nil {
+				return b, err
+			}
+			origin.Balance -= x.Amount
+			destination.Balance += x.Amount
+			wallets[origin.ID], wallets[destination.ID] = origin, destination
+		}
+
+		b.Transfers = append(b.Transfers, t)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return b, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return b, nil
+}
+
+// GetBatch returns the batch of the wallet with its transfers in the requested order.
+func (r *TransferRepository) GetBatch(ctx context.Context, walletID, batchID uuid.UUID) (b service.Batch, err error) {
+	ctx = metrics.WithQuery(ctx, "TransferRepository.GetBatch")
+
+	row := r.DB.QueryRowContext(ctx, `
+		SELECT id, issuer_id, origin_wallet_id, mode, date
+		FROM batches
+		WHERE id = $1 AND origin_wallet_id = $2`,
+		batchID, walletID,
+	)
+	err = row.Scan(&b.ID, &b.IssuerID, &b.OriginWalletID, &b.Mode, &b.Date)
+	if err == sql.ErrNoRows {
+		return b, service.ErrBatchNotFound
+	}
+	if err != nil {
+		return b, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve batch"), err)
+	}
+	b.Date = b.Date.UTC()
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+transferColumns+`
+		FROM transfers
+		WHERE batch_id = $1
+		ORDER BY batch_index`,
+		batchID,
+	)
+	if err != nil {
+		return b, fmt.Errorf("cannot query batch transfers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return b, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan transfer"), err)
+		}
+		b.Transfers = append(b.Transfers, t)
+	}
+	if err = rows.Err(); err != nil {
+		return b, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate batch transfers"), err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,156 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestCreateBatch(t *testing.T) {
+	db := setupTestDB(t)
+
+	items := func(amounts ...float64) (list []service.BatchItem) {
+		for i, x := range amounts {
+			destination := walletB
+			if i%2 == 1 {
+				destination = walletC
+			}
+			list = append(list, service.BatchItem{DestinationWalletID: destination, Amount: x})
+		}
+		return list
+	}
+
+	tt := []struct {
+		name              string
+		req               service.BatchRequest
+		wantStatus        []service.TransferStatus
+		wantOriginBalance float64
+		wantErr           error
+	}{
+		{
+			name: "origin wallet not found",
+			req: service.BatchRequest{
+				Issuer:         userA,
+				OriginWalletID: uuid.New(),
+				Mode:           service.BatchAtomic,
+				Items:          items(5),
+			},
+			wantErr: service.ErrWalletNotFound,
+		},
+		{
+			name: "destination wallet not found",
+			req: service.BatchRequest{
+				Issuer:         userA,
+				OriginWalletID: walletA,
+				Mode:           service.BatchAtomic,
+				Items:          []service.BatchItem{{DestinationWalletID: uuid.New(), Amount: 5}},
+			},
+			wantErr: service.ErrWalletNotFound,
+		},
+		{
+			name: "access denied",
+			req: service.BatchRequest{
+				Issuer:         userA,
+				OriginWalletID: walletB,
+				Mode:           service.BatchAtomic,
+				Items:          []service.BatchItem{{DestinationWalletID: walletA, Amount: 5}},
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		},
+		{
+			name: "atomic insufficient funds",
+			req: service.BatchRequest{
+				Issuer:         userA,
+				OriginWalletID: walletA,
+				Mode:           service.BatchAtomic,
+				Items:          items(5, 5, 5),
+			},
+			wantErr: service.ErrInsufficientFunds,
+		},
+		{
+			name: "atomic ok",
+			req: service.BatchRequest{
+				Issuer:         userA,
+				OriginWalletID: walletA,
+				Mode:           service.BatchAtomic,
+				Items:          items(5, 2.75),
+			},
+			wantStatus:        []service.TransferStatus{service.TransferCompleted, service.TransferCompleted},
+			wantOriginBalance: 5,
+		},
+		{
+			name: "best effort",
+			req: service.BatchRequest{
+				Issuer:         userA,
+				OriginWalletID: walletA,
+				Mode:           service.BatchBestEffort,
+				Items:          items(5, 10, 5),
+			},
+			wantStatus:        []service.TransferStatus{service.TransferCompleted, service.TransferFailed, service.TransferCompleted},
+			wantOriginBalance: 2.75,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			setupFixtures(ctx, t, db)
+
+			r := TransferRepository{
+				DB:              db,
+				WalletRepo:      &WalletRepository{DB: db},
+				TransactionRepo: &TransactionRepository{DB: db},
+			}
+
+			b, err := r.CreateBatch(ctx, tc.req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var got []service.TransferStatus
+			for _, x := range b.Transfers {
+				got = append(got, x.Status)
+			}
+			if !reflect.DeepEqual(got, tc.wantStatus) {
+				t.Fatalf("unexpected transfers: got: %v, want %v", got, tc.wantStatus)
+			}
+
+			stored, err := r.GetBatch(ctx, tc.req.OriginWalletID, b.ID)
+			if err != nil {
+				t.Fatalf("cannot get batch: %v", err)
+			}
+			if !reflect.DeepEqual(stored, b) {
+				t.Fatalf("unexpected stored batch: \n got:  %+v \n want: %+v", stored, b)
+			}
+
+			w, err := r.WalletRepo.GetByID(ctx, tc.req.OriginWalletID)
+			if err != nil {
+				t.Fatalf("cannot load origin wallet: %v", err)
+			}
+			if got := w.Balance; got != tc.wantOriginBalance {
+				t.Fatalf("unexpected origin balance: got: %v, want %v", got, tc.wantOriginBalance)
+			}
+		})
+	}
+}
+
+func TestGetBatchNotFound(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	r := TransferRepository{DB: db}
+	if _, err := r.GetBatch(ctx, walletA, uuid.New()); !errors.Is(err, service.ErrBatchNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
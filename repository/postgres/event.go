@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/lib/pq"
+)
+
+type EventRepository struct {
+	DB *sql.DB
+}
+
+// depositEvent data of the deposit.created event.
+type depositEvent struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	service.Transaction
+}
+
+// RelayEvents locks the oldest pending events skipping the ones locked by
+// other relays, publishes them and marks them as published in the same DB
+// transaction, so an event is published again if the relay crashes.
+func (r *EventRepository) RelayEvents(ctx context.Context, limit int, p service.EventPublisher) (published int, err error) {
+	ctx = metrics.WithQuery(ctx, "EventRepository.RelayEvents")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	var committed bool
+	defer func() {
+		if err != nil && !committed {
+			err = rollback(tx, err)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, sequence, wallet_id, event_type, payload, created_at
+		FROM events
+		WHERE published_at IS NULL
+		ORDER BY sequence
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query pending events: %v", err)
+	}
+	defer rows.Close()
+
+	var (
+		list    []service.Event
+		wallets []uuid.UUID
+		seen    = make(map[uuid.UUID]bool)
+	)
+	for rows.Next() {
+		var (
+			e       service.Event
+			payload []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Sequence, &e.WalletID, &e.Type, &payload, &e.CreatedAt); err != nil {
+			return 0, fmt.Errorf("cannot scan event: %v", err)
+		}
+		e.Payload = payload
+		e.CreatedAt = e.CreatedAt.UTC()
+		list = append(list, e)
+		if !seen[e.WalletID] {
+			seen[e.WalletID] = true
+			wallets = append(wallets, e.WalletID)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("cannot iterate events: %v", err)
+	}
+	if len(list) == 0 {
+		return 0, tx.Commit()
+	}
+
+	pending, err := r.pendingSequences(ctx, tx, wallets)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		ids        []uuid.UUID
+		blocked    = make(map[uuid.UUID]bool)
+		publishErr error
+	)
+	for _, e := range list {
+		// An older event of the wallet is locked by another relay or has
+		// failed, publishing this one would break the order of the wallet.
+		if blocked[e.WalletID] || len(pending[e.WalletID]) == 0 || pending[e.WalletID][0] != e.Sequence {
+			blocked[e.WalletID] = true
+			continue
+		}
+		if err := p.Publish(ctx, e); err != nil {
+			blocked[e.WalletID] = true
+			if publishErr == nil {
+				publishErr = fmt.Errorf("cannot publish event %s: %w", e.ID, err)
+			}
+			continue
+		}
+		pending[e.WalletID] = pending[e.WalletID][1:]
+		ids = append(ids, e.ID)
+	}
+
+	if len(ids) > 0 {
+		_, err = tx.ExecContext(ctx, `UPDATE events SET published_at = NOW() WHERE id = ANY($1)`, pq.Array(ids))
+		if err != nil {
+			return 0, fmt.Errorf("cannot mark events as published: %v", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+	committed = true
+
+	return len(ids), publishErr
+}
+
+// pendingSequences returns the sequences of the events pending to be
+// published of each wallet in order, including the ones locked by other relays.
+func (r *EventRepository) pendingSequences(
+	ctx context.Context,
+	db queryHandler,
+	wallets []uuid.UUID,
+) (map[uuid.UUID][]int64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT wallet_id, sequence
+		FROM events
+		WHERE published_at IS NULL AND wallet_id = ANY($1)
+		ORDER BY wallet_id, sequence`,
+		pq.Array(wallets),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query pending event sequences: %v", err)
+	}
+	defer rows.Close()
+
+	res := make(map[uuid.UUID][]int64)
+	for rows.Next() {
+		var (
+			walletID uuid.UUID
+			sequence int64
+		)
+		if err := rows.Scan(&walletID, &sequence); err != nil {
+			return nil, fmt.Errorf("cannot scan pending event sequence: %v", err)
+		}
+		res[walletID] = append(res[walletID], sequence)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot iterate pending event sequences: %v", err)
+	}
+
+	return res, nil
+}
+
+// recordEvent stores the event of the wallet in the outbox. It must be called in
+// the DB transaction of the operation after locking the wallet row, so the
+// sequence of the events of the wallet follows the order of the commits.
+func recordEvent(
+	ctx context.Context,
+	db queryHandler,
+	walletID uuid.UUID,
+	eventType service.EventType,
+	data interface{},
+) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cannot marshal event: %v", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO events (wallet_id, event_type, payload)
+		VALUES ($1, $2, $3)`,
+		walletID, eventType, string(payload),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot record event: %v", err)
+	}
+
+	return nil
+}
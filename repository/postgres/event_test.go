@@ -0,0 +1,105 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestRelayEvents(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r            = EventRepository{DB: db}
+		transactions = &TransactionRepository{DB: db}
+		transfers    = &TransferRepository{
+			DB:              db,
+			TransactionRepo: transactions,
+			WalletRepo:      &WalletRepository{DB: db},
+		}
+		dummyErr = errors.New("dummy error")
+	)
+
+	// wallet A: transfer; wallet B: transfer, deposit; wallet C: deposit.
+	_, err := transfers.CreateTransfer(ctx, service.TransferRequest{
+		Issuer:              userA,
+		OriginWalletID:      walletA,
+		DestinationWalletID: walletB,
+		Amount:              1,
+	})
+	if err != nil {
+		t.Fatalf("cannot create transfer: %v", err)
+	}
+	for _, walletID := range []uuid.UUID{walletB, walletC} {
+		if _, err = transactions.Deposit(ctx, walletID, 10); err != nil {
+			t.Fatalf("cannot deposit: %v", err)
+		}
+	}
+
+	// The transfer event of wallet B fails, its deposit event cannot be published before it.
+	var got []service.Event
+	n, err := r.RelayEvents(ctx, 10, service.EventPublisherFunc(func(_ context.Context, e service.Event) error {
+		if e.WalletID == walletB && e.Type == service.TransferCreatedEvent {
+			return dummyErr
+		}
+		got = append(got, e)
+		return nil
+	}))
+	if !errors.Is(err, dummyErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || len(got) != 2 || got[0].WalletID != walletA || got[1].WalletID != walletC {
+		t.Fatalf("unexpected published events: %d, %+v", n, got)
+	}
+
+	// An event locked by another relay blocks the following events of the wallet.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("cannot begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	_, err = tx.ExecContext(ctx, `
+		SELECT id FROM events
+		WHERE wallet_id = $1 AND event_type = $2
+		FOR UPDATE`,
+		walletB, service.TransferCreatedEvent,
+	)
+	if err != nil {
+		t.Fatalf("cannot lock event: %v", err)
+	}
+
+	got = nil
+	publish := service.EventPublisherFunc(func(_ context.Context, e service.Event) error {
+		got = append(got, e)
+		return nil
+	})
+	if n, err = r.RelayEvents(ctx, 10, publish); err != nil || n != 0 {
+		t.Fatalf("unexpected relay with locked event: %d, %v", n, err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("cannot rollback: %v", err)
+	}
+	if n, err = r.RelayEvents(ctx, 10, publish); err != nil || n != 2 {
+		t.Fatalf("unexpected relay: %d, %v", n, err)
+	}
+	if got[0].Type != service.TransferCreatedEvent || got[1].Type != service.DepositCreatedEvent ||
+		got[0].Sequence > got[1].Sequence {
+		t.Fatalf("unexpected order of the wallet events: %+v", got)
+	}
+
+	if n, err = r.RelayEvents(ctx, 10, publish); err != nil || n != 0 {
+		t.Fatalf("unexpected relay without pending events: %d, %v", n, err)
+	}
+}
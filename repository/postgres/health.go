@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/migrations"
+)
+
+// SchemaVersion version of the latest migration, the service
+// is not ready until the database has been migrated to it.
+var SchemaVersion = schemaVersion()
+
+var (
+	ErrSchemaDirty   = errors.New("the last migration failed, the schema is dirty")
+	ErrSchemaVersion = errors.New("unexpected schema version")
+)
+
+type HealthRepository struct {
+	DB *sql.DB
+}
+
+// CheckReady checks that the database is reachable and that its
+// schema has been migrated to SchemaVersion. A newer schema is ready
+// too, so the previous release keeps serving while a new one with
+// additive migrations is deployed.
+func (r *HealthRepository) CheckReady(ctx context.Context) error {
+	ctx = metrics.WithQuery(ctx, "HealthRepository.CheckReady")
+
+	if err := r.DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("cannot ping database: %v", err)
+	}
+
+	// golang-migrate keeps the current version in a single row.
+	var (
+		version int64
+		dirty   bool
+	)
+	err := r.DB.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: the database has not been migrated, want %d", ErrSchemaVersion, SchemaVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot query schema version: %v", err)
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, version)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("%w: got %d, want at least %d", ErrSchemaVersion, version, SchemaVersion)
+	}
+
+	return nil
+}
+
+// schemaVersion returns the version of the latest embedded migration.
+func schemaVersion() int64 {
+	v, err := migrations.Latest()
+	if err != nil {
+		panic(fmt.Sprintf("cannot get the schema version: %v", err))
+	}
+
+	return int64(v)
+}
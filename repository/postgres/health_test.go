@@ -0,0 +1,72 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCheckReady(t *testing.T) {
+	db := setupTestDB(t)
+	r := HealthRepository{DB: db}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.CheckReady(ctx); err != nil {
+		t.Fatalf("the migrated database is not ready, SchemaVersion must be the latest migration: %v", err)
+	}
+
+	tt := []struct {
+		name    string
+		queries []string
+		wantErr error
+	}{
+		{
+			name: "expected schema",
+		},
+		{
+			name:    "dirty schema",
+			queries: []string{`UPDATE schema_migrations SET dirty = true`},
+			wantErr: ErrSchemaDirty,
+		},
+		{
+			name:    "old schema",
+			queries: []string{`UPDATE schema_migrations SET version = version - 1`},
+			wantErr: ErrSchemaVersion,
+		},
+		{
+			name:    "newer schema",
+			queries: []string{`UPDATE schema_migrations SET version = version + 1`},
+		},
+		{
+			name:    "dirty newer schema",
+			queries: []string{`UPDATE schema_migrations SET version = version + 1, dirty = true`},
+			wantErr: ErrSchemaDirty,
+		},
+		{
+			name:    "not migrated",
+			queries: []string{`DELETE FROM schema_migrations`},
+			wantErr: ErrSchemaVersion,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			// Restores the version of the migrated schema.
+			execQueries(ctx, t, db, `DELETE FROM schema_migrations`, `INSERT INTO schema_migrations (version, dirty) VALUES (`+strconv.FormatInt(SchemaVersion, 10)+`, false)`)
+			execQueries(ctx, t, db, tc.queries...)
+
+			if err := r.CheckReady(ctx); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	walletTransactionsChannel = "wallet_transactions"
+
+	// listenerBuffer notifications buffered for each subscriber,
+	// a subscriber that falls behind is closed so it can resume.
+	listenerBuffer       = 64
+	listenerPingInterval = 90 * time.Second
+)
+
+// TransactionListener listens to the notifications of the committed wallet
+// transactions with a dedicated connection and dispatches them to the
+// subscribers of each wallet.
+type TransactionListener struct {
+	DSN string
+
+	mu          sync.Mutex
+	running     bool
+	subscribers map[uuid.UUID]map[chan uuid.UUID]struct{}
+}
+
+type transactionNotification struct {
+	WalletID      uuid.UUID `json:"wallet_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+}
+
+// Run listens to the notifications until the context is done.
+func (l *TransactionListener) Run(ctx context.Context) error {
+	// The listener reconnects on its own, the subscribers are closed on reconnection.
+	listener := pq.NewListener(l.DSN, 10*time.Millisecond, time.Minute, nil)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	if err := listener.Listen(walletTransactionsChannel); err != nil {
+		return fmt.Errorf("cannot listen wallet transactions: %v", err)
+	}
+
+	l.mu.Lock()
+	l.running = true
+	l.mu.Unlock()
+	defer l.closeAll(false)
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			go func() {
+				_ = listener.Ping()
+			}()
+		case n := <-listener.Notify:
+			// The connection has been re-established, the notifications
+			// sent meanwhile are lost so the subscribers must resume.
+			if n == nil {
+				l.closeAll(true)
+				continue
+			}
+			var x transactionNotification
+			if err := json.Unmarshal([]byte(n.Extra), &x); err != nil {
+				// not sent by the trigger, nobody is subscribed to it.
+				continue
+			}
+			l.dispatch(x)
+		}
+	}
+}
+
+// ListenTransactions subscribes to the transactions of the wallet until the context is done.
+func (l *TransactionListener) ListenTransactions(ctx context.Context, walletID uuid.UUID) (<-chan uuid.UUID, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.running {
+		return nil, fmt.Errorf("transaction listener is not running")
+	}
+	if l.subscribers == nil {
+		l.subscribers = make(map[uuid.UUID]map[chan uuid.UUID]struct{})
+	}
+	if l.subscribers[walletID] == nil {
+		l.subscribers[walletID] = make(map[chan uuid.UUID]struct{})
+	}
+
+	ch := make(chan uuid.UUID, listenerBuffer)
+	l.subscribers[walletID][ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.unsubscribe(walletID, ch)
+	}()
+
+	return ch, nil
+}
+
+func (l *TransactionListener) dispatch(n transactionNotification) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.subscribers[n.WalletID] {
+		select {
+		case ch <- n.TransactionID:
+		default:
+			l.unsubscribe(n.WalletID, ch)
+		}
+	}
+}
+
+// closeAll closes the channels of all the subscribers.
+func (l *TransactionListener) closeAll(running bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for walletID, list := range l.subscribers {
+		for ch := range list {
+			l.unsubscribe(walletID, ch)
+		}
+	}
+	l.running = running
+}
+
+// unsubscribe closes the channel if it is still subscribed,
+// must be called holding the lock.
+func (l *TransactionListener) unsubscribe(walletID uuid.UUID, ch chan uuid.UUID) {
+	if _, ok := l.subscribers[walletID][ch]; !ok {
+		return
+	}
+
+	close(ch)
+	delete(l.subscribers[walletID], ch)
+	if len(l.subscribers[walletID]) == 0 {
+		delete(l.subscribers, walletID)
+	}
+}
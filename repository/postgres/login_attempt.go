@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+)
+
+type LoginAttemptRepository struct {
+	DB *sql.DB
+}
+
+func (r *LoginAttemptRepository) GetAttempts(ctx context.Context, key string) (a service.LoginAttempts, err error) {
+	ctx = metrics.WithQuery(ctx, "LoginAttemptRepository.GetAttempts")
+
+	err = r.DB.QueryRowContext(ctx, `
+		SELECT failures, last_failure FROM login_attempts WHERE key = $1`,
+		key,
+	).Scan(&a.Failures, &a.LastFailure)
+	if err == sql.ErrNoRows {
+		return service.LoginAttempts{}, nil
+	}
+	if err != nil {
+		return a, fmt.Errorf("cannot get login attempts: %v", err)
+	}
+
+	return a, nil
+}
+
+// AddFailure increments the failures of the key atomically,
+// so the concurrent attempts cannot exceed the policy.
+func (r *LoginAttemptRepository) AddFailure(
+	ctx context.Context,
+	key string,
+	at time.Time,
+	window time.Duration,
+) (a service.LoginAttempts, err error) {
+	ctx = metrics.WithQuery(ctx, "LoginAttemptRepository.AddFailure")
+
+	err = r.DB.QueryRowContext(ctx, `
+		INSERT INTO login_attempts AS a (key, failures, last_failure)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN a.last_failure <= $3 THEN 1 ELSE a.failures + 1 END,
+			last_failure = EXCLUDED.last_failure
+		RETURNING failures, last_failure`,
+		key, at, at.Add(-window),
+	).Scan(&a.Failures, &a.LastFailure)
+	if err != nil {
+		return a, fmt.Errorf("cannot add failed login attempt: %v", err)
+	}
+
+	return a, nil
+}
+
+func (r *LoginAttemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	ctx = metrics.WithQuery(ctx, "LoginAttemptRepository.ResetAttempts")
+
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("cannot reset login attempts: %v", err)
+	}
+
+	return nil
+}
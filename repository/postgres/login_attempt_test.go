@@ -0,0 +1,89 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestLoginAttempts(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		r      = LoginAttemptRepository{DB: db}
+		now    = time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC)
+		window = time.Minute
+	)
+
+	if got, err := r.GetAttempts(ctx, "login:foo"); err != nil || got != (service.LoginAttempts{}) {
+		t.Fatalf("unexpected attempts of an unknown key: %+v, err: %v", got, err)
+	}
+
+	steps := []struct {
+		at   time.Time
+		want int
+	}{
+		{at: now, want: 1},
+		{at: now.Add(time.Second), want: 2},
+		{at: now.Add(time.Second + window), want: 1},
+	}
+	for _, step := range steps {
+		got, err := r.AddFailure(ctx, "login:foo", step.at, window)
+		if err != nil {
+			t.Fatalf("cannot add failure: %v", err)
+		}
+		if got.Failures != step.want || !got.LastFailure.Equal(step.at) {
+			t.Fatalf("unexpected attempts: \n got:  %+v \n want: %d at %v", got, step.want, step.at)
+		}
+	}
+
+	if err := r.ResetAttempts(ctx, "login:foo"); err != nil {
+		t.Fatalf("cannot reset attempts: %v", err)
+	}
+	if got, err := r.GetAttempts(ctx, "login:foo"); err != nil || got != (service.LoginAttempts{}) {
+		t.Fatalf("unexpected attempts after reset: %+v, err: %v", got, err)
+	}
+}
+
+func TestConcurrentLoginFailures(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		r   = LoginAttemptRepository{DB: db}
+		now = time.Now().UTC()
+		n   = 20
+		wg  sync.WaitGroup
+		got = make([]int, n)
+	)
+
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			a, err := r.AddFailure(ctx, "login:foo", now, time.Minute)
+			if err != nil {
+				t.Errorf("cannot add failure: %v", err)
+			}
+			got[i] = a.Failures
+		}(i)
+	}
+	wg.Wait()
+
+	// every failure sees its own count.
+	sort.Ints(got)
+	for i, failures := range got {
+		if failures != i+1 {
+			t.Fatalf("unexpected failures count: got %d, want %d", failures, i+1)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+)
+
+const paymentRequestColumns = `id, requester_id, requester_wallet_id, payer_id, payer_wallet_id, amount, message,
+	status, transfer_id, expires_at, created_at, updated_at`
+
+type PaymentRequestRepository struct {
+	DB           *sql.DB
+	TransferRepo *TransferRepository
+}
+
+func (r *PaymentRequestRepository) CreatePaymentRequest(ctx context.Context, p service.PaymentRequest) (service.PaymentRequest, error) {
+	ctx = metrics.WithQuery(ctx, "PaymentRequestRepository.CreatePaymentRequest")
+
+	row := r.DB.QueryRowContext(ctx, `
+		INSERT INTO payment_requests (requester_id, requester_wallet_id, payer_id, payer_wallet_id, amount, message,
+			status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at`,
+		p.RequesterID, p.RequesterWalletID, p.PayerID, p.PayerWalletID, p.Amount, p.Message,
+		p.Status, p.ExpiresAt,
+	)
+	if err := row.Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return p, fmt.Errorf("cannot insert payment request: %v", err)
+	}
+	p.ExpiresAt = p.ExpiresAt.UTC()
+	p.CreatedAt = p.CreatedAt.UTC()
+	p.UpdatedAt = p.UpdatedAt.UTC()
+
+	return p, nil
+}
+
+func (r *PaymentRequestRepository) GetPaymentRequest(ctx context.Context, requestID uuid.UUID) (p service.PaymentRequest, err error) {
+	ctx = metrics.WithQuery(ctx, "PaymentRequestRepository.GetPaymentRequest")
+
+	row := r.DB.QueryRowContext(ctx, `
+		SELECT `+paymentRequestColumns+`
+		FROM payment_requests
+		WHERE id = $1`,
+		requestID,
+	)
+	p, err = scanPaymentRequest(row)
+	if err == sql.ErrNoRows {
+		return p, service.ErrPaymentRequestNotFound
+	}
+	if err != nil {
+		return p, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve payment request"), err)
+	}
+
+	return p, nil
+}
+
+func (r *PaymentRequestRepository) ListPaymentRequests(
+	ctx context.Context,
+	userID uuid.UUID,
+	direction service.PaymentRequestDirection,
+	status *service.PaymentRequestStatus,
+) (list []service.PaymentRequest, err error) {
+	ctx = metrics.WithQuery(ctx, "PaymentRequestRepository.ListPaymentRequests")
+
+	column := "payer_id"
+	if direction == service.OutgoingPaymentRequests {
+		column = "requester_id"
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+paymentRequestColumns+`
+		FROM payment_requests
+		WHERE `+column+` = $1 AND ($2::payment_request_status IS NULL OR status = $2)
+		ORDER BY created_at DESC, id`,
+		userID, status,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query payment requests: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanPaymentRequest(rows)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan payment request"), err)
+		}
+		list = append(list, p)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate payment requests"), err)
+	}
+
+	return list, nil
+}
+
+// UpdatePaymentRequest updates the payment request only if it still
+// has the given status, protecting it from concurrent answers.
+func (r *PaymentRequestRepository) UpdatePaymentRequest(
+	ctx context.Context,
+	p service.PaymentRequest,
+	current service.PaymentRequestStatus,
+) (service.PaymentRequest, error) {
+	ctx = metrics.WithQuery(ctx, "PaymentRequestRepository.UpdatePaymentRequest")
+
+	return r.update(ctx, r.DB, p, current)
+}
+
+// AcceptPaymentRequest accepts the pending payment request and transfers
+// its funds in the same DB transaction, so the request is either paid
+// and linked to its transfer or remains pending.
+func (r *PaymentRequestRepository) AcceptPaymentRequest(
+	ctx context.Context,
+	p service.PaymentRequest,
+	req service.TransferRequest,
+) (_ service.PaymentRequest, err error) {
+	ctx = metrics.WithQuery(ctx, "PaymentRequestRepository.AcceptPaymentRequest")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return p, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	// the conditional update locks the request, a concurrent answer waits
+	// for this transaction and finds the request is not pending anymore.
+	p.Status = service.PaymentRequestAccepted
+	if p, err = r.accept(ctx, tx, p); err != nil {
+		return p, err
+	}
+	t, err := r.TransferRepo.transfer(ctx, tx, req)
+	if err != nil {
+		return p, err
+	}
+	p.TransferID = &t.ID
+	if p, err = r.update(ctx, tx, p, service.PaymentRequestAccepted); err != nil {
+		return p, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return p, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return p, nil
+}
+
+// accept marks the request as accepted if it's still pending and has not
+// expired, a request that expires after being loaded cannot be paid.
+func (r *PaymentRequestRepository) accept(ctx context.Context, tx *sql.Tx, p service.PaymentRequest) (service.PaymentRequest, error) {
+	row := tx.QueryRowContext(ctx, `
+		UPDATE payment_requests
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3 AND expires_at > NOW()
+		RETURNING updated_at`,
+		service.PaymentRequestAccepted, p.ID, service.PaymentRequestPending,
+	)
+	err := row.Scan(&p.UpdatedAt)
+	if err == nil {
+		p.UpdatedAt = p.UpdatedAt.UTC()
+		return p, nil
+	}
+	if err != sql.ErrNoRows {
+		return p, fmt.Errorf("cannot accept payment request: %v", err)
+	}
+
+	var pending bool
+	row = tx.QueryRowContext(ctx, `
+		SELECT status = $1
+		FROM payment_requests
+		WHERE id = $2`,
+		service.PaymentRequestPending, p.ID,
+	)
+	if err = row.Scan(&pending); err != nil && err != sql.ErrNoRows {
+		return p, fmt.Errorf("cannot retrieve payment request status: %v", err)
+	}
+	if pending {
+		return p, service.ErrPaymentRequestExpired
+	}
+
+	return p, fmt.Errorf("%w: payment request is not %s", service.ErrPaymentRequestNotPending, service.PaymentRequestPending)
+}
+
+func (r *PaymentRequestRepository) update(
+	ctx context.Context,
+	db queryHandler,
+	p service.PaymentRequest,
+	current service.PaymentRequestStatus,
+) (service.PaymentRequest, error) {
+	row := db.QueryRowContext(ctx, `
+		UPDATE payment_requests
+		SET status = $1, transfer_id = $2, updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING updated_at`,
+		p.Status, p.TransferID, p.ID, current,
+	)
+	err := row.Scan(&p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return p, fmt.Errorf("%w: payment request is not %s", service.ErrPaymentRequestNotPending, current)
+	}
+	if err != nil {
+		return p, fmt.Errorf("cannot update payment request: %v", err)
+	}
+	p.UpdatedAt = p.UpdatedAt.UTC()
+
+	return p, nil
+}
+
+// ExpirePaymentRequests marks all the overdue pending requests as expired at once.
+func (r *PaymentRequestRepository) ExpirePaymentRequests(ctx context.Context, until time.Time) (list []service.PaymentRequest, err error) {
+	ctx = metrics.WithQuery(ctx, "PaymentRequestRepository.ExpirePaymentRequests")
+
+	rows, err := r.DB.QueryContext(ctx, `
+		UPDATE payment_requests
+		SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND expires_at <= $3
+		RETURNING `+paymentRequestColumns,
+		service.PaymentRequestExpired, service.PaymentRequestPending, until,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot expire payment requests: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanPaymentRequest(rows)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan payment request"), err)
+		}
+		list = append(list, p)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate payment requests"), err)
+	}
+
+	return list, nil
+}
+
+func scanPaymentRequest(row rowScanner) (p service.PaymentRequest, err error) {
+	err = row.Scan(
+		&p.ID,
+		&p.RequesterID,
+		&p.RequesterWalletID,
+		&p.PayerID,
+		&p.PayerWalletID,
+		&p.Amount,
+		&p.Message,
+		&p.Status,
+		&p.TransferID,
+		&p.ExpiresAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return p, err
+	}
+
+	p.ExpiresAt = p.ExpiresAt.UTC()
+	p.CreatedAt = p.CreatedAt.UTC()
+	p.UpdatedAt = p.UpdatedAt.UTC()
+
+	return p, nil
+}
@@ -0,0 +1,225 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestPaymentRequestCRUD(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r       = PaymentRequestRepository{DB: db}
+		payerID = uuid.MustParse("f65697a1-dbe7-49b5-93d6-bbfc512a46f6")
+		pending = service.PaymentRequestPending
+		p       = service.PaymentRequest{
+			RequesterID:       userA.ID,
+			RequesterWalletID: walletA,
+			PayerID:           payerID,
+			PayerWalletID:     walletB,
+			Amount:            5,
+			Message:           ptrToStr("dinner"),
+			Status:            service.PaymentRequestPending,
+			ExpiresAt:         rfc3339MustParse(t, "2099-01-01T00:00:00Z"),
+		}
+	)
+
+	p, err := r.CreatePaymentRequest(ctx, p)
+	if err != nil {
+		t.Fatalf("cannot create payment request: %v", err)
+	}
+
+	got, err := r.GetPaymentRequest(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("cannot get payment request: %v", err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Fatalf("unexpected payment request: \n got:  %+v \n want: %+v", got, p)
+	}
+
+	if _, err = r.GetPaymentRequest(ctx, uuid.New()); !errors.Is(err, service.ErrPaymentRequestNotFound) {
+		t.Fatalf("unexpected error getting missing payment request: %v", err)
+	}
+
+	for _, x := range []struct {
+		userID    uuid.UUID
+		direction service.PaymentRequestDirection
+		want      int
+	}{
+		{userID: payerID, direction: service.IncomingPaymentRequests, want: 1},
+		{userID: payerID, direction: service.OutgoingPaymentRequests, want: 0},
+		{userID: userA.ID, direction: service.OutgoingPaymentRequests, want: 1},
+		{userID: userA.ID, direction: service.IncomingPaymentRequests, want: 0},
+	} {
+		list, err := r.ListPaymentRequests(ctx, x.userID, x.direction, &pending)
+		if err != nil {
+			t.Fatalf("cannot list payment requests: %v", err)
+		}
+		if len(list) != x.want {
+			t.Fatalf("unexpected %s payment requests: got %d, want %d", x.direction, len(list), x.want)
+		}
+	}
+
+	p.Status = service.PaymentRequestAccepted
+	p.TransferID = &completedTransferID
+	if p, err = r.UpdatePaymentRequest(ctx, p, service.PaymentRequestPending); err != nil {
+		t.Fatalf("cannot update payment request: %v", err)
+	}
+	if _, err = r.UpdatePaymentRequest(ctx, p, service.PaymentRequestPending); !errors.Is(err, service.ErrPaymentRequestNotPending) {
+		t.Fatalf("unexpected error updating answered payment request: %v", err)
+	}
+
+	list, err := r.ListPaymentRequests(ctx, payerID, service.IncomingPaymentRequests, nil)
+	if err != nil {
+		t.Fatalf("cannot list payment requests: %v", err)
+	}
+	if want := []service.PaymentRequest{p}; !reflect.DeepEqual(list, want) {
+		t.Fatalf("unexpected payment request list: \n got:  %+v \n want: %+v", list, want)
+	}
+}
+
+func TestExpirePaymentRequests(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r         = PaymentRequestRepository{DB: db}
+		expiresAt = rfc3339MustParse(t, "2099-01-01T00:00:00Z")
+	)
+
+	p, err := r.CreatePaymentRequest(ctx, service.PaymentRequest{
+		RequesterID:       userA.ID,
+		RequesterWalletID: walletA,
+		PayerID:           uuid.MustParse("f65697a1-dbe7-49b5-93d6-bbfc512a46f6"),
+		PayerWalletID:     walletB,
+		Amount:            5,
+		Status:            service.PaymentRequestPending,
+		ExpiresAt:         expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("cannot create payment request: %v", err)
+	}
+
+	list, err := r.ExpirePaymentRequests(ctx, expiresAt.Add(-time.Second))
+	if err != nil || len(list) != 0 {
+		t.Fatalf("unexpected expiration before due: %v, %v", list, err)
+	}
+
+	list, err = r.ExpirePaymentRequests(ctx, expiresAt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != p.ID || list[0].Status != service.PaymentRequestExpired {
+		t.Fatalf("unexpected expired payment requests: %+v", list)
+	}
+}
+
+func TestAcceptPaymentRequest(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		wallets = &WalletRepository{DB: db}
+		r       = PaymentRequestRepository{
+			DB: db,
+			TransferRepo: &TransferRepository{
+				DB:              db,
+				WalletRepo:      wallets,
+				TransactionRepo: &TransactionRepository{DB: db},
+			},
+		}
+		payer = service.User{ID: uuid.MustParse("f65697a1-dbe7-49b5-93d6-bbfc512a46f6")}
+	)
+
+	create := func(amount float64, expiresAt time.Time) (service.PaymentRequest, service.TransferRequest) {
+		p, err := r.CreatePaymentRequest(ctx, service.PaymentRequest{
+			RequesterID:       userA.ID,
+			RequesterWalletID: walletA,
+			PayerID:           payer.ID,
+			PayerWalletID:     walletB,
+			Amount:            amount,
+			Status:            service.PaymentRequestPending,
+			ExpiresAt:         expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("cannot create payment request: %v", err)
+		}
+		return p, service.TransferRequest{
+			Issuer:              payer,
+			OriginWalletID:      walletB,
+			DestinationWalletID: walletA,
+			Amount:              amount,
+		}
+	}
+	balance := func() float64 {
+		w, err := wallets.GetByID(ctx, walletB)
+		if err != nil {
+			t.Fatalf("cannot get payer wallet: %v", err)
+		}
+		return w.Balance
+	}
+
+	// A request that expires after being loaded cannot be paid.
+	p, req := create(5, time.Now().Add(-time.Second))
+	if _, err := r.AcceptPaymentRequest(ctx, p, req); !errors.Is(err, service.ErrPaymentRequestExpired) {
+		t.Fatalf("unexpected error accepting expired payment request: %v", err)
+	}
+	if got := balance(); got != 52.25 {
+		t.Fatalf("unexpected payer balance after accepting the expired request: %v", got)
+	}
+
+	// A failed transfer leaves the request pending, ready to be accepted again.
+	expiresAt := rfc3339MustParse(t, "2099-01-01T00:00:00Z")
+	p, req = create(100, expiresAt)
+	if _, err := r.AcceptPaymentRequest(ctx, p, req); !errors.Is(err, service.ErrInsufficientFunds) {
+		t.Fatalf("unexpected error accepting unaffordable payment request: %v", err)
+	}
+	got, err := r.GetPaymentRequest(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("cannot get payment request: %v", err)
+	}
+	if got.Status != service.PaymentRequestPending || got.TransferID != nil {
+		t.Fatalf("unexpected payment request after the failed transfer: %+v", got)
+	}
+	if got := balance(); got != 52.25 {
+		t.Fatalf("unexpected payer balance after the failed transfer: %v", got)
+	}
+
+	p, req = create(5, expiresAt)
+	p, err = r.AcceptPaymentRequest(ctx, p, req)
+	if err != nil {
+		t.Fatalf("cannot accept payment request: %v", err)
+	}
+	if p.Status != service.PaymentRequestAccepted || p.TransferID == nil {
+		t.Fatalf("unexpected accepted payment request: %+v", p)
+	}
+	if got, err = r.GetPaymentRequest(ctx, p.ID); err != nil || !reflect.DeepEqual(got, p) {
+		t.Fatalf("unexpected stored payment request: \n got:  %+v \n want: %+v \n err: %v", got, p, err)
+	}
+	if got := balance(); got != 47.25 {
+		t.Fatalf("unexpected payer balance after the transfer: %v", got)
+	}
+
+	// The request cannot be paid twice.
+	if _, err = r.AcceptPaymentRequest(ctx, p, req); !errors.Is(err, service.ErrPaymentRequestNotPending) {
+		t.Fatalf("unexpected error accepting the payment request twice: %v", err)
+	}
+	if got := balance(); got != 47.25 {
+		t.Fatalf("unexpected payer balance after the second accept: %v", got)
+	}
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+type DiscrepancyType string
+
+const (
+	// RunningBalanceMismatch the balance stored in a wallet transaction does not
+	// match the sum of the wallet transaction amounts up to it.
+	RunningBalanceMismatch DiscrepancyType = "running_balance_mismatch"
+	// CachedBalanceMismatch the wallet balance does not match its transaction history.
+	CachedBalanceMismatch DiscrepancyType = "cached_balance_mismatch"
+	// OrphanedReference the journal entry references a transfer that does not exist.
+	OrphanedReference DiscrepancyType = "orphaned_reference"
+	// UnbalancedTransfer the wallet legs of a transfer do not net to zero.
+	UnbalancedTransfer DiscrepancyType = "unbalanced_transfer"
+)
+
+type Discrepancy struct {
+	Type          DiscrepancyType `json:"type"`
+	WalletID      *uuid.UUID      `json:"wallet_id,omitempty"`
+	TransactionID *uuid.UUID      `json:"transaction_id,omitempty"`
+	ReferenceID   *uuid.UUID      `json:"reference_id,omitempty"`
+	Expected      float64         `json:"expected"`
+	Actual        float64         `json:"actual"`
+}
+
+type ReconciliationReport struct {
+	Wallets       int           `json:"wallets"`
+	Transactions  int           `json:"transactions"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// Balanced returns true if no discrepancies were found.
+func (r ReconciliationReport) Balanced() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Reconcile verifies the wallet balances against the ledger. It replays the
+// transactions of each wallet in the order they were posted checking the
+// running balances and the cached wallet balance, and reports orphaned
+// references and transfers whose wallet legs do not net to zero.
+func Reconcile(ctx context.Context, db *sql.DB) (report ReconciliationReport, err error) {
+	// Read everything in the same snapshot to avoid false positives
+	// caused by the operations that are committed meanwhile.
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return report, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	report.Discrepancies = []Discrepancy{}
+	for _, check := range []func(context.Context, queryHandler, *ReconciliationReport) error{
+		reconcileBalances,
+		reconcileReferences,
+		reconcileTransfers,
+	} {
+		if err = check(ctx, tx, &report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// reconcileBalances replays the wallet transactions adding up their amounts,
+// the running balance of each transaction and the cached wallet balance
+// must match the sum of the amounts up to that point.
+func reconcileBalances(ctx context.Context, db queryHandler, report *ReconciliationReport) error {
+	wallets := make(map[uuid.UUID]float64)
+	rows, err := db.QueryContext(ctx, `SELECT id, balance FROM wallets ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("cannot query wallets: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var (
+			id      uuid.UUID
+			balance float64
+		)
+		if err := rows.Scan(&id, &balance); err != nil {
+			return fmt.Errorf("%w: %v", fmt.Errorf("cannot scan wallet"), err)
+		}
+		wallets[id] = balance
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate wallets"), err)
+	}
+	report.Wallets = len(ids)
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT p.id, p.wallet_id, p.amount, p.balance
+		FROM postings p
+		WHERE p.wallet_id IS NOT NULL
+		ORDER BY p.wallet_id, p.sequence`,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query transactions: %v", err)
+	}
+	defer rows.Close()
+
+	sums := make(map[uuid.UUID]float64)
+	for rows.Next() {
+		var (
+			id, walletID    uuid.UUID
+			amount, balance float64
+		)
+		if err := rows.Scan(&id, &walletID, &amount, &balance); err != nil {
+			return fmt.Errorf("%w: %v", fmt.Errorf("cannot scan transaction"), err)
+		}
+		report.Transactions++
+
+		sums[walletID] += amount
+		if expected := sums[walletID]; !sameAmount(expected, balance) {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				Type:          RunningBalanceMismatch,
+				WalletID:      &walletID,
+				TransactionID: &id,
+				Expected:      roundAmount(expected),
+				Actual:        balance,
+			})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate transactions"), err)
+	}
+
+	for _, id := range ids {
+		id := id
+		if balance := wallets[id]; !sameAmount(sums[id], balance) {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				Type:     CachedBalanceMismatch,
+				WalletID: &id,
+				Expected: roundAmount(sums[id]),
+				Actual:   balance,
+			})
+		}
+	}
+
+	return nil
+}
+
+func reconcileReferences(ctx context.Context, db queryHandler, report *ReconciliationReport) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT j.id, j.reference_id
+		FROM journal_entries j
+		LEFT JOIN transfers t ON t.id = j.reference_id
+		WHERE j.reference_id IS NOT NULL AND t.id IS NULL
+		ORDER BY j.date, j.id`,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query orphaned references: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entryID, referenceID uuid.UUID
+		if err := rows.Scan(&entryID, &referenceID); err != nil {
+			return fmt.Errorf("%w: %v", fmt.Errorf("cannot scan orphaned reference"), err)
+		}
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			Type:          OrphanedReference,
+			TransactionID: &entryID,
+			ReferenceID:   &referenceID,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate orphaned references"), err)
+	}
+
+	return nil
+}
+
+// reconcileTransfers checks that the wallet legs of every transfer net to zero,
+// the journal entry of a broken transfer can still balance using the suspense account.
+func reconcileTransfers(ctx context.Context, db queryHandler, report *ReconciliationReport) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT j.reference_id, SUM(p.amount)
+		FROM journal_entries j
+		JOIN postings p ON p.journal_entry_id = j.id AND p.wallet_id IS NOT NULL
+		WHERE j.entry_type = 'transfer'
+		GROUP BY j.reference_id
+		HAVING SUM(p.amount) <> 0 OR COUNT(*) <> 2
+		ORDER BY j.reference_id`,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query unbalanced transfers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			referenceID *uuid.UUID
+			sum         float64
+		)
+		if err := rows.Scan(&referenceID, &sum); err != nil {
+			return fmt.Errorf("%w: %v", fmt.Errorf("cannot scan unbalanced transfer"), err)
+		}
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			Type:        UnbalancedTransfer,
+			ReferenceID: referenceID,
+			Actual:      sum,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate unbalanced transfers"), err)
+	}
+
+	return nil
+}
+
+// roundAmount rounds the amount to the four decimals stored in the database.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*10000) / 10000
+}
+
+func sameAmount(a, b float64) bool {
+	return roundAmount(a) == roundAmount(b)
+}
@@ -0,0 +1,204 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestReconcile(t *testing.T) {
+	db := setupTestDB(t)
+
+	var (
+		transferEntryID = uuid.MustParse("4bce4401-6b35-4fa1-94b9-ac5ce05d29b1")
+		transferID      = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
+		depositID       = uuid.MustParse("be3c602a-4410-475b-b39f-016c451726a1")
+		orphanID        = uuid.MustParse("e3f3a1b0-0d6c-4d7e-9d0a-5b8f2f4c1a77")
+	)
+
+	tt := []struct {
+		name    string
+		corrupt []string
+		want    []Discrepancy
+	}{
+		{
+			name: "balanced",
+			want: []Discrepancy{},
+		},
+		{
+			name: "cached balance mismatch",
+			corrupt: []string{
+				`UPDATE wallets SET balance = 1 WHERE id = 'f0212317-88db-4dd4-ba0e-39757e1ebcc6'`,
+			},
+			want: []Discrepancy{{
+				Type:     CachedBalanceMismatch,
+				WalletID: &walletC,
+				Expected: 0,
+				Actual:   1,
+			}},
+		},
+		{
+			name: "running balance mismatch",
+			corrupt: []string{
+				`UPDATE postings SET balance = 19 WHERE id = 'be3c602a-4410-475b-b39f-016c451726a1'`,
+			},
+			want: []Discrepancy{{
+				Type:          RunningBalanceMismatch,
+				WalletID:      &walletD,
+				TransactionID: &depositID,
+				Expected:      18,
+				Actual:        19,
+			}},
+		},
+		{
+			name: "orphaned reference",
+			corrupt: []string{
+				`UPDATE journal_entries SET reference_id = 'e3f3a1b0-0d6c-4d7e-9d0a-5b8f2f4c1a77' WHERE id = '4bce4401-6b35-4fa1-94b9-ac5ce05d29b1'`,
+			},
+			want: []Discrepancy{{
+				Type:          OrphanedReference,
+				TransactionID: &transferEntryID,
+				ReferenceID:   &orphanID,
+			}},
+		},
+		{
+			name: "unbalanced transfer",
+			corrupt: []string{
+				`DELETE FROM postings WHERE id = '60978032-b118-4727-a123-4468dced4104'`,
+				`UPDATE wallets SET balance = 45 WHERE id = '4e1d841d-e53f-4785-ba4d-99df05f11eee'`,
+				`INSERT INTO postings (journal_entry_id, system_account, amount)
+				 VALUES ('4bce4401-6b35-4fa1-94b9-ac5ce05d29b1', 'suspense', 7.25)`,
+			},
+			want: []Discrepancy{{
+				Type:        UnbalancedTransfer,
+				ReferenceID: &transferID,
+				Actual:      -7.25,
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			setupFixtures(ctx, t, db)
+
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("cannot begin transaction: %v", err)
+			}
+			for _, query := range tc.corrupt {
+				if _, err := tx.ExecContext(ctx, query); err != nil {
+					_ = tx.Rollback()
+					t.Fatalf("cannot corrupt the ledger: %v", err)
+				}
+			}
+			if err = tx.Commit(); err != nil {
+				t.Fatalf("cannot commit the corruption: %v", err)
+			}
+
+			report, err := Reconcile(ctx, db)
+			if err != nil {
+				t.Fatalf("cannot reconcile: %v", err)
+			}
+			if report.Wallets != 4 {
+				t.Fatalf("unexpected number of wallets: got %d, want 4", report.Wallets)
+			}
+			if !reflect.DeepEqual(report.Discrepancies, tc.want) {
+				t.Fatalf("unexpected discrepancies: \n got:  %+v \n want: %+v", report.Discrepancies, tc.want)
+			}
+			if got, want := report.Balanced(), len(tc.want) == 0; got != want {
+				t.Fatalf("unexpected balanced report: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestReconcileBatch checks the ledger order of the postings of the same DB
+// transaction, which share the date of their journal entries.
+func TestReconcileBatch(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		transactions = &TransactionRepository{DB: db}
+		transfers    = &TransferRepository{
+			DB:              db,
+			WalletRepo:      &WalletRepository{DB: db},
+			TransactionRepo: transactions,
+		}
+		req = service.BatchRequest{
+			Issuer:         userA,
+			OriginWalletID: walletA,
+			Mode:           service.BatchAtomic,
+		}
+	)
+	for i := 0; i < 10; i++ {
+		destination := walletB
+		if i%2 == 1 {
+			destination = walletC
+		}
+		req.Items = append(req.Items, service.BatchItem{DestinationWalletID: destination, Amount: 0.5})
+	}
+	if _, err := transfers.CreateBatch(ctx, req); err != nil {
+		t.Fatalf("cannot create batch: %v", err)
+	}
+
+	report, err := Reconcile(ctx, db)
+	if err != nil {
+		t.Fatalf("cannot reconcile: %v", err)
+	}
+	if !report.Balanced() {
+		t.Fatalf("unexpected discrepancies: %+v", report.Discrepancies)
+	}
+
+	// The pages follow the running balance without skipping or repeating transactions.
+	var (
+		balance float64
+		seen    = make(map[uuid.UUID]bool)
+		opt     = service.ListOptions{PerPage: 3}
+	)
+	for {
+		list, err := transactions.ListTransactions(ctx, walletA, opt)
+		if err != nil {
+			t.Fatalf("cannot list transactions: %v", err)
+		}
+		for _, tx := range list.Results {
+			if seen[tx.ID] {
+				t.Fatalf("transaction %s listed twice", tx.ID)
+			}
+			seen[tx.ID] = true
+			balance = roundAmount(balance + tx.Amount)
+			if tx.Balance != balance {
+				t.Fatalf("unexpected running balance of transaction %s: got %v, want %v", tx.ID, tx.Balance, balance)
+			}
+		}
+		if list.NextID == nil {
+			break
+		}
+		opt.FromID = list.NextID
+	}
+	if got, want := len(seen), 12; got != want {
+		t.Fatalf("unexpected number of transactions: got %d, want %d", got, want)
+	}
+	if balance != 7.75 {
+		t.Fatalf("unexpected final balance: got %v, want 7.75", balance)
+	}
+
+	at, err := transactions.BalanceAt(ctx, walletA, time.Now())
+	if err != nil {
+		t.Fatalf("cannot get the balance: %v", err)
+	}
+	if at != balance {
+		t.Fatalf("unexpected current balance: got %v, want %v", at, balance)
+	}
+}
@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+)
+
+const standingOrderColumns = `id, issuer_id, origin_wallet_id, destination_wallet_id, amount, message,
+	frequency, day_of_month, start_date, end_date, count, status, runs, next_run_at, created_at`
+
+type StandingOrderRepository struct {
+	DB           *sql.DB
+	TransferRepo *TransferRepository
+}
+
+func (r *StandingOrderRepository) CreateStandingOrder(ctx context.Context, o service.StandingOrder) (service.StandingOrder, error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.CreateStandingOrder")
+
+	row := r.DB.QueryRowContext(ctx, `
+		INSERT INTO standing_orders (issuer_id, origin_wallet_id, destination_wallet_id, amount, message,
+			frequency, day_of_month, start_date, end_date, count, status, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at`,
+		o.IssuerID, o.OriginWalletID, o.DestinationWalletID, o.Amount, o.Message,
+		o.Recurrence.Frequency, o.Recurrence.DayOfMonth, o.Recurrence.StartDate, o.Recurrence.EndDate, o.Recurrence.Count,
+		o.Status, o.NextRunAt,
+	)
+	if err := row.Scan(&o.ID, &o.CreatedAt); err != nil {
+		return o, fmt.Errorf("cannot insert standing order: %v", err)
+	}
+	o.CreatedAt = o.CreatedAt.UTC()
+
+	return o, nil
+}
+
+func (r *StandingOrderRepository) GetStandingOrder(ctx context.Context, walletID, orderID uuid.UUID) (o service.StandingOrder, err error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.GetStandingOrder")
+
+	row := r.DB.QueryRowContext(ctx, `
+		SELECT `+standingOrderColumns+`
+		FROM standing_orders
+		WHERE id = $1 AND origin_wallet_id = $2`,
+		orderID, walletID,
+	)
+	o, err = scanStandingOrder(row)
+	if err == sql.ErrNoRows {
+		return o, service.ErrStandingOrderNotFound
+	}
+	if err != nil {
+		return o, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve standing order"), err)
+	}
+
+	return o, nil
+}
+
+func (r *StandingOrderRepository) ListStandingOrders(ctx context.Context, walletID uuid.UUID) (list []service.StandingOrder, err error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.ListStandingOrders")
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+standingOrderColumns+`
+		FROM standing_orders
+		WHERE origin_wallet_id = $1
+		ORDER BY created_at, id`,
+		walletID,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query standing orders: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		o, err := scanStandingOrder(rows)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan standing order"), err)
+		}
+		list = append(list, o)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate standing orders"), err)
+	}
+
+	return list, nil
+}
+
+func (r *StandingOrderRepository) UpdateStandingOrder(ctx context.Context, orderID uuid.UUID, upd service.StandingOrderUpdate) (service.StandingOrder, error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.UpdateStandingOrder")
+
+	// The amount and the message are the only columns that are updated, and
+	// only while the order is active, so an update never rolls back the runs
+	// of an execution that happens between reading the order and updating it.
+	row := r.DB.QueryRowContext(ctx, `
+		UPDATE standing_orders
+		SET amount = COALESCE($1, amount), message = COALESCE($2, message)
+		WHERE id = $3 AND status = $4
+		RETURNING `+standingOrderColumns,
+		upd.Amount, upd.Message, orderID, service.StandingOrderActive,
+	)
+
+	return r.scanActive(row, "cannot update standing order")
+}
+
+func (r *StandingOrderRepository) CancelStandingOrder(ctx context.Context, orderID uuid.UUID) (service.StandingOrder, error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.CancelStandingOrder")
+
+	row := r.DB.QueryRowContext(ctx, `
+		UPDATE standing_orders
+		SET status = $1, next_run_at = NULL
+		WHERE id = $2 AND status = $3
+		RETURNING `+standingOrderColumns,
+		service.StandingOrderCancelled, orderID, service.StandingOrderActive,
+	)
+
+	return r.scanActive(row, "cannot cancel standing order")
+}
+
+// scanActive scans the standing order returned by an update
+// that only matches the active orders.
+func (r *StandingOrderRepository) scanActive(row rowScanner, msg string) (service.StandingOrder, error) {
+	o, err := scanStandingOrder(row)
+	if err == sql.ErrNoRows {
+		return o, service.ErrStandingOrderNotActive
+	}
+	if err != nil {
+		return o, fmt.Errorf("%s: %v", msg, err)
+	}
+
+	return o, nil
+}
+
+func (r *StandingOrderRepository) ListStandingOrderRuns(ctx context.Context, orderID uuid.UUID) (list []service.StandingOrderRun, err error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.ListStandingOrderRuns")
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, standing_order_id, transfer_id, scheduled_at, date, status, failure_reason
+		FROM standing_order_runs
+		WHERE standing_order_id = $1
+		ORDER BY date, id`,
+		orderID,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query standing order runs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var x service.StandingOrderRun
+		err := rows.Scan(&x.ID, &x.StandingOrderID, &x.TransferID, &x.ScheduledAt, &x.Date, &x.Status, &x.FailureReason)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan standing order run"), err)
+		}
+		x.ScheduledAt = x.ScheduledAt.UTC()
+		x.Date = x.Date.UTC()
+		list = append(list, x)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate standing order runs"), err)
+	}
+
+	return list, nil
+}
+
+// ExecuteStandingOrders executes the due standing orders one by one,
+// each execution in its own DB transaction. An order that has
+// missed several executions will be executed once per each.
+func (r *StandingOrderRepository) ExecuteStandingOrders(ctx context.Context, until time.Time) (list []service.StandingOrderRun, err error) {
+	ctx = metrics.WithQuery(ctx, "StandingOrderRepository.ExecuteStandingOrders")
+
+	for {
+		run, found, err := r.executeNext(ctx, until)
+		if err != nil {
+			return list, err
+		}
+		if !found {
+			return list, nil
+		}
+		list = append(list, run)
+	}
+}
+
+func (r *StandingOrderRepository) executeNext(ctx context.Context, until time.Time) (run service.StandingOrderRun, found bool, err error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return run, false, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	row := tx.QueryRowContext(ctx, `
+		SELECT `+standingOrderColumns+`
+		FROM standing_orders
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+		service.StandingOrderActive, until,
+	)
+	o, err := scanStandingOrder(row)
+	if err == sql.ErrNoRows {
+		return run, false, tx.Commit()
+	}
+	if err != nil {
+		return run, false, fmt.Errorf("cannot retrieve standing order: %v", err)
+	}
+
+	run = service.StandingOrderRun{
+		StandingOrderID: o.ID,
+		ScheduledAt:     *o.NextRunAt,
+		Status:          service.TransferCompleted,
+	}
+
+	t, execErr := r.transfer(ctx, tx, o)
+	switch {
+	case execErr == nil:
+		run.TransferID = &t.ID
+	case errors.Is(execErr, service.ErrWalletNotFound),
+		errors.Is(execErr, service.ErrWalletAccessDenied),
+		errors.Is(execErr, service.ErrInsufficientFunds):
+		reason := execErr.Error()
+		run.Status = service.TransferFailed
+		run.FailureReason = &reason
+	default:
+		return run, false, execErr
+	}
+
+	row = tx.QueryRowContext(ctx, `
+		INSERT INTO standing_order_runs (standing_order_id, transfer_id, scheduled_at, status, failure_reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, date`,
+		run.StandingOrderID, run.TransferID, run.ScheduledAt, run.Status, run.FailureReason,
+	)
+	if err = row.Scan(&run.ID, &run.Date); err != nil {
+		return run, false, fmt.Errorf("cannot insert standing order run: %v", err)
+	}
+	run.Date = run.Date.UTC()
+
+	o.Runs++
+	o.NextRunAt = nil
+	if next, ok := o.Recurrence.Occurrence(o.Runs); ok {
+		o.NextRunAt = &next
+	} else {
+		o.Status = service.StandingOrderFinished
+	}
+	if err = r.advance(ctx, tx, o); err != nil {
+		return run, false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return run, false, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return run, true, nil
+}
+
+// transfer creates the transfer of the standing order execution.
+// Business errors happen before any fund is moved, so the execution
+// can be stored as failed in the same DB transaction.
+func (r *StandingOrderRepository) transfer(ctx context.Context, tx *sql.Tx, o service.StandingOrder) (t service.Transfer, err error) {
+	return r.TransferRepo.transfer(ctx, tx, service.TransferRequest{
+		Issuer:              service.User{ID: o.IssuerID},
+		OriginWalletID:      o.OriginWalletID,
+		DestinationWalletID: o.DestinationWalletID,
+		Amount:              o.Amount,
+		Message:             o.Message,
+	})
+}
+
+// advance stores the runs, the next execution and the status
+// of the standing order locked by the execution.
+func (r *StandingOrderRepository) advance(ctx context.Context, tx *sql.Tx, o service.StandingOrder) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE standing_orders
+		SET status = $1, runs = $2, next_run_at = $3
+		WHERE id = $4`,
+		o.Status, o.Runs, o.NextRunAt, o.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot update standing order: %v", err)
+	}
+
+	return nil
+}
+
+func scanStandingOrder(row rowScanner) (o service.StandingOrder, err error) {
+	err = row.Scan(
+		&o.ID,
+		&o.IssuerID,
+		&o.OriginWalletID,
+		&o.DestinationWalletID,
+		&o.Amount,
+		&o.Message,
+		&o.Recurrence.Frequency,
+		&o.Recurrence.DayOfMonth,
+		&o.Recurrence.StartDate,
+		&o.Recurrence.EndDate,
+		&o.Recurrence.Count,
+		&o.Status,
+		&o.Runs,
+		&o.NextRunAt,
+		&o.CreatedAt,
+	)
+	if err != nil {
+		return o, err
+	}
+
+	o.Recurrence.StartDate = o.Recurrence.StartDate.UTC()
+	o.Recurrence.EndDate = utcOrNil(o.Recurrence.EndDate)
+	o.NextRunAt = utcOrNil(o.NextRunAt)
+	o.CreatedAt = o.CreatedAt.UTC()
+
+	return o, nil
+}
+
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	x := t.UTC()
+	return &x
+}
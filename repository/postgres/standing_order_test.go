@@ -0,0 +1,311 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestStandingOrderCRUD(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r     = StandingOrderRepository{DB: db}
+		start = rfc3339MustParse(t, "2099-01-31T10:00:00Z")
+		count = 12
+		order = service.StandingOrder{
+			IssuerID:            userA.ID,
+			OriginWalletID:      walletA,
+			DestinationWalletID: walletB,
+			Amount:              5,
+			Message:             ptrToStr("rent"),
+			Recurrence: service.Recurrence{
+				Frequency:  service.Monthly,
+				DayOfMonth: 31,
+				StartDate:  start,
+				Count:      &count,
+			},
+			Status:    service.StandingOrderActive,
+			NextRunAt: &start,
+		}
+	)
+
+	created, err := r.CreateStandingOrder(ctx, order)
+	if err != nil {
+		t.Fatalf("cannot create standing order: %v", err)
+	}
+	order.ID = created.ID
+	order.CreatedAt = created.CreatedAt
+
+	got, err := r.GetStandingOrder(ctx, walletA, order.ID)
+	if err != nil {
+		t.Fatalf("cannot get standing order: %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Fatalf("unexpected standing order: \n got:  %+v \n want: %+v", got, order)
+	}
+
+	if _, err = r.GetStandingOrder(ctx, walletB, order.ID); !errors.Is(err, service.ErrStandingOrderNotFound) {
+		t.Fatalf("unexpected error getting standing order from another wallet: %v", err)
+	}
+
+	amount := 7.5
+	order.Amount = amount
+	got, err = r.UpdateStandingOrder(ctx, order.ID, service.StandingOrderUpdate{Amount: &amount})
+	if err != nil {
+		t.Fatalf("cannot update standing order: %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Fatalf("unexpected updated standing order: \n got:  %+v \n want: %+v", got, order)
+	}
+
+	order.Status = service.StandingOrderCancelled
+	order.NextRunAt = nil
+	if got, err = r.CancelStandingOrder(ctx, order.ID); err != nil {
+		t.Fatalf("cannot cancel standing order: %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Fatalf("unexpected cancelled standing order: \n got:  %+v \n want: %+v", got, order)
+	}
+
+	list, err := r.ListStandingOrders(ctx, walletA)
+	if err != nil {
+		t.Fatalf("cannot list standing orders: %v", err)
+	}
+	if want := []service.StandingOrder{order}; !reflect.DeepEqual(list, want) {
+		t.Fatalf("unexpected standing order list: \n got:  %+v \n want: %+v", list, want)
+	}
+
+	if _, err = r.UpdateStandingOrder(ctx, order.ID, service.StandingOrderUpdate{Amount: &amount}); !errors.Is(err, service.ErrStandingOrderNotActive) {
+		t.Fatalf("unexpected error updating cancelled standing order: %v", err)
+	}
+	if _, err = r.CancelStandingOrder(ctx, order.ID); !errors.Is(err, service.ErrStandingOrderNotActive) {
+		t.Fatalf("unexpected error cancelling cancelled standing order: %v", err)
+	}
+	if _, err = r.UpdateStandingOrder(ctx, uuid.New(), service.StandingOrderUpdate{Amount: &amount}); !errors.Is(err, service.ErrStandingOrderNotActive) {
+		t.Fatalf("unexpected error updating missing standing order: %v", err)
+	}
+}
+
+func TestStandingOrderRecurrenceZone(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r     = StandingOrderRepository{DB: db}
+		start = rfc3339MustParse(t, "2099-11-01T00:30:00+02:00")
+		// the service stores the recurrence in UTC.
+		recurrence = service.Recurrence{Frequency: service.Monthly, DayOfMonth: 1, StartDate: start}.UTC()
+	)
+	next, _ := recurrence.Occurrence(0)
+
+	o, err := r.CreateStandingOrder(ctx, service.StandingOrder{
+		IssuerID:            userA.ID,
+		OriginWalletID:      walletA,
+		DestinationWalletID: walletB,
+		Amount:              1,
+		Recurrence:          recurrence,
+		Status:              service.StandingOrderActive,
+		NextRunAt:           &next,
+	})
+	if err != nil {
+		t.Fatalf("cannot create standing order: %v", err)
+	}
+
+	got, err := r.GetStandingOrder(ctx, walletA, o.ID)
+	if err != nil {
+		t.Fatalf("cannot get standing order: %v", err)
+	}
+	if !reflect.DeepEqual(got.Recurrence, recurrence) {
+		t.Fatalf("unexpected recurrence: \n got:  %+v \n want: %+v", got.Recurrence, recurrence)
+	}
+	for n := 0; n < 3; n++ {
+		want, _ := recurrence.Occurrence(n)
+		if x, _ := got.Recurrence.Occurrence(n); !x.Equal(want) {
+			t.Fatalf("unexpected occurrence %d: got: %v, want: %v", n, x, want)
+		}
+	}
+	if !got.NextRunAt.Equal(next) {
+		t.Fatalf("unexpected next run: got: %v, want: %v", got.NextRunAt, next)
+	}
+}
+
+func TestUpdateExecutedStandingOrder(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r = StandingOrderRepository{
+			DB: db,
+			TransferRepo: &TransferRepository{
+				DB:              db,
+				WalletRepo:      &WalletRepository{DB: db},
+				TransactionRepo: &TransactionRepository{DB: db},
+			},
+		}
+		start  = rfc3339MustParse(t, "2099-01-31T10:00:00Z")
+		amount = 2.0
+	)
+
+	o, err := r.CreateStandingOrder(ctx, service.StandingOrder{
+		IssuerID:            userA.ID,
+		OriginWalletID:      walletA,
+		DestinationWalletID: walletB,
+		Amount:              1,
+		Recurrence:          service.Recurrence{Frequency: service.Monthly, StartDate: start},
+		Status:              service.StandingOrderActive,
+		NextRunAt:           &start,
+	})
+	if err != nil {
+		t.Fatalf("cannot create standing order: %v", err)
+	}
+
+	// the order read before the execution must not roll back its runs.
+	if _, err = r.ExecuteStandingOrders(ctx, start); err != nil {
+		t.Fatalf("cannot execute standing orders: %v", err)
+	}
+	got, err := r.UpdateStandingOrder(ctx, o.ID, service.StandingOrderUpdate{Amount: &amount})
+	if err != nil {
+		t.Fatalf("cannot update standing order: %v", err)
+	}
+
+	next := rfc3339MustParse(t, "2099-02-28T10:00:00Z")
+	if got.Amount != amount || got.Runs != 1 || !reflect.DeepEqual(got.NextRunAt, &next) {
+		t.Fatalf("unexpected standing order: amount %v, runs %v, next run %v", got.Amount, got.Runs, got.NextRunAt)
+	}
+}
+
+func TestExecuteStandingOrders(t *testing.T) {
+	db := setupTestDB(t)
+
+	var (
+		start = rfc3339MustParse(t, "2099-01-31T10:00:00Z")
+		two   = 2
+	)
+	tt := []struct {
+		name              string
+		amount            float64
+		until             time.Time
+		wantRuns          []service.TransferStatus
+		wantStatus        service.StandingOrderStatus
+		wantNextRunAt     *time.Time
+		wantOriginBalance float64
+	}{
+		{
+			name:              "not due",
+			amount:            5,
+			until:             start.Add(-time.Second),
+			wantStatus:        service.StandingOrderActive,
+			wantNextRunAt:     &start,
+			wantOriginBalance: 12.75,
+		},
+		{
+			name:              "one execution",
+			amount:            5,
+			until:             start,
+			wantRuns:          []service.TransferStatus{service.TransferCompleted},
+			wantStatus:        service.StandingOrderActive,
+			wantNextRunAt:     ptrToTime(rfc3339MustParse(t, "2099-02-28T10:00:00Z")),
+			wantOriginBalance: 7.75,
+		},
+		{
+			name:              "missed executions until finished",
+			amount:            5,
+			until:             rfc3339MustParse(t, "2099-06-01T00:00:00Z"),
+			wantRuns:          []service.TransferStatus{service.TransferCompleted, service.TransferCompleted},
+			wantStatus:        service.StandingOrderFinished,
+			wantOriginBalance: 2.75,
+		},
+		{
+			name:              "insufficient funds",
+			amount:            10,
+			until:             rfc3339MustParse(t, "2099-06-01T00:00:00Z"),
+			wantRuns:          []service.TransferStatus{service.TransferCompleted, service.TransferFailed},
+			wantStatus:        service.StandingOrderFinished,
+			wantOriginBalance: 2.75,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			setupFixtures(ctx, t, db)
+
+			r := StandingOrderRepository{
+				DB: db,
+				TransferRepo: &TransferRepository{
+					DB:              db,
+					WalletRepo:      &WalletRepository{DB: db},
+					TransactionRepo: &TransactionRepository{DB: db},
+				},
+			}
+
+			o, err := r.CreateStandingOrder(ctx, service.StandingOrder{
+				IssuerID:            userA.ID,
+				OriginWalletID:      walletA,
+				DestinationWalletID: walletB,
+				Amount:              tc.amount,
+				Recurrence: service.Recurrence{
+					Frequency: service.Monthly,
+					StartDate: start,
+					Count:     &two,
+				},
+				Status:    service.StandingOrderActive,
+				NextRunAt: &start,
+			})
+			if err != nil {
+				t.Fatalf("cannot create standing order: %v", err)
+			}
+
+			list, err := r.ExecuteStandingOrders(ctx, tc.until)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []service.TransferStatus
+			for _, x := range list {
+				got = append(got, x.Status)
+			}
+			if !reflect.DeepEqual(got, tc.wantRuns) {
+				t.Fatalf("unexpected runs: got: %v, want %v", got, tc.wantRuns)
+			}
+
+			runs, err := r.ListStandingOrderRuns(ctx, o.ID)
+			if err != nil {
+				t.Fatalf("cannot list standing order runs: %v", err)
+			}
+			if len(runs) != len(tc.wantRuns) {
+				t.Fatalf("unexpected run history: %+v", runs)
+			}
+
+			o, err = r.GetStandingOrder(ctx, walletA, o.ID)
+			if err != nil {
+				t.Fatalf("cannot get standing order: %v", err)
+			}
+			if o.Status != tc.wantStatus || !reflect.DeepEqual(o.NextRunAt, tc.wantNextRunAt) {
+				t.Fatalf("unexpected standing order: status %v, next run %v", o.Status, o.NextRunAt)
+			}
+
+			w, err := r.TransferRepo.WalletRepo.GetByID(ctx, walletA)
+			if err != nil {
+				t.Fatalf("cannot load origin wallet: %v", err)
+			}
+			if got := w.Balance; got != tc.wantOriginBalance {
+				t.Fatalf("unexpected origin balance: got: %v, want %v", got, tc.wantOriginBalance)
+			}
+		})
+	}
+}
@@ -3,50 +3,371 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
 	"github.com/hmoragrega/paybile/service"
 )
 
+var errUnbalancedEntry = errors.New("journal entry does not balance")
+
 type TransactionRepository struct {
 	DB *sql.DB
 }
 
-func (r *TransactionRepository) insert(
+// journalEntry a movement of funds in the ledger, the
+// amounts of all its postings must balance to zero.
+type journalEntry struct {
+	entryType   service.TransactionType
+	referenceID *uuid.UUID
+	postings    []posting
+}
+
+// posting the part of a journal entry that affects either a wallet or a system account.
+type posting struct {
+	walletID *uuid.UUID
+	account  *service.SystemAccount
+	amount   float64
+	// balance of the wallet after the posting, empty for system accounts.
+	balance *float64
+}
+
+func walletPosting(walletID uuid.UUID, amount, balance float64) posting {
+	return posting{walletID: &walletID, amount: amount, balance: &balance}
+}
+
+func systemPosting(account service.SystemAccount, amount float64) posting {
+	return posting{account: &account, amount: amount}
+}
+
+// Deposit adds funds to the wallet from the cash-in account.
+func (r *TransactionRepository) Deposit(ctx context.Context, walletID uuid.UUID, amount float64) (t service.Transaction, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.Deposit")
+
+	if amount <= 0 {
+		return t, service.ErrInvalidTransactionAmount
+	}
+
+	return r.moveSystemFunds(ctx, walletID, amount, service.CashInAccount, service.DepositType, nil)
+}
+
+// ChargeFee moves funds from the wallet to the fees account,
+// the reference is the operation that originated the fee.
+func (r *TransactionRepository) ChargeFee(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount float64,
+	referenceID *uuid.UUID,
+) (t service.Transaction, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.ChargeFee")
+
+	if amount <= 0 {
+		return t, service.ErrInvalidTransactionAmount
+	}
+
+	return r.moveSystemFunds(ctx, walletID, -amount, service.FeesAccount, service.FeeType, referenceID)
+}
+
+// moveSystemFunds moves funds between a wallet and a system account.
+func (r *TransactionRepository) moveSystemFunds(
 	ctx context.Context,
-	db queryHandler,
 	walletID uuid.UUID,
 	amount float64,
-	balance float64,
-	transactionType service.TransactionType,
+	account service.SystemAccount,
+	entryType service.TransactionType,
 	referenceID *uuid.UUID,
 ) (t service.Transaction, err error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	var balance float64
+	row := tx.QueryRowContext(ctx, `SELECT balance FROM wallets WHERE id = $1 FOR UPDATE`, walletID)
+	err = row.Scan(&balance)
+	if err == sql.ErrNoRows {
+		return t, service.ErrWalletNotFound
+	}
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve wallet"), err)
+	}
+	if balance += amount; balance < 0 {
+		return t, fmt.Errorf("%w: balance %.2f", service.ErrInsufficientFunds, balance-amount)
+	}
+
+	list, err := r.post(ctx, tx, journalEntry{
+		entryType:   entryType,
+		referenceID: referenceID,
+		postings: []posting{
+			walletPosting(walletID, amount, balance),
+			systemPosting(account, -amount),
+		},
+	})
+	if err != nil {
+		return t, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE wallets SET balance = $1 WHERE id = $2`, balance, walletID)
+	if err != nil {
+		return t, fmt.Errorf("cannot update wallet balance: %v", err)
+	}
+
+	if entryType == service.DepositType {
+		event := depositEvent{WalletID: walletID, Transaction: list[0]}
+		if err = recordEvent(ctx, tx, walletID, service.DepositCreatedEvent, event); err != nil {
+			return t, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return t, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return list[0], nil
+}
+
+// post inserts the journal entry with its postings.
+// Returns the wallet transactions of the entry.
+func (r *TransactionRepository) post(ctx context.Context, db queryHandler, e journalEntry) (list []service.Transaction, err error) {
+	var sum float64
+	for _, p := range e.postings {
+		sum += p.amount
+	}
+	if roundAmount(sum) != 0 {
+		return list, fmt.Errorf("%w: postings sum %.4f", errUnbalancedEntry, sum)
+	}
+
+	var (
+		entryID uuid.UUID
+		t       service.Transaction
+	)
 	row := db.QueryRowContext(ctx, `
-		INSERT INTO transactions(wallet_id, amount, balance, transaction_type, reference_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO journal_entries(entry_type, reference_id)
+		VALUES ($1, $2)
 		RETURNING id, date`,
-		walletID, amount, balance, transactionType, referenceID,
+		e.entryType, e.referenceID,
+	)
+	if err = row.Scan(&entryID, &t.Date); err != nil {
+		return list, fmt.Errorf("cannot insert journal entry: %v", err)
+	}
+	t.Date = t.Date.UTC()
+
+	for _, p := range e.postings {
+		row := db.QueryRowContext(ctx, `
+			INSERT INTO postings(journal_entry_id, wallet_id, system_account, amount, balance)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`,
+			entryID, p.walletID, p.account, p.amount, p.balance,
+		)
+		var id uuid.UUID
+		if err = row.Scan(&id); err != nil {
+			return list, fmt.Errorf("cannot insert posting: %v", err)
+		}
+		if p.walletID == nil {
+			continue
+		}
+
+		t.ID = id
+		t.Amount = p.amount
+		t.Balance = *p.balance
+		t.Type = e.entryType
+		t.ReferenceID = e.referenceID
+		list = append(list, t)
+	}
+
+	return list, nil
+}
+
+// BalanceAt returns the running balance of the last wallet transaction at or before the given time.
+func (r *TransactionRepository) BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (balance float64, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.BalanceAt")
+
+	row := r.DB.QueryRowContext(ctx, `
+		SELECT p.balance
+		FROM postings p
+		JOIN journal_entries j ON j.id = p.journal_entry_id
+		WHERE p.wallet_id = $1 AND j.date <= $2
+		ORDER BY p.sequence DESC
+		LIMIT 1`,
+		walletID, at,
+	)
+	err = row.Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return balance, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve balance"), err)
+	}
+
+	return balance, nil
+}
+
+// ListStatementLines returns the wallet transactions between the dates. The
+// counterparty of a transfer transaction is the other wallet of the transfer.
+func (r *TransactionRepository) ListStatementLines(
+	ctx context.Context,
+	walletID uuid.UUID,
+	from, to time.Time,
+) (lines []service.StatementLine, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.ListStatementLines")
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.amount, j.date, p.balance, j.entry_type, j.reference_id,
+			CASE WHEN t.origin_wallet_id = p.wallet_id THEN t.destination_wallet_id ELSE t.origin_wallet_id END,
+			t.message
+		FROM postings p
+		JOIN journal_entries j ON j.id = p.journal_entry_id
+		LEFT JOIN transfers t ON t.id = j.reference_id AND j.entry_type = 'transfer'
+		WHERE p.wallet_id = $1 AND j.date >= $2 AND j.date < $3
+		ORDER BY p.sequence`,
+		walletID, from, to,
 	)
+	if err != nil {
+		return lines, fmt.Errorf("cannot query statement transactions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l service.StatementLine
+		err := rows.Scan(
+			&l.ID,
+			&l.Amount,
+			&l.Date,
+			&l.Balance,
+			&l.Type,
+			&l.ReferenceID,
+			&l.CounterpartyWalletID,
+			&l.Message,
+		)
+		if err != nil {
+			return lines, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan statement transaction"), err)
+		}
+		l.Date = l.Date.UTC()
+		lines = append(lines, l)
+	}
+	if err = rows.Err(); err != nil {
+		return lines, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate statement transactions"), err)
+	}
+
+	return lines, nil
+}
+
+// StreamTransactions reads the wallet transactions from the cursor
+// one by one, so the history never needs to fit in memory.
+func (r *TransactionRepository) StreamTransactions(
+	ctx context.Context,
+	walletID uuid.UUID,
+	fn func(service.Transaction) error,
+) error {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.StreamTransactions")
 
-	err = row.Scan(&t.ID, &t.Date)
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.amount, j.date, p.balance, j.entry_type, j.reference_id
+		FROM postings p
+		JOIN journal_entries j ON j.id = p.journal_entry_id
+		WHERE p.wallet_id = $1
+		ORDER BY p.sequence`,
+		walletID,
+	)
 	if err != nil {
-		return t, fmt.Errorf("cannot insert transaction: %v", err)
+		return fmt.Errorf("cannot query transactions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tx service.Transaction
+		if err := rows.Scan(&tx.ID, &tx.Amount, &tx.Date, &tx.Balance, &tx.Type, &tx.ReferenceID); err != nil {
+			return fmt.Errorf("%w: %v", fmt.Errorf("cannot scan transaction"), err)
+		}
+		tx.Date = tx.Date.UTC()
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate transactions"), err)
 	}
 
-	t.Amount = amount
-	t.Balance = balance
-	t.Type = transactionType
-	t.ReferenceID = referenceID
+	return nil
+}
+
+// GetTransaction returns a transaction of the wallet.
+func (r *TransactionRepository) GetTransaction(ctx context.Context, walletID, transactionID uuid.UUID) (t service.Transaction, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.GetTransaction")
+
+	row := r.DB.QueryRowContext(ctx, `
+		SELECT p.id, p.amount, j.date, p.balance, j.entry_type, j.reference_id
+		FROM postings p
+		JOIN journal_entries j ON j.id = p.journal_entry_id
+		WHERE p.id = $1 AND p.wallet_id = $2`,
+		transactionID, walletID,
+	)
+	err = row.Scan(&t.ID, &t.Amount, &t.Date, &t.Balance, &t.Type, &t.ReferenceID)
+	if err == sql.ErrNoRows {
+		return t, service.ErrTransactionNotFound
+	}
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve transaction"), err)
+	}
+	t.Date = t.Date.UTC()
 
 	return t, nil
 }
 
+// ListTransactionsAfter returns the wallet transactions posted after the given one.
+func (r *TransactionRepository) ListTransactionsAfter(
+	ctx context.Context,
+	walletID uuid.UUID,
+	transactionID uuid.UUID,
+) (list []service.Transaction, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.ListTransactionsAfter")
+
+	if _, err = r.GetTransaction(ctx, walletID, transactionID); err != nil {
+		return list, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.amount, j.date, p.balance, j.entry_type, j.reference_id
+		FROM postings p
+		JOIN journal_entries j ON j.id = p.journal_entry_id
+		WHERE p.wallet_id = $1 AND p.sequence > (SELECT sequence FROM postings WHERE id = $2)
+		ORDER BY p.sequence`,
+		walletID, transactionID,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query transactions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tx service.Transaction
+		if err := rows.Scan(&tx.ID, &tx.Amount, &tx.Date, &tx.Balance, &tx.Type, &tx.ReferenceID); err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan transaction"), err)
+		}
+		tx.Date = tx.Date.UTC()
+		list = append(list, tx)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate transactions"), err)
+	}
+
+	return list, nil
+}
+
 func (r *TransactionRepository) ListTransactions(
 	ctx context.Context,
 	walletID uuid.UUID,
 	opt service.ListOptions,
 ) (res service.TransactionList, err error) {
+	ctx = metrics.WithQuery(ctx, "TransactionRepository.ListTransactions")
 
 	order := "ASC"
 	if opt.Order.Descending() {
@@ -56,23 +377,36 @@ func (r *TransactionRepository) ListTransactions(
 	// Request one more elements to get the next ID if available.
 	params := []interface{}{walletID, opt.PerPage + 1}
 
+	// The cursor is the ID of the first transaction of the page,
+	// the pages follow the order of the postings in the ledger.
 	var where string
 	if opt.FromID != nil {
 		if opt.Order.Ascending() {
-			where = ` AND t.ID >= $3 `
+			where = ` AND p.sequence >= (SELECT sequence FROM postings WHERE id = $3) `
 		} else {
-			where = ` AND t.ID <= $3 `
+			where = ` AND p.sequence <= (SELECT sequence FROM postings WHERE id = $3) `
 		}
 		params = append(params, *opt.FromID)
 	}
 
+	var columns, join string
+	if opt.ExpandTransfer {
+		columns = `,
+			CASE WHEN t.origin_wallet_id = p.wallet_id THEN t.destination_wallet_id ELSE t.origin_wallet_id END,
+			t.issuer_id, t.message`
+		join = `LEFT JOIN transfers t ON t.id = j.reference_id AND j.entry_type = 'transfer'`
+	}
+
+	// The wallet transactions are the wallet postings of the ledger.
 	query := fmt.Sprintf(`
-		SELECT t.id, t.amount, t.date, t.balance, t.transaction_type, t.reference_id
-		FROM transactions t
-		WHERE t.wallet_id = $1 %s
-		ORDER BY t.date %s, t.id %s
+		SELECT p.id, p.amount, j.date, p.balance, j.entry_type, j.reference_id %s
+		FROM postings p
+		JOIN journal_entries j ON j.id = p.journal_entry_id
+		%s
+		WHERE p.wallet_id = $1 %s
+		ORDER BY p.sequence %s
 		LIMIT $2`,
-		where, order, order,
+		columns, join, where, order,
 	)
 
 	rows, err := r.DB.QueryContext(ctx, query, params...)
@@ -81,11 +415,26 @@ func (r *TransactionRepository) ListTransactions(
 	}
 
 	for rows.Next() {
-		var tx service.Transaction
-		if err := rows.Scan(&tx.ID, &tx.Amount, &tx.Date, &tx.Balance, &tx.Type, &tx.ReferenceID); err != nil {
+		var (
+			tx                             service.Transaction
+			counterpartyWalletID, issuerID *uuid.UUID
+			message                        *string
+		)
+		dest := []interface{}{&tx.ID, &tx.Amount, &tx.Date, &tx.Balance, &tx.Type, &tx.ReferenceID}
+		if opt.ExpandTransfer {
+			dest = append(dest, &counterpartyWalletID, &issuerID, &message)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return res, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan transaction"), err)
 		}
 		tx.Date = tx.Date.UTC()
+		if issuerID != nil {
+			tx.Transfer = &service.TransactionTransfer{
+				CounterpartyWalletID: *counterpartyWalletID,
+				IssuerID:             *issuerID,
+				Message:              message,
+			}
+		}
 		res.Results = append(res.Results, tx)
 	}
 	if err = rows.Err(); err != nil {
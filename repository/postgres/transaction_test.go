@@ -91,6 +91,35 @@ func TestListTransactions(t *testing.T) {
 				NextID: ptrToUUID(uuid.MustParse("72db21de-9a63-40c6-b666-d35cf8437fd5")),
 			},
 		},
+		{
+			name:     "expand transfer",
+			walletID: walletB,
+			options: service.ListOptions{
+				PerPage:        2,
+				ExpandTransfer: true,
+			},
+			wantList: service.TransactionList{
+				Results: []service.Transaction{{
+					ID:      uuid.MustParse("7b63d966-a700-4d4f-b12c-e490bc96fd8c"),
+					Amount:  45,
+					Balance: 45,
+					Type:    service.DepositType,
+					Date:    rfc3339MustParse(t, "2020-09-20T10:00:01Z"),
+				}, {
+					ID:          uuid.MustParse("60978032-b118-4727-a123-4468dced4104"),
+					Amount:      7.25,
+					Balance:     52.25,
+					Type:        service.TransferType,
+					ReferenceID: &completedTransferID,
+					Date:        rfc3339MustParse(t, "2020-09-20T11:10:00Z"),
+					Transfer: &service.TransactionTransfer{
+						CounterpartyWalletID: walletA,
+						IssuerID:             userA.ID,
+						Message:              ptrToStr("dinner"),
+					},
+				}},
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -111,3 +140,290 @@ func TestListTransactions(t *testing.T) {
 		})
 	}
 }
+
+func TestDepositAndChargeFee(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r           = TransactionRepository{DB: db}
+		walletRepo  = WalletRepository{DB: db}
+		referenceID = uuid.New()
+	)
+
+	if _, err := r.Deposit(ctx, walletC, 0); !errors.Is(err, service.ErrInvalidTransactionAmount) {
+		t.Fatalf("unexpected error depositing zero: %v", err)
+	}
+	if _, err := r.Deposit(ctx, uuid.New(), 10); !errors.Is(err, service.ErrWalletNotFound) {
+		t.Fatalf("unexpected error depositing in missing wallet: %v", err)
+	}
+
+	deposit, err := r.Deposit(ctx, walletC, 10)
+	if err != nil {
+		t.Fatalf("cannot deposit: %v", err)
+	}
+	if deposit.Amount != 10 || deposit.Balance != 10 || deposit.Type != service.DepositType {
+		t.Fatalf("unexpected deposit transaction: %+v", deposit)
+	}
+
+	if _, err = r.ChargeFee(ctx, walletC, 20, &referenceID); !errors.Is(err, service.ErrInsufficientFunds) {
+		t.Fatalf("unexpected error charging a fee over the balance: %v", err)
+	}
+
+	fee, err := r.ChargeFee(ctx, walletC, 1.5, &referenceID)
+	if err != nil {
+		t.Fatalf("cannot charge fee: %v", err)
+	}
+	want := service.Transaction{
+		ID:          fee.ID,
+		Amount:      -1.5,
+		Balance:     8.5,
+		Type:        service.FeeType,
+		ReferenceID: &referenceID,
+		Date:        fee.Date,
+	}
+	if !reflect.DeepEqual(fee, want) {
+		t.Fatalf("unexpected fee transaction: \n got:  %+v \n want: %+v", fee, want)
+	}
+
+	wallet, err := walletRepo.GetByID(ctx, walletC)
+	if err != nil {
+		t.Fatalf("cannot get wallet: %v", err)
+	}
+	if wallet.Balance != 8.5 {
+		t.Fatalf("unexpected wallet balance: got %v, want 8.5", wallet.Balance)
+	}
+
+	// the whole ledger, wallets and system accounts, must balance to zero.
+	var sum float64
+	if err := db.QueryRowContext(ctx, `SELECT SUM(amount) FROM postings`).Scan(&sum); err != nil {
+		t.Fatalf("cannot sum postings: %v", err)
+	}
+	if sum != 0 {
+		t.Fatalf("unexpected ledger sum: got %v, want 0", sum)
+	}
+
+	var fees float64
+	row := db.QueryRowContext(ctx, `SELECT SUM(amount) FROM postings WHERE system_account = $1`, service.FeesAccount)
+	if err := row.Scan(&fees); err != nil {
+		t.Fatalf("cannot sum fees account: %v", err)
+	}
+	if fees != 1.5 {
+		t.Fatalf("unexpected fees account balance: got %v, want 1.5", fees)
+	}
+}
+
+func TestBalanceAt(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	r := TransactionRepository{DB: db}
+
+	tt := []struct {
+		name        string
+		walletID    uuid.UUID
+		at          string
+		wantBalance float64
+	}{
+		{
+			name:        "before any transaction",
+			walletID:    walletA,
+			at:          "2020-09-20T09:59:59Z",
+			wantBalance: 0,
+		},
+		{
+			name:        "at the deposit",
+			walletID:    walletA,
+			at:          "2020-09-20T10:00:00Z",
+			wantBalance: 20,
+		},
+		{
+			name:        "before the transfer",
+			walletID:    walletA,
+			at:          "2020-09-20T11:09:59Z",
+			wantBalance: 20,
+		},
+		{
+			name:        "after the transfer",
+			walletID:    walletA,
+			at:          "2020-09-21T00:00:00Z",
+			wantBalance: 12.75,
+		},
+		{
+			name:        "transactions on the same date",
+			walletID:    walletD,
+			at:          "2020-09-20T10:00:02Z",
+			wantBalance: 30.50,
+		},
+		{
+			name:        "no transactions",
+			walletID:    walletC,
+			at:          "2020-09-21T00:00:00Z",
+			wantBalance: 0,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.BalanceAt(ctx, tc.walletID, rfc3339MustParse(t, tc.at))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.wantBalance {
+				t.Fatalf("unexpected balance: got %v, want %v", got, tc.wantBalance)
+			}
+		})
+	}
+}
+
+func TestListStatementLines(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r    = TransactionRepository{DB: db}
+		from = rfc3339MustParse(t, "2020-09-01T00:00:00Z")
+		to   = rfc3339MustParse(t, "2020-10-01T00:00:00Z")
+	)
+
+	got, err := r.ListStatementLines(ctx, walletA, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []service.StatementLine{{
+		Transaction: service.Transaction{
+			ID:      uuid.MustParse("9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc"),
+			Amount:  20,
+			Balance: 20,
+			Type:    service.DepositType,
+			Date:    rfc3339MustParse(t, "2020-09-20T10:00:00Z"),
+		},
+	}, {
+		Transaction: service.Transaction{
+			ID:          uuid.MustParse("4bce4401-6b35-4fa1-94b9-ac5ce05d29b1"),
+			Amount:      -7.25,
+			Balance:     12.75,
+			Type:        service.TransferType,
+			ReferenceID: &completedTransferID,
+			Date:        rfc3339MustParse(t, "2020-09-20T11:10:00Z"),
+		},
+		CounterpartyWalletID: &walletB,
+		Message:              ptrToStr("dinner"),
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected statement lines: \n got:  %+v \n want: %+v", got, want)
+	}
+
+	got, err = r.ListStatementLines(ctx, walletA, to, to.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("unexpected statement lines in an empty period: %+v", got)
+	}
+}
+
+func TestStreamTransactions(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r        = TransactionRepository{DB: db}
+		got      []uuid.UUID
+		dummyErr = errors.New("dummy")
+	)
+
+	err := r.StreamTransactions(ctx, walletD, func(tx service.Transaction) error {
+		got = append(got, tx.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []uuid.UUID{
+		uuid.MustParse("72db21de-9a63-40c6-b666-d35cf8437fd5"),
+		uuid.MustParse("be3c602a-4410-475b-b39f-016c451726a1"),
+		uuid.MustParse("ccf28188-92c7-4a30-8f60-70345694f893"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected transactions: \n got:  %+v \n want: %+v", got, want)
+	}
+
+	var calls int
+	err = r.StreamTransactions(ctx, walletD, func(tx service.Transaction) error {
+		calls++
+		return dummyErr
+	})
+	if !errors.Is(err, dummyErr) || calls != 1 {
+		t.Fatalf("unexpected stream stop: error %v, calls %d", err, calls)
+	}
+}
+
+func TestListTransactionsAfter(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	r := TransactionRepository{DB: db}
+
+	tt := []struct {
+		name     string
+		walletID uuid.UUID
+		lastID   uuid.UUID
+		want     []uuid.UUID
+		wantErr  error
+	}{
+		{
+			name:     "transactions after the last one",
+			walletID: walletD,
+			lastID:   uuid.MustParse("72db21de-9a63-40c6-b666-d35cf8437fd5"),
+			want: []uuid.UUID{
+				uuid.MustParse("be3c602a-4410-475b-b39f-016c451726a1"),
+				uuid.MustParse("ccf28188-92c7-4a30-8f60-70345694f893"),
+			},
+		},
+		{
+			name:     "same date ordered by ID",
+			walletID: walletD,
+			lastID:   uuid.MustParse("be3c602a-4410-475b-b39f-016c451726a1"),
+			want: []uuid.UUID{
+				uuid.MustParse("ccf28188-92c7-4a30-8f60-70345694f893"),
+			},
+		},
+		{
+			name:     "up to date",
+			walletID: walletD,
+			lastID:   uuid.MustParse("ccf28188-92c7-4a30-8f60-70345694f893"),
+		},
+		{
+			name:     "transaction of another wallet",
+			walletID: walletC,
+			lastID:   uuid.MustParse("72db21de-9a63-40c6-b666-d35cf8437fd5"),
+			wantErr:  service.ErrTransactionNotFound,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := r.ListTransactionsAfter(ctx, tc.walletID, tc.lastID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			var got []uuid.UUID
+			for _, x := range list {
+				got = append(got, x.ID)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected transactions: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+		})
+	}
+}
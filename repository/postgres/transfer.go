@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
 	"github.com/hmoragrega/paybile/service"
 )
 
@@ -15,6 +18,8 @@ var (
 	errTxCommit = errors.New("cannot commit transaction")
 )
 
+const transferColumns = `id, issuer_id, origin_wallet_id, destination_wallet_id, amount, message, date, status, execute_at, failure_reason, batch_id`
+
 type TransferRepository struct {
 	DB              *sql.DB
 	TransactionRepo *TransactionRepository
@@ -28,6 +33,8 @@ type queryHandler interface {
 }
 
 func (r *TransferRepository) CreateTransfer(ctx context.Context, req service.TransferRequest) (t service.Transfer, err error) {
+	ctx = metrics.WithQuery(ctx, "TransferRepository.CreateTransfer")
+
 	if req.Amount <= 0 {
 		return t, service.ErrInvalidTransactionAmount
 	}
@@ -42,59 +49,275 @@ func (r *TransferRepository) CreateTransfer(ctx context.Context, req service.Tra
 		}
 	}()
 
-	wallets, err := r.WalletRepo.findByID(ctx, tx, req.OriginWalletID, req.DestinationWalletID)
-	if err != nil {
+	if t, err = r.transfer(ctx, tx, req); err != nil {
 		return t, err
 	}
-	origin, ok := wallets[req.OriginWalletID]
-	if !ok {
-		return t, fmt.Errorf("%w: origin wallet not found", service.ErrWalletNotFound)
-	}
-	destination, ok := wallets[req.DestinationWalletID]
-	if !ok {
-		return t, fmt.Errorf("%w: destination wallet not found", service.ErrWalletNotFound)
+
+	if err = tx.Commit(); err != nil {
+		return t, fmt.Errorf("%w: %v", errTxCommit, err)
 	}
 
-	if !req.Issuer.CanWrite(origin) {
-		return t, service.ErrWalletAccessDenied
+	return t, nil
+}
+
+// transfer moves the funds of the request within the given DB transaction.
+// Business errors happen before any fund is moved, so the caller
+// can store the failure in the same DB transaction.
+func (r *TransferRepository) transfer(ctx context.Context, tx *sql.Tx, req service.TransferRequest) (t service.Transfer, err error) {
+	origin, destination, err := r.lockTransferWallets(ctx, tx, req)
+	if err != nil {
+		return t, err
 	}
 	if w := origin; w.Balance < req.Amount {
 		return t, fmt.Errorf("%w: balance %.2f", service.ErrInsufficientFunds, w.Balance)
 	}
 
+	t, err = r.insert(ctx, tx, req, transferRow{status: service.TransferCompleted})
+	if err != nil {
+		return t, err
+	}
+
+	if err = r.moveFunds(ctx, tx, t, origin, destination); err != nil {
+		return t, err
+	}
+
+	return t, nil
+}
+
+// ScheduleTransfer stores the transfer to be executed
+// at the requested time without moving the funds.
+func (r *TransferRepository) ScheduleTransfer(ctx context.Context, req service.TransferRequest) (t service.Transfer, err error) {
+	ctx = metrics.WithQuery(ctx, "TransferRepository.ScheduleTransfer")
+
+	if req.Amount <= 0 {
+		return t, service.ErrInvalidTransactionAmount
+	}
+
+	if _, _, err = r.transferWallets(ctx, r.DB, req); err != nil {
+		return t, err
+	}
+
+	return r.insert(ctx, r.DB, req, transferRow{status: service.TransferScheduled})
+}
+
+// CancelTransfer cancels a scheduled transfer of the wallet.
+func (r *TransferRepository) CancelTransfer(
+	ctx context.Context,
+	issuer service.User,
+	walletID uuid.UUID,
+	transferID uuid.UUID,
+) (t service.Transfer, err error) {
+	ctx = metrics.WithQuery(ctx, "TransferRepository.CancelTransfer")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	// The access is checked first, so the issuer cannot find
+	// out if a transfer exists in a wallet it cannot access.
+	origin, err := r.WalletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return t, err
+	}
+	if !issuer.CanWrite(origin) {
+		return t, service.ErrWalletAccessDenied
+	}
+
 	row := tx.QueryRowContext(ctx, `
-		INSERT INTO transfers (issuer_id, origin_wallet_id, destination_wallet_id, amount, message)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, date`,
-		req.Issuer.ID, req.OriginWalletID, req.DestinationWalletID, req.Amount, req.Message,
+		SELECT `+transferColumns+`
+		FROM transfers
+		WHERE id = $1 AND origin_wallet_id = $2
+		FOR UPDATE`,
+		transferID, walletID,
 	)
-	if err = row.Scan(&t.ID, &t.Date); err != nil {
-		return t, fmt.Errorf("cannot insert transfer: %v", err)
+	if t, err = scanTransfer(row); err != nil {
+		if err == sql.ErrNoRows {
+			return t, service.ErrTransferNotFound
+		}
+		return t, fmt.Errorf("cannot retrieve transfer: %v", err)
 	}
 
-	// Insert transactions for origin and destination with opposite amounts.
-	for _, x := range []struct {
-		wallet *service.Wallet
-		amount float64
-	}{{
-		wallet: &origin,
-		amount: req.Amount * -1,
-	}, {
-		wallet: &destination,
-		amount: req.Amount,
-	}} {
-		balance := x.wallet.Balance + x.amount
-		_, err = r.TransactionRepo.insert(ctx, tx, x.wallet.ID, x.amount, balance, service.TransferType, &t.ID)
+	if t.Status != service.TransferScheduled {
+		return t, fmt.Errorf("%w: transfer is %s", service.ErrTransferNotCancellable, t.Status)
+	}
+
+	if err = r.updateStatus(ctx, tx, t.ID, service.TransferCancelled, nil); err != nil {
+		return t, err
+	}
+	if err = tx.Commit(); err != nil {
+		return t, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	t.Status = service.TransferCancelled
+
+	return t, nil
+}
+
+// ExecuteScheduledTransfers executes the due scheduled transfers one by one,
+// each one in its own DB transaction, so several workers can run at the
+// same time without executing the same transfer twice.
+func (r *TransferRepository) ExecuteScheduledTransfers(ctx context.Context, until time.Time) (list []service.Transfer, err error) {
+	ctx = metrics.WithQuery(ctx, "TransferRepository.ExecuteScheduledTransfers")
+
+	for {
+		t, found, err := r.executeNextScheduled(ctx, until)
 		if err != nil {
-			return t, err
+			return list, err
 		}
-		if err = r.WalletRepo.updateBalance(ctx, tx, x.wallet.ID, balance); err != nil {
-			return t, err
+		if !found {
+			return list, nil
 		}
+		list = append(list, t)
 	}
+}
 
+func (r *TransferRepository) executeNextScheduled(ctx context.Context, until time.Time) (t service.Transfer, found bool, err error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return t, false, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	row := tx.QueryRowContext(ctx, `
+		SELECT `+transferColumns+`
+		FROM transfers
+		WHERE status = $1 AND execute_at <= $2
+		ORDER BY execute_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+		service.TransferScheduled, until,
+	)
+	t, err = scanTransfer(row)
+	if err == sql.ErrNoRows {
+		return t, false, tx.Commit()
+	}
+	if err != nil {
+		return t, false, fmt.Errorf("cannot retrieve scheduled transfer: %v", err)
+	}
+
+	req := service.TransferRequest{
+		Issuer:              service.User{ID: t.IssuerID},
+		OriginWalletID:      t.OriginWalletID,
+		DestinationWalletID: t.DestinationWalletID,
+		Amount:              t.Amount,
+		Message:             t.Message,
+	}
+
+	// Business errors happen before any fund is moved, so the
+	// transfer can be flagged as failed in the same DB transaction.
+	origin, destination, execErr := r.lockTransferWallets(ctx, tx, req)
+	if execErr == nil && origin.Balance < req.Amount {
+		execErr = fmt.Errorf("%w: balance %.2f", service.ErrInsufficientFunds, origin.Balance)
+	}
+	switch {
+	case execErr == nil:
+		t.Status = service.TransferCompleted
+		if err = r.moveFunds(ctx, tx, t, origin, destination); err != nil {
+			return t, false, err
+		}
+	case errors.Is(execErr, service.ErrWalletNotFound),
+		errors.Is(execErr, service.ErrWalletAccessDenied),
+		errors.Is(execErr, service.ErrInsufficientFunds):
+		reason := execErr.Error()
+		t.Status = service.TransferFailed
+		t.FailureReason = &reason
+	default:
+		return t, false, execErr
+	}
+
+	if err = r.updateStatus(ctx, tx, t.ID, t.Status, t.FailureReason); err != nil {
+		return t, false, err
+	}
 	if err = tx.Commit(); err != nil {
-		return t, fmt.Errorf("%w: %v", errTxCommit, err)
+		return t, false, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return t, true, nil
+}
+
+// transferWallets loads the origin and destination wallets of
+// the transfer checking the issuer can transfer funds from origin.
+func (r *TransferRepository) transferWallets(
+	ctx context.Context,
+	db queryHandler,
+	req service.TransferRequest,
+) (origin service.Wallet, destination service.Wallet, err error) {
+	wallets, err := r.WalletRepo.findByID(ctx, db, req.OriginWalletID, req.DestinationWalletID)
+	if err != nil {
+		return origin, destination, err
+	}
+
+	return checkTransferWallets(req, wallets)
+}
+
+// lockTransferWallets loads the wallets of the transfer like transferWallets
+// locking them, the balances cannot change until the DB transaction ends.
+func (r *TransferRepository) lockTransferWallets(
+	ctx context.Context,
+	tx *sql.Tx,
+	req service.TransferRequest,
+) (origin service.Wallet, destination service.Wallet, err error) {
+	wallets, err := r.WalletRepo.lockByID(ctx, tx, req.OriginWalletID, req.DestinationWalletID)
+	if err != nil {
+		return origin, destination, err
+	}
+
+	return checkTransferWallets(req, wallets)
+}
+
+func checkTransferWallets(
+	req service.TransferRequest,
+	wallets map[uuid.UUID]service.Wallet,
+) (origin service.Wallet, destination service.Wallet, err error) {
+	origin, ok := wallets[req.OriginWalletID]
+	if !ok {
+		return origin, destination, fmt.Errorf("%w: origin wallet not found", service.ErrWalletNotFound)
+	}
+	destination, ok = wallets[req.DestinationWalletID]
+	if !ok {
+		return origin, destination, fmt.Errorf("%w: destination wallet not found", service.ErrWalletNotFound)
+	}
+	if !req.Issuer.CanWrite(origin) {
+		return origin, destination, service.ErrWalletAccessDenied
+	}
+
+	return origin, destination, nil
+}
+
+// transferRow contains the columns of a transfer that are not part of the request.
+type transferRow struct {
+	status        service.TransferStatus
+	failureReason *string
+	batchID       *uuid.UUID
+	batchIndex    *int
+}
+
+func (r *TransferRepository) insert(
+	ctx context.Context,
+	db queryHandler,
+	req service.TransferRequest,
+	x transferRow,
+) (t service.Transfer, err error) {
+	row := db.QueryRowContext(ctx, `
+		INSERT INTO transfers (issuer_id, origin_wallet_id, destination_wallet_id, amount, message, status, execute_at,
+			failure_reason, batch_id, batch_index)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, date`,
+		req.Issuer.ID, req.OriginWalletID, req.DestinationWalletID, req.Amount, req.Message, x.status, req.ExecuteAt,
+		x.failureReason, x.batchID, x.batchIndex,
+	)
+	if err = row.Scan(&t.ID, &t.Date); err != nil {
+		return t, fmt.Errorf("cannot insert transfer: %v", err)
 	}
 
 	t.IssuerID = req.Issuer.ID
@@ -102,6 +325,101 @@ func (r *TransferRepository) CreateTransfer(ctx context.Context, req service.Tra
 	t.DestinationWalletID = req.DestinationWalletID
 	t.Amount = req.Amount
 	t.Message = req.Message
+	t.Date = t.Date.UTC()
+	t.Status = x.status
+	t.ExecuteAt = req.ExecuteAt
+	t.FailureReason = x.failureReason
+	t.BatchID = x.batchID
+
+	return t, nil
+}
+
+// moveFunds posts the transfer in the ledger with opposite amounts for origin
+// and destination, updates the balance of both wallets and records the event
+// of both wallets.
+func (r *TransferRepository) moveFunds(
+	ctx context.Context,
+	db queryHandler,
+	t service.Transfer,
+	origin service.Wallet,
+	destination service.Wallet,
+) error {
+	var (
+		amount             = t.Amount
+		originBalance      = origin.Balance - amount
+		destinationBalance = destination.Balance + amount
+	)
+	_, err := r.TransactionRepo.post(ctx, db, journalEntry{
+		entryType:   service.TransferType,
+		referenceID: &t.ID,
+		postings: []posting{
+			walletPosting(origin.ID, amount*-1, originBalance),
+			walletPosting(destination.ID, amount, destinationBalance),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err = r.WalletRepo.updateBalance(ctx, db, origin.ID, originBalance); err != nil {
+		return err
+	}
+	if err = r.WalletRepo.updateBalance(ctx, db, destination.ID, destinationBalance); err != nil {
+		return err
+	}
+
+	for _, walletID := range []uuid.UUID{origin.ID, destination.ID} {
+		if err = recordEvent(ctx, db, walletID, service.TransferCreatedEvent, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *TransferRepository) updateStatus(
+	ctx context.Context,
+	db queryHandler,
+	transferID uuid.UUID,
+	status service.TransferStatus,
+	failureReason *string,
+) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE transfers
+		SET status = $1, failure_reason = $2
+		WHERE id = $3`,
+		status, failureReason, transferID,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot update transfer status: %v", err)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransfer(row rowScanner) (t service.Transfer, err error) {
+	err = row.Scan(
+		&t.ID,
+		&t.IssuerID,
+		&t.OriginWalletID,
+		&t.DestinationWalletID,
+		&t.Amount,
+		&t.Message,
+		&t.Date,
+		&t.Status,
+		&t.ExecuteAt,
+		&t.FailureReason,
+		&t.BatchID,
+	)
+	if err != nil {
+		return t, err
+	}
+
+	t.Date = t.Date.UTC()
+	t.ExecuteAt = utcOrNil(t.ExecuteAt)
 
 	return t, nil
 }
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -39,6 +40,9 @@ var (
 	walletB = uuid.MustParse("4e1d841d-e53f-4785-ba4d-99df05f11eee")
 	walletC = uuid.MustParse("f0212317-88db-4dd4-ba0e-39757e1ebcc6")
 	walletD = uuid.MustParse("f889299f-41c4-4e58-96c2-7451c8276842")
+
+	scheduledTransferID = uuid.MustParse("5b1d4e0c-3f3e-4c2a-9a43-2a3f1c9e8d71")
+	completedTransferID = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
 )
 
 func TestCreateTransfer(t *testing.T) {
@@ -134,6 +138,7 @@ func TestCreateTransfer(t *testing.T) {
 				DestinationWalletID: walletB,
 				Amount:              12.75,
 				Message:             ptrToStr("test"),
+				Status:              service.TransferCompleted,
 			},
 			wantOriginBalance:      0.00,
 			wantDestinationBalance: 65.00,
@@ -187,6 +192,345 @@ func TestCreateTransfer(t *testing.T) {
 	}
 }
 
+func TestScheduleTransfer(t *testing.T) {
+	db := setupTestDB(t)
+	executeAt := rfc3339MustParse(t, "2099-02-01T00:00:00Z")
+
+	tt := []struct {
+		name         string
+		req          service.TransferRequest
+		wantTransfer service.Transfer
+		wantErr      error
+	}{
+		{
+			name: "zero amount",
+			req: service.TransferRequest{
+				Issuer:              userA,
+				OriginWalletID:      walletA,
+				DestinationWalletID: walletB,
+				ExecuteAt:           &executeAt,
+			},
+			wantErr: service.ErrInvalidTransactionAmount,
+		},
+		{
+			name: "destination wallet not found",
+			req: service.TransferRequest{
+				Issuer:              userA,
+				OriginWalletID:      walletA,
+				DestinationWalletID: uuid.UUID{},
+				Amount:              5.00,
+				ExecuteAt:           &executeAt,
+			},
+			wantErr: service.ErrWalletNotFound,
+		},
+		{
+			name: "access denied",
+			req: service.TransferRequest{
+				Issuer:              service.User{},
+				OriginWalletID:      walletA,
+				DestinationWalletID: walletB,
+				Amount:              5.00,
+				ExecuteAt:           &executeAt,
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		},
+		{
+			name: "funds are not checked",
+			req: service.TransferRequest{
+				Issuer:              userA,
+				OriginWalletID:      walletA,
+				DestinationWalletID: walletB,
+				Amount:              100.00,
+				Message:             ptrToStr("rent"),
+				ExecuteAt:           &executeAt,
+			},
+			wantTransfer: service.Transfer{
+				IssuerID:            userA.ID,
+				OriginWalletID:      walletA,
+				DestinationWalletID: walletB,
+				Amount:              100.00,
+				Message:             ptrToStr("rent"),
+				Status:              service.TransferScheduled,
+				ExecuteAt:           &executeAt,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			setupFixtures(ctx, t, db)
+
+			r := TransferRepository{
+				DB:              db,
+				WalletRepo:      &WalletRepository{DB: db},
+				TransactionRepo: &TransactionRepository{DB: db},
+			}
+
+			got, err := r.ScheduleTransfer(ctx, tc.req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			tc.wantTransfer.ID = got.ID
+			tc.wantTransfer.Date = got.Date
+			if !reflect.DeepEqual(tc.wantTransfer, got) {
+				t.Fatalf("unexpected result: got: %+v, want %+v", got, tc.wantTransfer)
+			}
+
+			w, err := r.WalletRepo.GetByID(ctx, tc.req.OriginWalletID)
+			if err != nil {
+				t.Fatalf("cannot load origin wallet: %v", err)
+			}
+			if got, want := w.Balance, 12.75; got != want {
+				t.Fatalf("unexpected origin balance: got: %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCancelTransfer(t *testing.T) {
+	db := setupTestDB(t)
+
+	tt := []struct {
+		name       string
+		issuer     service.User
+		walletID   uuid.UUID
+		transferID uuid.UUID
+		wantStatus service.TransferStatus
+		wantErr    error
+	}{
+		{
+			name:       "transfer not found",
+			issuer:     userA,
+			walletID:   walletA,
+			transferID: uuid.UUID{},
+			wantErr:    service.ErrTransferNotFound,
+		},
+		{
+			name:       "transfer from another wallet",
+			issuer:     service.User{ID: uuid.MustParse("f65697a1-dbe7-49b5-93d6-bbfc512a46f6")},
+			walletID:   walletB,
+			transferID: scheduledTransferID,
+			wantErr:    service.ErrTransferNotFound,
+		},
+		{
+			name:       "access denied",
+			issuer:     service.User{},
+			walletID:   walletA,
+			transferID: scheduledTransferID,
+			wantErr:    service.ErrWalletAccessDenied,
+		},
+		{
+			name:       "access denied to a missing transfer",
+			issuer:     service.User{},
+			walletID:   walletA,
+			transferID: uuid.UUID{},
+			wantErr:    service.ErrWalletAccessDenied,
+		},
+		{
+			name:       "transfer already completed",
+			issuer:     userA,
+			walletID:   walletA,
+			transferID: completedTransferID,
+			wantErr:    service.ErrTransferNotCancellable,
+		},
+		{
+			name:       "transfer cancelled",
+			issuer:     userA,
+			walletID:   walletA,
+			transferID: scheduledTransferID,
+			wantStatus: service.TransferCancelled,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			setupFixtures(ctx, t, db)
+
+			r := TransferRepository{
+				DB:              db,
+				WalletRepo:      &WalletRepository{DB: db},
+				TransactionRepo: &TransactionRepository{DB: db},
+			}
+
+			got, err := r.CancelTransfer(ctx, tc.issuer, tc.walletID, tc.transferID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != tc.transferID || got.Status != tc.wantStatus {
+				t.Fatalf("unexpected transfer: got: %+v, want status %v", got, tc.wantStatus)
+			}
+
+			// Cancelled transfers are not executed.
+			list, err := r.ExecuteScheduledTransfers(ctx, rfc3339MustParse(t, "2099-12-31T00:00:00Z"))
+			if err != nil {
+				t.Fatalf("cannot execute scheduled transfers: %v", err)
+			}
+			if len(list) != 0 {
+				t.Fatalf("unexpected executed transfers: %+v", list)
+			}
+		})
+	}
+}
+
+func TestExecuteScheduledTransfers(t *testing.T) {
+	db := setupTestDB(t)
+
+	tt := []struct {
+		name              string
+		until             time.Time
+		setup             func(ctx context.Context, t *testing.T)
+		wantStatus        []service.TransferStatus
+		wantOriginBalance float64
+	}{
+		{
+			name:              "transfer not due",
+			until:             rfc3339MustParse(t, "2098-12-31T23:59:59Z"),
+			wantOriginBalance: 12.75,
+		},
+		{
+			name:              "transfer executed",
+			until:             rfc3339MustParse(t, "2099-01-01T00:00:00Z"),
+			wantStatus:        []service.TransferStatus{service.TransferCompleted},
+			wantOriginBalance: 7.75,
+		},
+		{
+			name:  "transfer failed due insufficient funds",
+			until: rfc3339MustParse(t, "2099-01-01T00:00:00Z"),
+			setup: func(ctx context.Context, t *testing.T) {
+				if _, err := db.ExecContext(ctx, `UPDATE transfers SET amount = 20 WHERE id = $1`, scheduledTransferID); err != nil {
+					t.Fatalf("cannot update scheduled transfer: %v", err)
+				}
+			},
+			wantStatus:        []service.TransferStatus{service.TransferFailed},
+			wantOriginBalance: 12.75,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			setupFixtures(ctx, t, db)
+			if tc.setup != nil {
+				tc.setup(ctx, t)
+			}
+
+			r := TransferRepository{
+				DB:              db,
+				WalletRepo:      &WalletRepository{DB: db},
+				TransactionRepo: &TransactionRepository{DB: db},
+			}
+
+			list, err := r.ExecuteScheduledTransfers(ctx, tc.until)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []service.TransferStatus
+			for _, x := range list {
+				got = append(got, x.Status)
+			}
+			if !reflect.DeepEqual(got, tc.wantStatus) {
+				t.Fatalf("unexpected statuses: got: %v, want %v", got, tc.wantStatus)
+			}
+
+			w, err := r.WalletRepo.GetByID(ctx, walletA)
+			if err != nil {
+				t.Fatalf("cannot load origin wallet: %v", err)
+			}
+			if got := w.Balance; got != tc.wantOriginBalance {
+				t.Fatalf("unexpected origin balance: got: %v, want %v", got, tc.wantOriginBalance)
+			}
+		})
+	}
+}
+
+func TestConcurrentTransfers(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r = TransferRepository{
+			DB:              db,
+			WalletRepo:      &WalletRepository{DB: db},
+			TransactionRepo: &TransactionRepository{DB: db},
+		}
+		userB = service.User{ID: uuid.MustParse("f65697a1-dbe7-49b5-93d6-bbfc512a46f6")}
+	)
+
+	// transfer runs n transfers of one unit at the same time and
+	// returns the number of them that failed due insufficient funds.
+	transfer := func(n int, reqs ...service.TransferRequest) int {
+		var (
+			wg           sync.WaitGroup
+			mu           sync.Mutex
+			insufficient int
+		)
+		for i := 0; i < n; i++ {
+			for _, req := range reqs {
+				wg.Add(1)
+				go func(req service.TransferRequest) {
+					defer wg.Done()
+					_, err := r.CreateTransfer(ctx, req)
+					if errors.Is(err, service.ErrInsufficientFunds) {
+						mu.Lock()
+						insufficient++
+						mu.Unlock()
+						return
+					}
+					if err != nil {
+						t.Errorf("unexpected transfer error: %v", err)
+					}
+				}(req)
+			}
+		}
+		wg.Wait()
+		return insufficient
+	}
+	balance := func(walletID uuid.UUID) float64 {
+		w, err := r.WalletRepo.GetByID(ctx, walletID)
+		if err != nil {
+			t.Fatalf("cannot load wallet: %v", err)
+		}
+		return w.Balance
+	}
+	aToB := service.TransferRequest{Issuer: userA, OriginWalletID: walletA, DestinationWalletID: walletB, Amount: 1}
+	bToA := service.TransferRequest{Issuer: userB, OriginWalletID: walletB, DestinationWalletID: walletA, Amount: 1}
+
+	// both directions at the same time lock the same wallets without deadlocks.
+	if n := transfer(10, aToB, bToA); n != 0 {
+		t.Fatalf("unexpected transfers without funds: %d", n)
+	}
+	if a, b := balance(walletA), balance(walletB); a != 12.75 || b != 52.25 {
+		t.Fatalf("unexpected balances after the transfers in both directions: %v, %v", a, b)
+	}
+
+	// only the transfers covered by the balance are completed.
+	if n := transfer(20, aToB); n != 8 {
+		t.Fatalf("unexpected transfers without funds: got: %d, want: 8", n)
+	}
+	if a, b := balance(walletA), balance(walletB); a != 0.75 || b != 64.25 {
+		t.Fatalf("unexpected balances after spending the origin wallet: %v, %v", a, b)
+	}
+
+	report, err := Reconcile(ctx, db)
+	if err != nil {
+		t.Fatalf("cannot reconcile: %v", err)
+	}
+	if !report.Balanced() {
+		t.Fatalf("unexpected discrepancies: %+v", report.Discrepancies)
+	}
+}
+
 // setupTestDB spins up a new instance of the database,
 // loads the most recent schema and insert the fixtures
 // Returns an open connection to the DB.
@@ -350,3 +694,7 @@ func ptrToUUID(id uuid.UUID) *uuid.UUID {
 func ptrToStr(str string) *string {
 	return &str
 }
+
+func ptrToTime(t time.Time) *time.Time {
+	return &t
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/hmoragrega/paybile/metrics"
 	"github.com/hmoragrega/paybile/service"
 )
 
@@ -13,6 +14,8 @@ type UserRepository struct {
 }
 
 func (r *UserRepository) GetByLogin(ctx context.Context, login string) (u service.User, err error) {
+	ctx = metrics.WithQuery(ctx, "UserRepository.GetByLogin")
+
 	query := `SELECT id, hashed_password FROM users WHERE login = $1`
 	row := r.DB.QueryRowContext(ctx, query, login)
 	if err := row.Scan(&u.ID, &u.HashedPassword); err != nil {
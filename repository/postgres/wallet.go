@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
 	"github.com/hmoragrega/paybile/service"
 	"github.com/lib/pq"
 )
@@ -15,6 +16,8 @@ type WalletRepository struct {
 }
 
 func (r *WalletRepository) GetByID(ctx context.Context, walletID uuid.UUID) (w service.Wallet, err error) {
+	ctx = metrics.WithQuery(ctx, "WalletRepository.GetByID")
+
 	query := `SELECT id, user_id, balance FROM wallets WHERE id = $1`
 	row := r.DB.QueryRowContext(ctx, query, walletID)
 	err = row.Scan(&w.ID, &w.UserID, &w.Balance)
@@ -51,17 +54,37 @@ func (r *WalletRepository) findByID(
 	ctx context.Context,
 	db queryHandler,
 	walletIDs ...uuid.UUID,
+) (res map[uuid.UUID]service.Wallet, err error) {
+	return r.queryWallets(ctx, db, `SELECT id, user_id, balance FROM wallets WHERE id = ANY($1)`, walletIDs)
+}
+
+// lockByID loads the wallets locking them until the end of the DB
+// transaction, so concurrent transfers cannot move funds from the same
+// balance. The rows are locked in the order of the IDs to avoid deadlocks.
+func (r *WalletRepository) lockByID(
+	ctx context.Context,
+	tx *sql.Tx,
+	walletIDs ...uuid.UUID,
+) (res map[uuid.UUID]service.Wallet, err error) {
+	return r.queryWallets(ctx, tx, `SELECT id, user_id, balance FROM wallets WHERE id = ANY($1) ORDER BY id FOR UPDATE`, walletIDs)
+}
+
+func (r *WalletRepository) queryWallets(
+	ctx context.Context,
+	db queryHandler,
+	query string,
+	walletIDs []uuid.UUID,
 ) (res map[uuid.UUID]service.Wallet, err error) {
 	ids := make([]interface{}, len(walletIDs))
 	for i, x := range walletIDs {
 		ids[i] = x
 	}
 
-	query := `SELECT id, user_id, balance FROM wallets WHERE id = ANY($1)`
 	rows, err := db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
 		return res, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve wallets"), err)
 	}
+	defer rows.Close()
 
 	res = make(map[uuid.UUID]service.Wallet)
 	for rows.Next() {
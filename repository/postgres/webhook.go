@@ -0,0 +1,359 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/metrics"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/lib/pq"
+)
+
+const webhookColumns = `id, user_id, url, secret, event_types, created_at`
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.created_at, d.delivered_at`
+
+type WebhookRepository struct {
+	DB *sql.DB
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, s service.WebhookSubscription) (service.WebhookSubscription, error) {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.CreateWebhook")
+
+	eventTypes := make([]string, len(s.EventTypes))
+	for i, x := range s.EventTypes {
+		eventTypes[i] = string(x)
+	}
+
+	row := r.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscriptions (user_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		s.UserID, s.URL, s.Secret, pq.Array(eventTypes),
+	)
+	if err := row.Scan(&s.ID, &s.CreatedAt); err != nil {
+		return s, fmt.Errorf("cannot insert webhook: %v", err)
+	}
+	s.CreatedAt = s.CreatedAt.UTC()
+
+	return s, nil
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, subscriptionID uuid.UUID) (s service.WebhookSubscription, err error) {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.GetWebhook")
+
+	row := r.DB.QueryRowContext(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhook_subscriptions
+		WHERE id = $1`,
+		subscriptionID,
+	)
+	s, err = scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return s, service.ErrWebhookNotFound
+	}
+	if err != nil {
+		return s, fmt.Errorf("%w: %v", fmt.Errorf("cannot retrieve webhook"), err)
+	}
+
+	return s, nil
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, userID uuid.UUID) (list []service.WebhookSubscription, err error) {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.ListWebhooks")
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhook_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at, id`,
+		userID,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanWebhook(rows)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan webhook"), err)
+		}
+		list = append(list, s)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate webhooks"), err)
+	}
+
+	return list, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, subscriptionID uuid.UUID) error {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.DeleteWebhook")
+
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("cannot delete webhook: %v", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cannot get deleted webhooks: %v", err)
+	}
+	if n == 0 {
+		return service.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) ListWebhookDeliveries(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+	limit int,
+) (list []service.WebhookDelivery, err error) {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.ListWebhookDeliveries")
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.created_at DESC, d.id
+		LIMIT $2`,
+		subscriptionID, limit,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan webhook delivery"), err)
+		}
+		d.Log = []service.WebhookDeliveryAttempt{}
+		index[d.ID] = len(list)
+		list = append(list, d)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate webhook deliveries"), err)
+	}
+	if len(list) == 0 {
+		return list, nil
+	}
+
+	ids := make([]uuid.UUID, len(list))
+	for i, d := range list {
+		ids[i] = d.ID
+	}
+	rows, err = r.DB.QueryContext(ctx, `
+		SELECT delivery_id, attempt, status_code, error, duration_ms, date
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY delivery_id, attempt`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query webhook delivery attempts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			deliveryID uuid.UUID
+			a          service.WebhookDeliveryAttempt
+		)
+		if err := rows.Scan(&deliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.DurationMS, &a.Date); err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan webhook delivery attempt"), err)
+		}
+		a.Date = a.Date.UTC()
+		i := index[deliveryID]
+		list[i].Log = append(list[i].Log, a)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate webhook delivery attempts"), err)
+	}
+
+	return list, nil
+}
+
+// ClaimWebhookDeliveries locks the due deliveries skipping the ones locked by
+// other dispatchers, and postpones them for the lease duration before returning.
+func (r *WebhookRepository) ClaimWebhookDeliveries(
+	ctx context.Context,
+	until time.Time,
+	lease time.Duration,
+	limit int,
+) (list []service.PendingWebhook, err error) {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.ClaimWebhookDeliveries")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return list, fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`, s.url, s.secret
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.status = $1 AND d.next_attempt_at <= $2
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $3
+		FOR UPDATE OF d SKIP LOCKED`,
+		service.WebhookDeliveryPending, until, limit,
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot query pending webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var p service.PendingWebhook
+		p.Delivery, err = scanWebhookDelivery(rows, &p.Subscription.URL, &p.Subscription.Secret)
+		if err != nil {
+			return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot scan webhook delivery"), err)
+		}
+		p.Subscription.ID = p.Delivery.SubscriptionID
+		ids = append(ids, p.Delivery.ID)
+		list = append(list, p)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("%w: %v", fmt.Errorf("cannot iterate webhook deliveries"), err)
+	}
+	if len(list) == 0 {
+		return list, tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id = ANY($2)`,
+		until.Add(lease), pq.Array(ids),
+	)
+	if err != nil {
+		return list, fmt.Errorf("cannot lease webhook deliveries: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return list, fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return list, nil
+}
+
+// RecordWebhookAttempt stores the attempt and the updated delivery in the same DB transaction.
+func (r *WebhookRepository) RecordWebhookAttempt(
+	ctx context.Context,
+	d service.WebhookDelivery,
+	a service.WebhookDeliveryAttempt,
+) (err error) {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.RecordWebhookAttempt")
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errTxBegin, err)
+	}
+	defer func() {
+		if err != nil {
+			err = rollback(tx, err)
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, date)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		d.ID, a.Attempt, a.StatusCode, a.Error, a.DurationMS, a.Date,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot insert webhook delivery attempt: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, delivered_at = $4
+		WHERE id = $5`,
+		d.Status, d.Attempts, d.NextAttemptAt, d.DeliveredAt, d.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot update webhook delivery: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", errTxCommit, err)
+	}
+
+	return nil
+}
+
+// EnqueueWebhooks writes a delivery of the event in the outbox for every
+// subscription of the owner of the wallet to the event type. The event
+// is delivered once per subscription even if it is enqueued again.
+func (r *WebhookRepository) EnqueueWebhooks(ctx context.Context, e service.Event) error {
+	ctx = metrics.WithQuery(ctx, "WebhookRepository.EnqueueWebhooks")
+
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, created_at)
+		SELECT s.id, $1::UUID, $2::TEXT, $3::JSONB, $4
+		FROM webhook_subscriptions s
+		JOIN wallets w ON w.user_id = s.user_id
+		WHERE w.id = $5 AND $2 = ANY(s.event_types)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		e.ID, string(e.Type), string(e.Payload), e.CreatedAt, e.WalletID,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot enqueue webhook event: %v", err)
+	}
+
+	return nil
+}
+
+func scanWebhook(row rowScanner) (s service.WebhookSubscription, err error) {
+	var eventTypes []string
+	if err = row.Scan(&s.ID, &s.UserID, &s.URL, &s.Secret, pq.Array(&eventTypes), &s.CreatedAt); err != nil {
+		return s, err
+	}
+
+	s.EventTypes = make([]service.EventType, len(eventTypes))
+	for i, x := range eventTypes {
+		s.EventTypes[i] = service.EventType(x)
+	}
+	s.CreatedAt = s.CreatedAt.UTC()
+
+	return s, nil
+}
+
+func scanWebhookDelivery(row rowScanner, extra ...interface{}) (d service.WebhookDelivery, err error) {
+	var payload []byte
+	dest := append([]interface{}{
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	}, extra...)
+	if err = row.Scan(dest...); err != nil {
+		return d, err
+	}
+
+	d.Payload = payload
+	d.NextAttemptAt = d.NextAttemptAt.UTC()
+	d.CreatedAt = d.CreatedAt.UTC()
+	d.DeliveredAt = utcOrNil(d.DeliveredAt)
+
+	return d, nil
+}
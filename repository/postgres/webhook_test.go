@@ -0,0 +1,146 @@
+//+build integration
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestWebhookOutbox(t *testing.T) {
+	db := setupTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	setupFixtures(ctx, t, db)
+
+	var (
+		r            = WebhookRepository{DB: db}
+		events       = EventRepository{DB: db}
+		transactions = &TransactionRepository{DB: db}
+		transfers    = &TransferRepository{
+			DB:              db,
+			TransactionRepo: transactions,
+			WalletRepo:      &WalletRepository{DB: db},
+		}
+		userB = uuid.MustParse("f65697a1-dbe7-49b5-93d6-bbfc512a46f6")
+	)
+
+	hookA, err := r.CreateWebhook(ctx, service.WebhookSubscription{
+		UserID:     userA.ID,
+		URL:        "https://example.com/a",
+		Secret:     "0123456789abcdef",
+		EventTypes: []service.EventType{service.TransferCreatedEvent, service.DepositCreatedEvent},
+	})
+	if err != nil {
+		t.Fatalf("cannot create webhook: %v", err)
+	}
+	hookB, err := r.CreateWebhook(ctx, service.WebhookSubscription{
+		UserID:     userB,
+		URL:        "https://example.com/b",
+		Secret:     "0123456789abcdef",
+		EventTypes: []service.EventType{service.DepositCreatedEvent},
+	})
+	if err != nil {
+		t.Fatalf("cannot create webhook: %v", err)
+	}
+
+	got, err := r.GetWebhook(ctx, hookA.ID)
+	if err != nil {
+		t.Fatalf("cannot get webhook: %v", err)
+	}
+	if !reflect.DeepEqual(got, hookA) {
+		t.Fatalf("unexpected webhook: \n got:  %+v \n want: %+v", got, hookA)
+	}
+	if _, err = r.GetWebhook(ctx, uuid.New()); !errors.Is(err, service.ErrWebhookNotFound) {
+		t.Fatalf("unexpected error getting missing webhook: %v", err)
+	}
+	list, err := r.ListWebhooks(ctx, userA.ID)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("unexpected webhooks: %+v, %v", list, err)
+	}
+
+	// The transfer has an event for both wallets, but user B is not subscribed to transfers.
+	transfer, err := transfers.CreateTransfer(ctx, service.TransferRequest{
+		Issuer:              userA,
+		OriginWalletID:      walletA,
+		DestinationWalletID: walletB,
+		Amount:              1,
+	})
+	if err != nil {
+		t.Fatalf("cannot create transfer: %v", err)
+	}
+	if _, err = transactions.Deposit(ctx, walletB, 10); err != nil {
+		t.Fatalf("cannot deposit: %v", err)
+	}
+
+	// The deliveries are enqueued relaying the events, relaying them
+	// again, as if the relay had crashed, does not duplicate them.
+	publisher := service.WebhookPublisher{Outbox: &r}
+	if n, err := events.RelayEvents(ctx, 10, publisher); err != nil || n != 3 {
+		t.Fatalf("unexpected relayed events: %d, %v", n, err)
+	}
+	if _, err = db.ExecContext(ctx, `UPDATE events SET published_at = NULL`); err != nil {
+		t.Fatalf("cannot reset the published events: %v", err)
+	}
+	if n, err := events.RelayEvents(ctx, 10, publisher); err != nil || n != 3 {
+		t.Fatalf("unexpected events relayed again: %d, %v", n, err)
+	}
+
+	now := time.Now()
+	pending, err := r.ClaimWebhookDeliveries(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("cannot claim webhook deliveries: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("unexpected number of pending deliveries: %d", len(pending))
+	}
+	var transferEvent service.Transfer
+	if err = json.Unmarshal(pending[0].Delivery.Payload, &transferEvent); err != nil {
+		t.Fatalf("cannot unmarshal transfer event: %v", err)
+	}
+	if d := pending[0].Delivery; d.SubscriptionID != hookA.ID || d.EventType != service.TransferCreatedEvent ||
+		transferEvent.ID != transfer.ID || pending[0].Subscription.URL != hookA.URL {
+		t.Fatalf("unexpected transfer delivery: %+v, %+v", pending[0], transferEvent)
+	}
+	if d := pending[1].Delivery; d.SubscriptionID != hookB.ID || d.EventType != service.DepositCreatedEvent {
+		t.Fatalf("unexpected deposit delivery: %+v", pending[1])
+	}
+
+	// Claimed deliveries are leased.
+	if again, err := r.ClaimWebhookDeliveries(ctx, now, time.Minute, 10); err != nil || len(again) != 0 {
+		t.Fatalf("unexpected leased deliveries: %+v, %v", again, err)
+	}
+
+	statusCode := 200
+	delivered := pending[0].Delivery
+	delivered.Status = service.WebhookDeliveryDelivered
+	delivered.Attempts = 1
+	delivered.DeliveredAt = &now
+	attempt := service.WebhookDeliveryAttempt{Attempt: 1, StatusCode: &statusCode, DurationMS: 3, Date: now}
+	if err = r.RecordWebhookAttempt(ctx, delivered, attempt); err != nil {
+		t.Fatalf("cannot record webhook attempt: %v", err)
+	}
+
+	log, err := r.ListWebhookDeliveries(ctx, hookA.ID, 10)
+	if err != nil {
+		t.Fatalf("cannot list webhook deliveries: %v", err)
+	}
+	if len(log) != 1 || log[0].Status != service.WebhookDeliveryDelivered || len(log[0].Log) != 1 ||
+		*log[0].Log[0].StatusCode != statusCode {
+		t.Fatalf("unexpected delivery log: %+v", log)
+	}
+
+	if err = r.DeleteWebhook(ctx, hookA.ID); err != nil {
+		t.Fatalf("cannot delete webhook: %v", err)
+	}
+	if err = r.DeleteWebhook(ctx, hookA.ID); !errors.Is(err, service.ErrWebhookNotFound) {
+		t.Fatalf("unexpected error deleting missing webhook: %v", err)
+	}
+}
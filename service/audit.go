@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type AuditAction string
+
+const (
+	AuditLogin          AuditAction = "login"
+	AuditTransfer       AuditAction = "transfer"
+	AuditTransferCancel AuditAction = "transfer.cancel"
+	AuditBatchTransfer  AuditAction = "batch_transfer"
+	AuditAccessDenied   AuditAction = "access_denied"
+	AuditUnlock         AuditAction = "login.unlock"
+)
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+	AuditDenied  AuditOutcome = "denied"
+)
+
+// AuditEntry records who did what, for compliance. The ID, the
+// sequence and the date are assigned when it is appended to the log.
+type AuditEntry struct {
+	ID       uuid.UUID `json:"id"`
+	Sequence int64     `json:"sequence"`
+	// Actor ID of the user, or the login used if the user is unknown.
+	Actor  string      `json:"actor"`
+	Action AuditAction `json:"action"`
+	// Resource affected by the action, like "wallet:{id}".
+	Resource string       `json:"resource"`
+	Outcome  AuditOutcome `json:"outcome"`
+	AuditMetadata
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditMetadata describes the request that originated the action.
+type AuditMetadata struct {
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	RequestID string `json:"request_id"`
+}
+
+type Auditor interface {
+	// Audit appends the entry to the audit log.
+	Audit(ctx context.Context, e AuditEntry) error
+}
+
+const (
+	DefaultAuditChainInterval  = time.Second
+	DefaultAuditChainBatchSize = 500
+)
+
+type AuditChainer interface {
+	// ChainAuditLog chains the entries appended since the last call
+	// to the hash chain of the audit log.
+	// Returns:
+	// - chained: the number of chained entries.
+	ChainAuditLog(ctx context.Context, limit int) (chained int, err error)
+}
+
+// AuditChainWorker chains periodically the entries appended to the audit
+// log, so the hash chain is built off the request path by a single writer.
+type AuditChainWorker struct {
+	Chainer AuditChainer
+	// Interval time between executions, by default one second.
+	Interval time.Duration
+	// BatchSize maximum entries chained per execution, by default five hundred.
+	BatchSize int
+}
+
+// Run chains the pending entries until the context is done.
+func (w *AuditChainWorker) Run(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultAuditChainInterval
+	}
+
+	runEvery(ctx, interval, w.chain)
+}
+
+func (w *AuditChainWorker) chain(ctx context.Context) {
+	batchSize := w.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultAuditChainBatchSize
+	}
+
+	n, err := w.Chainer.ChainAuditLog(ctx, batchSize)
+	if n > 0 {
+		log.Debug().Int("chained", n).Msg("audit entries chained")
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msg("cannot chain audit log")
+	}
+}
+
+// auditMetadataCtxKey context key that holds the metadata of the request.
+type auditMetadataCtxKey struct{}
+
+// ContextWithAuditMetadata returns a context with the metadata
+// of the request, it is added to the entries audited with it.
+func ContextWithAuditMetadata(ctx context.Context, m AuditMetadata) context.Context {
+	return context.WithValue(ctx, auditMetadataCtxKey{}, m)
+}
+
+// AuditMetadataFromContext returns the metadata of the request, if any.
+func AuditMetadataFromContext(ctx context.Context) AuditMetadata {
+	m, _ := ctx.Value(auditMetadataCtxKey{}).(AuditMetadata)
+	return m
+}
+
+// Audit appends the entry to the audit log with the metadata of the
+// request in the context. The auditor is optional, the errors are
+// logged so a failure in the audit log does not fail the action.
+func Audit(ctx context.Context, a Auditor, e AuditEntry) {
+	if a == nil {
+		return
+	}
+	e.AuditMetadata = AuditMetadataFromContext(ctx)
+	if err := a.Audit(ctx, e); err != nil {
+		log.Error().
+			Err(err).
+			Str("action", string(e.Action)).
+			Str("actor", e.Actor).
+			Str("resource", e.Resource).
+			Msg("cannot audit action")
+	}
+}
+
+// auditOutcome returns the outcome of an action given its error.
+func auditOutcome(err error) AuditOutcome {
+	switch {
+	case err == nil:
+		return AuditSuccess
+	case errors.Is(err, ErrWalletAccessDenied):
+		return AuditDenied
+	default:
+		return AuditFailure
+	}
+}
+
+// walletResource returns the audit resource of a wallet.
+func walletResource(walletID uuid.UUID) string {
+	return "wallet:" + walletID.String()
+}
@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditChainWorker(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name    string
+		chained int
+		err     error
+	}{
+		{
+			name: "chainer error",
+			err:  fmt.Errorf("dummy error"),
+		}, {
+			name:    "entries chained",
+			chained: 2,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			called := make(chan struct{})
+			c := &mocks.AuditChainer{}
+			c.On("ChainAuditLog", mock.Anything, 10).
+				Return(tc.chained, tc.err).
+				Run(func(mock.Arguments) {
+					select {
+					case called <- struct{}{}:
+					default:
+					}
+				})
+
+			w := service.AuditChainWorker{
+				Chainer:   c,
+				Interval:  time.Millisecond,
+				BatchSize: 10,
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				w.Run(ctx)
+			}()
+
+			select {
+			case <-called:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the audit chainer")
+			}
+
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the worker to stop")
+			}
+		})
+	}
+}
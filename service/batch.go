@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const MaxBatchSize = 500
+
+var (
+	ErrInvalidBatch  = errors.New("invalid batch")
+	ErrBatchNotFound = errors.New("batch not found")
+)
+
+type BatchTransferCreator interface {
+	// CreateBatch transfers funds from one wallet to many destinations.
+	// In atomic mode all the transfers are executed in the same DB
+	// transaction, otherwise the transfers that cannot be executed
+	// are stored as failed.
+	// Errors:
+	// - ErrWalletNotFound: if a wallet involved does not exists.
+	// - ErrWalletAccessDenied: if the issuer cannot transfer funds from the wallet.
+	// - ErrInsufficientFunds: in atomic mode, if the origin wallet cannot afford the batch.
+	CreateBatch(ctx context.Context, req BatchRequest) (Batch, error)
+
+	// GetBatch returns a batch of the wallet with its transfers.
+	// Errors:
+	// - ErrBatchNotFound: if the wallet does not have the batch.
+	GetBatch(ctx context.Context, walletID, batchID uuid.UUID) (Batch, error)
+}
+
+type BatchRequest struct {
+	Issuer         User
+	OriginWalletID uuid.UUID
+	Mode           BatchMode
+	Items          []BatchItem
+}
+
+type BatchItem struct {
+	DestinationWalletID uuid.UUID
+	Amount              float64
+	Message             *string
+}
+
+// Validate checks all the items of the batch before any transfer is executed.
+func (req BatchRequest) Validate() error {
+	switch req.Mode {
+	case BatchAtomic, BatchBestEffort:
+	default:
+		return fmt.Errorf("%w: %q is not a valid mode. Valid values: atomic, best_effort", ErrInvalidBatch, req.Mode)
+	}
+	if len(req.Items) == 0 {
+		return fmt.Errorf("%w: no transfers", ErrInvalidBatch)
+	}
+	if len(req.Items) > MaxBatchSize {
+		return fmt.Errorf("%w: too many transfers, maximum %d", ErrInvalidBatch, MaxBatchSize)
+	}
+	for i, x := range req.Items {
+		if x.Amount <= 0 {
+			return fmt.Errorf("%w: transfer %d: %v", ErrInvalidBatch, i, ErrInvalidTransactionAmount)
+		}
+		if x.DestinationWalletID == req.OriginWalletID {
+			return fmt.Errorf("%w: transfer %d: %v", ErrInvalidBatch, i, ErrSameTransferWallets)
+		}
+	}
+
+	return nil
+}
+
+// TransferBatch transfer funds to many wallets.
+func (svc *WalletService) TransferBatch(ctx context.Context, req BatchRequest) (b Batch, err error) {
+	ctx, end := startSpan(ctx, "WalletService.TransferBatch", walletAttr(req.OriginWalletID))
+	defer end(&err)
+
+	defer func() {
+		Audit(ctx, svc.Auditor, AuditEntry{
+			Actor:    req.Issuer.ID.String(),
+			Action:   AuditBatchTransfer,
+			Resource: walletResource(req.OriginWalletID),
+			Outcome:  auditOutcome(err),
+		})
+	}()
+
+	if err = req.Validate(); err != nil {
+		return b, err
+	}
+
+	b, err = svc.BatchCreator.CreateBatch(ctx, req)
+	if err != nil {
+		return b, fmt.Errorf("cannot transfer batch: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetBatch returns a batch of transfers of the wallet.
+func (svc *WalletService) GetBatch(ctx context.Context, user User, walletID, batchID uuid.UUID) (b Batch, err error) {
+	ctx, end := startSpan(ctx, "WalletService.GetBatch", walletAttr(walletID))
+	defer end(&err)
+
+	w, err := svc.WalletReader.GetByID(ctx, walletID)
+	if err != nil {
+		return b, fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if !user.CanRead(w) {
+		return b, ErrWalletAccessDenied
+	}
+
+	b, err = svc.BatchCreator.GetBatch(ctx, walletID, batchID)
+	if err != nil {
+		return b, fmt.Errorf("cannot get batch: %w", err)
+	}
+
+	return b, nil
+}
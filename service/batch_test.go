@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+)
+
+func TestTransferBatch(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		user     = service.User{ID: uuid.New()}
+		originID = uuid.New()
+		item     = service.BatchItem{DestinationWalletID: uuid.New(), Amount: 10}
+		request  = service.BatchRequest{
+			Issuer:         user,
+			OriginWalletID: originID,
+			Mode:           service.BatchAtomic,
+			Items:          []service.BatchItem{item, item},
+		}
+		batch = service.Batch{ID: uuid.New(), OriginWalletID: originID, Mode: service.BatchAtomic}
+	)
+
+	withItems := func(items ...service.BatchItem) service.BatchRequest {
+		req := request
+		req.Items = items
+		return req
+	}
+
+	tt := []struct {
+		name      string
+		req       service.BatchRequest
+		expect    func(*mocks.BatchTransferCreator)
+		wantBatch service.Batch
+		wantErr   error
+	}{
+		{
+			name:    "invalid mode",
+			req:     service.BatchRequest{Mode: "foo", Items: request.Items},
+			wantErr: service.ErrInvalidBatch,
+		}, {
+			name:    "no transfers",
+			req:     withItems(),
+			wantErr: service.ErrInvalidBatch,
+		}, {
+			name:    "too many transfers",
+			req:     withItems(make([]service.BatchItem, service.MaxBatchSize+1)...),
+			wantErr: service.ErrInvalidBatch,
+		}, {
+			name:    "invalid amount",
+			req:     withItems(item, service.BatchItem{DestinationWalletID: uuid.New()}),
+			wantErr: service.ErrInvalidBatch,
+		}, {
+			name:    "same wallets",
+			req:     withItems(service.BatchItem{DestinationWalletID: originID, Amount: 10}),
+			wantErr: service.ErrInvalidBatch,
+		}, {
+			name: "insufficient funds",
+			req:  request,
+			expect: func(c *mocks.BatchTransferCreator) {
+				c.On("CreateBatch", anyCtx, request).Return(service.Batch{}, service.ErrInsufficientFunds)
+			},
+			wantErr: service.ErrInsufficientFunds,
+		}, {
+			name: "batch error",
+			req:  request,
+			expect: func(c *mocks.BatchTransferCreator) {
+				c.On("CreateBatch", anyCtx, request).Return(service.Batch{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "batch ok",
+			req:  request,
+			expect: func(c *mocks.BatchTransferCreator) {
+				c.On("CreateBatch", anyCtx, request).Return(batch, nil)
+			},
+			wantBatch: batch,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := &mocks.BatchTransferCreator{}
+			if tc.expect != nil {
+				tc.expect(c)
+			}
+
+			svc := service.WalletService{
+				BatchCreator: c,
+			}
+
+			got, err := svc.TransferBatch(ctx, tc.req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantBatch) {
+				t.Fatalf("unexpected batch: \n got:  %+v \n want: %+v", got, tc.wantBatch)
+			}
+		})
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		user     = service.User{ID: uuid.New()}
+		walletID = uuid.New()
+		batchID  = uuid.New()
+		wallet   = service.Wallet{ID: walletID, UserID: user.ID}
+		batch    = service.Batch{ID: batchID, OriginWalletID: walletID}
+	)
+	tt := []struct {
+		name      string
+		user      service.User
+		expect    func(*mocks.WalletReader, *mocks.BatchTransferCreator)
+		wantBatch service.Batch
+		wantErr   error
+	}{
+		{
+			name: "wallet not found",
+			user: user,
+			expect: func(wr *mocks.WalletReader, c *mocks.BatchTransferCreator) {
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, service.ErrWalletNotFound)
+			},
+			wantErr: service.ErrWalletNotFound,
+		}, {
+			name: "access denied",
+			user: service.User{ID: uuid.New()},
+			expect: func(wr *mocks.WalletReader, c *mocks.BatchTransferCreator) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "batch not found",
+			user: user,
+			expect: func(wr *mocks.WalletReader, c *mocks.BatchTransferCreator) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				c.On("GetBatch", anyCtx, walletID, batchID).Return(service.Batch{}, service.ErrBatchNotFound)
+			},
+			wantErr: service.ErrBatchNotFound,
+		}, {
+			name: "batch ok",
+			user: user,
+			expect: func(wr *mocks.WalletReader, c *mocks.BatchTransferCreator) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				c.On("GetBatch", anyCtx, walletID, batchID).Return(batch, nil)
+			},
+			wantBatch: batch,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			c := &mocks.BatchTransferCreator{}
+			tc.expect(wr, c)
+
+			svc := service.WalletService{
+				WalletReader: wr,
+				BatchCreator: c,
+			}
+
+			got, err := svc.GetBatch(ctx, tc.user, walletID, batchID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantBatch) {
+				t.Fatalf("unexpected batch: \n got:  %+v \n want: %+v", got, tc.wantBatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrorCode stable code that identifies a service error in the responses
+// of every API. The codes are part of the API contract and must not
+// change once published.
+type ErrorCode string
+
+const (
+	// users
+	CodeUnauthorized ErrorCode = "unauthorized"
+	CodeForbidden    ErrorCode = "forbidden"
+	CodeLoginLocked  ErrorCode = "login_locked"
+
+	// wallets and transfers
+	CodeWalletNotFound         ErrorCode = "wallet_not_found"
+	CodeWalletAccessDenied     ErrorCode = "wallet_access_denied"
+	CodeInvalidAmount          ErrorCode = "invalid_amount"
+	CodeInsufficientFunds      ErrorCode = "insufficient_funds"
+	CodeSameWallets            ErrorCode = "same_wallets"
+	CodeInvalidExecutionDate   ErrorCode = "invalid_execution_date"
+	CodeTransferNotFound       ErrorCode = "transfer_not_found"
+	CodeTransferNotCancellable ErrorCode = "transfer_not_cancellable"
+	CodeTransactionNotFound    ErrorCode = "transaction_not_found"
+
+	// lists
+	CodeInvalidListOrder   ErrorCode = "invalid_list_order"
+	CodeInvalidPerPage     ErrorCode = "invalid_per_page"
+	CodeMaxPerPageExceeded ErrorCode = "max_per_page_exceeded"
+	CodeInvalidExpand      ErrorCode = "invalid_expand"
+
+	// batches
+	CodeInvalidBatch  ErrorCode = "invalid_batch"
+	CodeBatchNotFound ErrorCode = "batch_not_found"
+
+	// standing orders
+	CodeInvalidRecurrence      ErrorCode = "invalid_recurrence"
+	CodeStandingOrderNotFound  ErrorCode = "standing_order_not_found"
+	CodeStandingOrderNotActive ErrorCode = "standing_order_not_active"
+
+	// payment requests
+	CodePaymentRequestNotFound   ErrorCode = "payment_request_not_found"
+	CodePaymentRequestNotPending ErrorCode = "payment_request_not_pending"
+	CodePaymentRequestExpired    ErrorCode = "payment_request_expired"
+	CodeInvalidExpirationDate    ErrorCode = "invalid_expiration_date"
+
+	// webhooks
+	CodeInvalidWebhook  ErrorCode = "invalid_webhook"
+	CodeWebhookNotFound ErrorCode = "webhook_not_found"
+)
+
+// errorCodes registry of the service errors returned to the clients,
+// the transports map the codes to their own status codes.
+var errorCodes = map[error]ErrorCode{
+	// users, the unknown users and the wrong passwords are not told apart.
+	ErrUserNotFound:    CodeUnauthorized,
+	ErrInvalidPassword: CodeForbidden,
+	ErrLoginLocked:     CodeLoginLocked,
+
+	// wallets and transfers
+	ErrWalletNotFound:           CodeWalletNotFound,
+	ErrWalletAccessDenied:       CodeWalletAccessDenied,
+	ErrInvalidTransactionAmount: CodeInvalidAmount,
+	ErrInsufficientFunds:        CodeInsufficientFunds,
+	ErrSameTransferWallets:      CodeSameWallets,
+	ErrInvalidExecutionDate:     CodeInvalidExecutionDate,
+	ErrTransferNotFound:         CodeTransferNotFound,
+	ErrTransferNotCancellable:   CodeTransferNotCancellable,
+	ErrTransactionNotFound:      CodeTransactionNotFound,
+
+	// lists
+	ErrInvalidListOrder:    CodeInvalidListOrder,
+	ErrInvalidItemsPerPage: CodeInvalidPerPage,
+	ErrMaxItemsPerPage:     CodeMaxPerPageExceeded,
+	ErrInvalidExpand:       CodeInvalidExpand,
+
+	// batches
+	ErrInvalidBatch:  CodeInvalidBatch,
+	ErrBatchNotFound: CodeBatchNotFound,
+
+	// standing orders
+	ErrInvalidRecurrence:      CodeInvalidRecurrence,
+	ErrStandingOrderNotFound:  CodeStandingOrderNotFound,
+	ErrStandingOrderNotActive: CodeStandingOrderNotActive,
+
+	// payment requests
+	ErrPaymentRequestNotFound:   CodePaymentRequestNotFound,
+	ErrPaymentRequestNotPending: CodePaymentRequestNotPending,
+	ErrPaymentRequestExpired:    CodePaymentRequestExpired,
+	ErrInvalidExpirationDate:    CodeInvalidExpirationDate,
+
+	// webhooks
+	ErrInvalidWebhook:  CodeInvalidWebhook,
+	ErrWebhookNotFound: CodeWebhookNotFound,
+}
+
+// ErrorCodeOf returns the code of the first service error found in the
+// chain of wrapped errors and the error to display to the user: the
+// service error, or the error that wraps it as "%w: details" to give
+// more context. Returns false if there is no service error in the chain.
+func ErrorCodeOf(err error) (ErrorCode, error, bool) {
+	var wrapper error
+	for e := err; e != nil; wrapper, e = e, errors.Unwrap(e) {
+		if !reflect.TypeOf(e).Comparable() {
+			continue
+		}
+		code, ok := errorCodes[e]
+		if !ok {
+			continue
+		}
+		if wrapper != nil && strings.HasPrefix(wrapper.Error(), e.Error()+": ") {
+			return code, wrapper, true
+		}
+		return code, e, true
+	}
+
+	return "", nil, false
+}
+
+// Errors returns the service errors registered by their code.
+func Errors() map[ErrorCode]error {
+	m := make(map[ErrorCode]error, len(errorCodes))
+	for err, code := range errorCodes {
+		m[code] = err
+	}
+
+	return m
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	t.Parallel()
+	detailed := fmt.Errorf("%w: balance 2.00", service.ErrInsufficientFunds)
+
+	tt := []struct {
+		name       string
+		err        error
+		wantCode   service.ErrorCode
+		wantPublic error
+		wantOK     bool
+	}{
+		{
+			name:       "service error",
+			err:        service.ErrWalletNotFound,
+			wantCode:   service.CodeWalletNotFound,
+			wantPublic: service.ErrWalletNotFound,
+			wantOK:     true,
+		}, {
+			name:       "wrapped service error",
+			err:        fmt.Errorf("cannot get wallet: %w", service.ErrWalletNotFound),
+			wantCode:   service.CodeWalletNotFound,
+			wantPublic: service.ErrWalletNotFound,
+			wantOK:     true,
+		}, {
+			name:       "service error with details",
+			err:        fmt.Errorf("cannot transfer funds: %w", detailed),
+			wantCode:   service.CodeInsufficientFunds,
+			wantPublic: detailed,
+			wantOK:     true,
+		}, {
+			name: "unknown error",
+			err:  errors.New("dummy"),
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, public, ok := service.ErrorCodeOf(tc.err)
+			if code != tc.wantCode || public != tc.wantPublic || ok != tc.wantOK {
+				t.Fatalf("unexpected code: got: %q, %v, %v, want: %q, %v, %v", code, public, ok, tc.wantCode, tc.wantPublic, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	for code, err := range service.Errors() {
+		if got, _, ok := service.ErrorCodeOf(err); !ok || got != code {
+			t.Fatalf("unexpected code of %v: got: %q, want: %q", err, got, code)
+		}
+	}
+}
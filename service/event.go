@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultEventRelayInterval  = time.Second
+	DefaultEventRelayBatchSize = 100
+)
+
+// EventPublisher sends the domain events to an integration (a
+// queue, an analytics pipeline...). The events are published at
+// least once, so publishers must discard duplicates using the ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+type EventOutbox interface {
+	// RelayEvents publishes the pending events, marking them as published.
+	// The events of a wallet are published in sequence order: if one cannot
+	// be published, or it is being published by another relay, the following
+	// events of the same wallet are left for a later call.
+	// Returns:
+	// - published: the number of published events.
+	// - err: the first error publishing an event, the rest are published anyway.
+	RelayEvents(ctx context.Context, limit int, p EventPublisher) (published int, err error)
+}
+
+// EventRelay publishes periodically the events stored in the outbox.
+type EventRelay struct {
+	Outbox    EventOutbox
+	Publisher EventPublisher
+	// Interval time between executions, by default one second.
+	Interval time.Duration
+	// BatchSize maximum events published per execution, by default one hundred.
+	BatchSize int
+}
+
+// Run publishes the pending events until the context is done.
+func (r *EventRelay) Run(ctx context.Context) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultEventRelayInterval
+	}
+
+	runEvery(ctx, interval, r.relay)
+}
+
+func (r *EventRelay) relay(ctx context.Context) {
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultEventRelayBatchSize
+	}
+
+	n, err := r.Outbox.RelayEvents(ctx, batchSize, r.Publisher)
+	if n > 0 {
+		log.Debug().Int("published", n).Msg("events relayed")
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msg("cannot relay events")
+	}
+}
+
+// LogEventPublisher publishes the events in the log.
+type LogEventPublisher struct{}
+
+func (p LogEventPublisher) Publish(_ context.Context, e Event) error {
+	log.Info().
+		Str("event_id", e.ID.String()).
+		Int64("sequence", e.Sequence).
+		Str("wallet_id", e.WalletID.String()).
+		Str("type", string(e.Type)).
+		RawJSON("payload", e.Payload).
+		Msg("event published")
+
+	return nil
+}
+
+// EventPublishers publishes the events to all the publishers in order, stopping
+// at the first error. The relay publishes the event again to all of them later.
+type EventPublishers []EventPublisher
+
+func (l EventPublishers) Publish(ctx context.Context, e Event) error {
+	for _, p := range l {
+		if err := p.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EventPublisherFunc adapts a function to the EventPublisher interface.
+type EventPublisherFunc func(ctx context.Context, e Event) error
+
+func (f EventPublisherFunc) Publish(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEventRelay(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name      string
+		published int
+		err       error
+	}{
+		{
+			name: "outbox error",
+			err:  fmt.Errorf("dummy error"),
+		}, {
+			name:      "events published",
+			published: 2,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			relayed := make(chan struct{})
+			p := &mocks.EventPublisher{}
+			o := &mocks.EventOutbox{}
+			o.On("RelayEvents", mock.Anything, 10, p).
+				Return(tc.published, tc.err).
+				Run(func(mock.Arguments) {
+					select {
+					case relayed <- struct{}{}:
+					default:
+					}
+				})
+
+			r := service.EventRelay{
+				Outbox:    o,
+				Publisher: p,
+				Interval:  time.Millisecond,
+				BatchSize: 10,
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				r.Run(ctx)
+			}()
+
+			select {
+			case <-relayed:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the events relay")
+			}
+
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the relay to stop")
+			}
+		})
+	}
+}
+
+func TestEventPublishers(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		e        = service.Event{ID: uuid.New(), Type: service.DepositCreatedEvent}
+	)
+
+	first := &mocks.EventPublisher{}
+	first.On("Publish", ctx, e).Return(nil)
+	failing := &mocks.EventPublisher{}
+	failing.On("Publish", ctx, e).Return(dummyErr)
+	last := &mocks.EventPublisher{}
+
+	err := service.EventPublishers{first, failing, last}.Publish(ctx, e)
+	if !errors.Is(err, dummyErr) {
+		t.Fatalf("unexpected error: got: %v, want %v", err, dummyErr)
+	}
+	first.AssertExpectations(t)
+	failing.AssertExpectations(t)
+	last.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
@@ -13,6 +13,7 @@ var (
 	ErrInvalidListOrder    = errors.New("invalid list order")
 	ErrInvalidItemsPerPage = errors.New("invalid items per page")
 	ErrMaxItemsPerPage     = errors.New("too many items per page request")
+	ErrInvalidExpand       = errors.New("invalid expand option")
 )
 
 const (
@@ -24,6 +25,55 @@ const (
 	MaxPerPage     = 50
 )
 
+// PageLimits limits the items of the list pages, the
+// zero value uses DefaultPerPage and MaxPerPage.
+type PageLimits struct {
+	// Default items when the page size is not requested.
+	Default int
+	// Max items that can be requested per page.
+	Max int
+}
+
+// DefaultPerPage returns the items when the page size is not requested.
+func (l PageLimits) DefaultPerPage() int {
+	if l.Default <= 0 {
+		return DefaultPerPage
+	}
+	return l.Default
+}
+
+// MaxPerPage returns the items that can be requested per page.
+func (l PageLimits) MaxPerPage() int {
+	if l.Max <= 0 {
+		return MaxPerPage
+	}
+	return l.Max
+}
+
+// ParsePerPage parses a string as a "per page" list value within the limits.
+func (l PageLimits) ParsePerPage(in string) (int, error) {
+	maxPerPage := l.MaxPerPage()
+	x, err := strconv.Atoi(in)
+	if err != nil {
+		return 0, fmt.Errorf("%w: must be between %d and %d", ErrInvalidItemsPerPage, 1, maxPerPage)
+	}
+	if x < 0 || x > maxPerPage {
+		return 0, fmt.Errorf("%w: must be between %d and %d", ErrMaxItemsPerPage, 1, maxPerPage)
+	}
+
+	return x, nil
+}
+
+// ParsePerPageOrDefault parses a string as a "per page" list value
+// if invalid it default to the default per page.
+func (l PageLimits) ParsePerPageOrDefault(in string) int {
+	if x, err := l.ParsePerPage(in); err == nil {
+		return x
+	}
+
+	return l.DefaultPerPage()
+}
+
 type ListOrder int
 
 // Ascending checks if the order is ascending.
@@ -56,26 +106,32 @@ func ParseListOrderOrDefault(in string) ListOrder {
 	return Ascending
 }
 
+// ParsePerPage parses a string as a "per page" list value with the default limits.
 func ParsePerPage(in string) (int, error) {
-	x, err := strconv.Atoi(in)
-	if err != nil {
-		return 0, fmt.Errorf("%w: must be between %d and %d", ErrInvalidItemsPerPage, 1, MaxPerPage)
-	}
-	if x < 0 || x > MaxPerPage {
-		return 0, fmt.Errorf("%w: must be between %d and %d", ErrMaxItemsPerPage, 1, MaxPerPage)
-	}
-
-	return x, nil
+	return PageLimits{}.ParsePerPage(in)
 }
 
-// ParseListOrder parses a string as a "per page" list value
+// ParsePerPageOrDefault parses a string as a "per page" list value
 // if invalid it default to the default per page.
 func ParsePerPageOrDefault(in string) int {
-	if x, err := ParsePerPage(in); err == nil {
-		return x
+	return PageLimits{}.ParsePerPageOrDefault(in)
+}
+
+// ParseExpandTransfer parses a comma separated list of the related
+// resources to expand, returning whether the transfer is requested.
+func ParseExpandTransfer(in string) (bool, error) {
+	var transfer bool
+	for _, x := range strings.Split(in, ",") {
+		switch x = strings.TrimSpace(strings.ToLower(x)); x {
+		case "":
+		case "transfer":
+			transfer = true
+		default:
+			return false, fmt.Errorf("%w: %q is not a valid expand option. Valid values: transfer", ErrInvalidExpand, x)
+		}
 	}
 
-	return DefaultPerPage
+	return transfer, nil
 }
 
 // ListOptions contains the options of a resource list.
@@ -86,4 +142,6 @@ type ListOptions struct {
 	Order ListOrder
 	// PerPage maximum number of items to return.
 	PerPage int
+	// ExpandTransfer embeds the details of the originating transfer in each transaction.
+	ExpandTransfer bool
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// SubscribeTransactions returns the transactions of the wallet as they are committed.
+// If the last received transaction is given, the transactions that followed it are
+// sent first from the history. The channel is closed when the context is done or the
+// live updates are interrupted, the subscriber can resume from the last transaction.
+func (svc *WalletService) SubscribeTransactions(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	lastID *uuid.UUID,
+) (<-chan Transaction, error) {
+	w, err := svc.WalletReader.GetByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if !user.CanRead(w) {
+		return nil, ErrWalletAccessDenied
+	}
+
+	// Listen before reading the history, so the transactions
+	// committed meanwhile are not missed.
+	ctx, cancel := context.WithCancel(ctx)
+	ids, err := svc.Listener.ListenTransactions(ctx, walletID)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("cannot listen transactions: %w", err)
+	}
+
+	var history []Transaction
+	if lastID != nil {
+		history, err = svc.TransactionsReader.ListTransactionsAfter(ctx, walletID, *lastID)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("cannot list transactions: %w", err)
+		}
+	}
+
+	out := make(chan Transaction)
+	go func() {
+		defer cancel()
+		defer close(out)
+		svc.sendTransactions(ctx, walletID, history, ids, out)
+	}()
+
+	return out, nil
+}
+
+// sendTransactions sends the history followed by the notified
+// transactions, skipping the ones that were already in the history.
+func (svc *WalletService) sendTransactions(
+	ctx context.Context,
+	walletID uuid.UUID,
+	history []Transaction,
+	ids <-chan uuid.UUID,
+	out chan<- Transaction,
+) {
+	send := func(t Transaction) bool {
+		select {
+		case out <- t:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	sent := make(map[uuid.UUID]bool, len(history))
+	for _, t := range history {
+		sent[t.ID] = true
+		if !send(t) {
+			return
+		}
+	}
+
+	for id := range ids {
+		if sent[id] {
+			continue
+		}
+		t, err := svc.TransactionsReader.GetTransaction(ctx, walletID, id)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Str("transaction_id", id.String()).Msg("cannot get notified transaction")
+			}
+			return
+		}
+		if !send(t) {
+			return
+		}
+	}
+}
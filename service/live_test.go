@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubscribeTransactions(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		issuerID = uuid.New()
+		user     = service.User{ID: issuerID}
+		walletID = uuid.New()
+		wallet   = service.Wallet{ID: walletID, UserID: issuerID}
+		lastID   = uuid.New()
+		txA      = service.Transaction{ID: uuid.New(), Amount: 10}
+		txB      = service.Transaction{ID: uuid.New(), Amount: 20}
+		txC      = service.Transaction{ID: uuid.New(), Amount: 30}
+	)
+
+	// notify returns a closed channel with the given notifications.
+	notify := func(ids ...uuid.UUID) <-chan uuid.UUID {
+		ch := make(chan uuid.UUID, len(ids))
+		for _, id := range ids {
+			ch <- id
+		}
+		close(ch)
+		return ch
+	}
+
+	tt := []struct {
+		name    string
+		user    service.User
+		lastID  *uuid.UUID
+		expect  func(*mocks.WalletReader, *mocks.TransactionsReader, *mocks.TransactionListener)
+		wantErr error
+		want    []service.Transaction
+	}{
+		{
+			name: "error loading wallet",
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(service.Wallet{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "wallet access denied",
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "listen error",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				tl.On("ListenTransactions", mock.Anything, walletID).Return(nil, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name:   "last transaction not found",
+			user:   user,
+			lastID: &lastID,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				tl.On("ListenTransactions", mock.Anything, walletID).Return(notify(), nil)
+				tr.On("ListTransactionsAfter", mock.Anything, walletID, lastID).Return(nil, service.ErrTransactionNotFound)
+			},
+			wantErr: service.ErrTransactionNotFound,
+		}, {
+			name: "live transactions",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				tl.On("ListenTransactions", mock.Anything, walletID).Return(notify(txA.ID, txB.ID), nil)
+				tr.On("GetTransaction", mock.Anything, walletID, txA.ID).Return(txA, nil)
+				tr.On("GetTransaction", mock.Anything, walletID, txB.ID).Return(txB, nil)
+			},
+			want: []service.Transaction{txA, txB},
+		}, {
+			name:   "resume skipping the notified transactions in the history",
+			user:   user,
+			lastID: &lastID,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				tl.On("ListenTransactions", mock.Anything, walletID).Return(notify(txB.ID, txC.ID), nil)
+				tr.On("ListTransactionsAfter", mock.Anything, walletID, lastID).Return([]service.Transaction{txA, txB}, nil)
+				tr.On("GetTransaction", mock.Anything, walletID, txC.ID).Return(txC, nil)
+			},
+			want: []service.Transaction{txA, txB, txC},
+		}, {
+			name: "error getting notified transaction",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader, tl *mocks.TransactionListener) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				tl.On("ListenTransactions", mock.Anything, walletID).Return(notify(txA.ID, txB.ID), nil)
+				tr.On("GetTransaction", mock.Anything, walletID, txA.ID).Return(service.Transaction{}, dummyErr)
+			},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			tr := &mocks.TransactionsReader{}
+			tl := &mocks.TransactionListener{}
+			tc.expect(wr, tr, tl)
+
+			svc := service.WalletService{
+				WalletReader:       wr,
+				TransactionsReader: tr,
+				Listener:           tl,
+			}
+
+			ch, err := svc.SubscribeTransactions(ctx, tc.user, walletID, tc.lastID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var got []service.Transaction
+			for x := range ch {
+				got = append(got, x)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected transactions: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+		})
+	}
+}
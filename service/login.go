@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+var (
+	// DefaultLoginPolicy allows three failed attempts of a login before
+	// delaying the next ones, and locks it after ten for fifteen minutes.
+	DefaultLoginPolicy = LoginPolicy{
+		FreeAttempts:    3,
+		Delay:           time.Second,
+		MaxAttempts:     10,
+		LockoutDuration: 15 * time.Minute,
+	}
+	// DefaultIPPolicy is more permissive than the login one
+	// because many users can share the IP behind a NAT.
+	DefaultIPPolicy = LoginPolicy{
+		FreeAttempts:    20,
+		Delay:           time.Second,
+		MaxAttempts:     100,
+		LockoutDuration: 15 * time.Minute,
+	}
+)
+
+// LoginLockedError the login or the IP of the request cannot try to
+// login again until the given time, it wraps ErrLoginLocked.
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LoginAttempts failed login attempts of a login or an IP.
+type LoginAttempts struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+type LoginAttemptStore interface {
+	// GetAttempts returns the failed attempts of the key, zero if there are none.
+	GetAttempts(ctx context.Context, key string) (LoginAttempts, error)
+
+	// AddFailure records a failed attempt of the key at the given time and
+	// returns the updated attempts. The previous failures are forgotten if
+	// the last one happened before the window.
+	AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (LoginAttempts, error)
+
+	// ResetAttempts forgets the failed attempts of the key.
+	ResetAttempts(ctx context.Context, key string) error
+}
+
+// LoginPolicy defines how the failed login attempts are throttled.
+type LoginPolicy struct {
+	// FreeAttempts failed attempts allowed without delay.
+	FreeAttempts int
+	// Delay wait after the first throttled attempt, it doubles with each failure.
+	Delay time.Duration
+	// MaxAttempts failed attempts that lock the logins for the lockout duration.
+	MaxAttempts int
+	// LockoutDuration maximum wait, the failures are forgotten after it.
+	LockoutDuration time.Duration
+}
+
+// lockedUntil returns the time until the next attempt is not allowed.
+func (p LoginPolicy) lockedUntil(a LoginAttempts) time.Time {
+	if a.Failures < p.FreeAttempts {
+		return time.Time{}
+	}
+	delay := p.LockoutDuration
+	if n := a.Failures - p.FreeAttempts; a.Failures < p.MaxAttempts && n < 32 && p.Delay<<n < delay {
+		delay = p.Delay << n
+	}
+
+	return a.LastFailure.Add(delay)
+}
+
+// UnlockLogin forgets the failed attempts of the login, so it can
+// login again without delay.
+func (svc *UserService) UnlockLogin(ctx context.Context, admin, login string) (err error) {
+	defer svc.auditUnlock(ctx, admin, loginAttemptsKey(login), &err)
+
+	if svc.Attempts == nil {
+		return nil
+	}
+	if err = svc.Attempts.ResetAttempts(ctx, loginAttemptsKey(login)); err != nil {
+		return fmt.Errorf("cannot unlock login: %w", err)
+	}
+
+	return nil
+}
+
+// UnlockIP forgets the failed attempts of the IP, so it can
+// login again without delay.
+func (svc *UserService) UnlockIP(ctx context.Context, admin, ip string) (err error) {
+	defer svc.auditUnlock(ctx, admin, ipAttemptsKey(ip), &err)
+
+	if svc.Attempts == nil {
+		return nil
+	}
+	if err = svc.Attempts.ResetAttempts(ctx, ipAttemptsKey(ip)); err != nil {
+		return fmt.Errorf("cannot unlock IP: %w", err)
+	}
+
+	return nil
+}
+
+func (svc *UserService) auditUnlock(ctx context.Context, admin, resource string, err *error) {
+	Audit(ctx, svc.Auditor, AuditEntry{
+		Actor:    admin,
+		Action:   AuditUnlock,
+		Resource: resource,
+		Outcome:  auditOutcome(*err),
+	})
+}
+
+// checkAttempts returns a LoginLockedError if the login or the IP
+// have to wait before trying to login again, and whether the login
+// has failures to forget after a successful attempt.
+func (svc *UserService) checkAttempts(ctx context.Context, login, ip string) (bool, error) {
+	if svc.Attempts == nil {
+		return false, nil
+	}
+
+	var (
+		failed bool
+		until  time.Time
+	)
+	for _, k := range svc.attemptKeys(login, ip) {
+		a, err := svc.Attempts.GetAttempts(ctx, k.key)
+		if err != nil {
+			return false, fmt.Errorf("cannot get login attempts: %w", err)
+		}
+		if k.key == loginAttemptsKey(login) && a.Failures > 0 {
+			failed = true
+		}
+		if t := k.policy.lockedUntil(a); t.After(until) {
+			until = t
+		}
+	}
+	if until.After(time.Now()) {
+		return false, &LoginLockedError{Until: until}
+	}
+
+	return failed, nil
+}
+
+// addFailure records a failed attempt of the login and the IP. It
+// returns a LoginLockedError if the incremented failures exceed the
+// maximum attempts, the attempt raced others that locked the login.
+func (svc *UserService) addFailure(ctx context.Context, login, ip string) error {
+	if svc.Attempts == nil {
+		return nil
+	}
+
+	var (
+		now   = time.Now()
+		until time.Time
+	)
+	for _, k := range svc.attemptKeys(login, ip) {
+		a, err := svc.Attempts.AddFailure(ctx, k.key, now, k.policy.LockoutDuration)
+		if err != nil {
+			return fmt.Errorf("cannot add failed login attempt: %w", err)
+		}
+		if a.Failures <= k.policy.MaxAttempts {
+			continue
+		}
+		if t := k.policy.lockedUntil(a); t.After(until) {
+			until = t
+		}
+	}
+	if until.After(now) {
+		return &LoginLockedError{Until: until}
+	}
+
+	return nil
+}
+
+// resetLogin forgets the failed attempts of the login after a successful
+// one, the ones of the IP are kept so a valid account of an attacker
+// cannot be used to keep guessing other logins.
+func (svc *UserService) resetLogin(ctx context.Context, login string) error {
+	if svc.Attempts == nil {
+		return nil
+	}
+	if err := svc.Attempts.ResetAttempts(ctx, loginAttemptsKey(login)); err != nil {
+		return fmt.Errorf("cannot reset login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// attemptKey key of the attempts of a login or an IP with its policy.
+type attemptKey struct {
+	key    string
+	policy LoginPolicy
+}
+
+// attemptKeys returns the keys of the attempt, the IP is
+// unknown when the request does not come from the API.
+func (svc *UserService) attemptKeys(login, ip string) []attemptKey {
+	keys := []attemptKey{{
+		key:    loginAttemptsKey(login),
+		policy: policyOrDefault(svc.LoginPolicy, DefaultLoginPolicy),
+	}}
+	if ip != "" {
+		keys = append(keys, attemptKey{
+			key:    ipAttemptsKey(ip),
+			policy: policyOrDefault(svc.IPPolicy, DefaultIPPolicy),
+		})
+	}
+
+	return keys
+}
+
+func policyOrDefault(p, def LoginPolicy) LoginPolicy {
+	if p == (LoginPolicy{}) {
+		return def
+	}
+	return p
+}
+
+func loginAttemptsKey(login string) string {
+	return "login:" + login
+}
+
+func ipAttemptsKey(ip string) string {
+	return "ip:" + ip
+}
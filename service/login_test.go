@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoginAttempts(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = service.ContextWithAuditMetadata(context.Background(), service.AuditMetadata{IP: "127.0.0.1"})
+		user     = service.User{ID: uuid.New(), Login: "foo", HashedPassword: "bar"}
+		loginKey = "login:foo"
+		ipKey    = "ip:127.0.0.1"
+		window   = service.DefaultLoginPolicy.LockoutDuration
+		now      = time.Now()
+	)
+	tt := []struct {
+		name      string
+		password  string
+		expect    func(*mocks.LoginAttemptStore, *mocks.UserReader)
+		wantErr   error
+		wantUntil time.Time
+	}{
+		{
+			name: "login locked",
+			expect: func(s *mocks.LoginAttemptStore, _ *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{Failures: 10, LastFailure: now}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{}, nil)
+			},
+			wantErr:   service.ErrLoginLocked,
+			wantUntil: now.Add(window),
+		}, {
+			name: "login delayed",
+			expect: func(s *mocks.LoginAttemptStore, _ *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{Failures: 5, LastFailure: now}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{}, nil)
+			},
+			wantErr:   service.ErrLoginLocked,
+			wantUntil: now.Add(4 * time.Second),
+		}, {
+			name: "ip delayed",
+			expect: func(s *mocks.LoginAttemptStore, _ *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{Failures: 20, LastFailure: now}, nil)
+			},
+			wantErr:   service.ErrLoginLocked,
+			wantUntil: now.Add(time.Second),
+		}, {
+			name:     "delay passed, wrong password",
+			password: "wrong",
+			expect: func(s *mocks.LoginAttemptStore, r *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{Failures: 5, LastFailure: now.Add(-5 * time.Second)}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{}, nil)
+				r.On("GetByLogin", ctx, user.Login).Return(user, nil)
+				s.On("AddFailure", ctx, loginKey, mock.AnythingOfType("time.Time"), window).Return(service.LoginAttempts{}, nil)
+				s.On("AddFailure", ctx, ipKey, mock.AnythingOfType("time.Time"), window).Return(service.LoginAttempts{}, nil)
+			},
+			wantErr: service.ErrInvalidPassword,
+		}, {
+			name:     "concurrent failures exceed the maximum",
+			password: "wrong",
+			expect: func(s *mocks.LoginAttemptStore, r *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{Failures: 9, LastFailure: now.Add(-time.Hour)}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{}, nil)
+				r.On("GetByLogin", ctx, user.Login).Return(user, nil)
+				s.On("AddFailure", ctx, loginKey, mock.AnythingOfType("time.Time"), window).Return(service.LoginAttempts{Failures: 11, LastFailure: now}, nil)
+				s.On("AddFailure", ctx, ipKey, mock.AnythingOfType("time.Time"), window).Return(service.LoginAttempts{Failures: 1, LastFailure: now}, nil)
+			},
+			wantErr:   service.ErrLoginLocked,
+			wantUntil: now.Add(window),
+		}, {
+			name: "unknown login",
+			expect: func(s *mocks.LoginAttemptStore, r *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{}, nil)
+				r.On("GetByLogin", ctx, user.Login).Return(service.User{}, service.ErrUserNotFound)
+				s.On("AddFailure", ctx, loginKey, mock.AnythingOfType("time.Time"), window).Return(service.LoginAttempts{}, nil)
+				s.On("AddFailure", ctx, ipKey, mock.AnythingOfType("time.Time"), window).Return(service.LoginAttempts{}, nil)
+			},
+			wantErr: service.ErrUserNotFound,
+		}, {
+			name:     "success resets the login",
+			password: user.HashedPassword,
+			expect: func(s *mocks.LoginAttemptStore, r *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{Failures: 2, LastFailure: now}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{Failures: 2, LastFailure: now}, nil)
+				r.On("GetByLogin", ctx, user.Login).Return(user, nil)
+				s.On("ResetAttempts", ctx, loginKey).Return(nil)
+			},
+		}, {
+			name:     "success without failures does not reset the login",
+			password: user.HashedPassword,
+			expect: func(s *mocks.LoginAttemptStore, r *mocks.UserReader) {
+				s.On("GetAttempts", ctx, loginKey).Return(service.LoginAttempts{}, nil)
+				s.On("GetAttempts", ctx, ipKey).Return(service.LoginAttempts{Failures: 2, LastFailure: now}, nil)
+				r.On("GetByLogin", ctx, user.Login).Return(user, nil)
+			},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := &mocks.LoginAttemptStore{}
+			r := &mocks.UserReader{}
+			tc.expect(s, r)
+
+			svc := service.UserService{
+				Reader:   r,
+				Attempts: s,
+			}
+
+			_, err := svc.Login(ctx, user.Login, tc.password)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			var locked *service.LoginLockedError
+			if errors.As(err, &locked) && !locked.Until.Equal(tc.wantUntil) {
+				t.Fatalf("unexpected lock: \n got:  %+v \n want: %+v", locked.Until, tc.wantUntil)
+			}
+			s.AssertExpectations(t)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = errors.New("dummy error")
+	)
+
+	s := &mocks.LoginAttemptStore{}
+	s.On("ResetAttempts", ctx, "login:foo").Return(nil)
+	s.On("ResetAttempts", ctx, "ip:127.0.0.1").Return(dummyErr)
+	a := &mocks.Auditor{}
+	a.On("Audit", ctx, service.AuditEntry{
+		Actor:    "admin",
+		Action:   service.AuditUnlock,
+		Resource: "login:foo",
+		Outcome:  service.AuditSuccess,
+	}).Return(nil)
+	a.On("Audit", ctx, service.AuditEntry{
+		Actor:    "admin",
+		Action:   service.AuditUnlock,
+		Resource: "ip:127.0.0.1",
+		Outcome:  service.AuditFailure,
+	}).Return(nil)
+
+	svc := service.UserService{
+		Attempts: s,
+		Auditor:  a,
+	}
+	if err := svc.UnlockLogin(ctx, "admin", "foo"); err != nil {
+		t.Fatalf("cannot unlock login: %v", err)
+	}
+	if err := svc.UnlockIP(ctx, "admin", "127.0.0.1"); !errors.Is(err, dummyErr) {
+		t.Fatalf("unexpected error: got: %v, want %v", err, dummyErr)
+	}
+	a.AssertExpectations(t)
+}
@@ -4,11 +4,11 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
+	uuid "github.com/google/uuid"
 	service "github.com/hmoragrega/paybile/service"
 	mock "github.com/stretchr/testify/mock"
-
-	uuid "github.com/google/uuid"
 )
 
 // TransactionsReader is an autogenerated mock type for the TransactionsReader type
@@ -16,6 +16,71 @@ type TransactionsReader struct {
 	mock.Mock
 }
 
+// BalanceAt provides a mock function with given fields: ctx, walletID, at
+func (_m *TransactionsReader) BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (float64, error) {
+	ret := _m.Called(ctx, walletID, at)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) float64); ok {
+		r0 = rf(ctx, walletID, at)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r1 = rf(ctx, walletID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransaction provides a mock function with given fields: ctx, walletID, transactionID
+func (_m *TransactionsReader) GetTransaction(ctx context.Context, walletID uuid.UUID, transactionID uuid.UUID) (service.Transaction, error) {
+	ret := _m.Called(ctx, walletID, transactionID)
+
+	var r0 service.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) service.Transaction); ok {
+		r0 = rf(ctx, walletID, transactionID)
+	} else {
+		r0 = ret.Get(0).(service.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, walletID, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListStatementLines provides a mock function with given fields: ctx, walletID, from, to
+func (_m *TransactionsReader) ListStatementLines(ctx context.Context, walletID uuid.UUID, from time.Time, to time.Time) ([]service.StatementLine, error) {
+	ret := _m.Called(ctx, walletID, from, to)
+
+	var r0 []service.StatementLine
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time, time.Time) []service.StatementLine); ok {
+		r0 = rf(ctx, walletID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.StatementLine)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, walletID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListTransactions provides a mock function with given fields: ctx, walletID, filters
 func (_m *TransactionsReader) ListTransactions(ctx context.Context, walletID uuid.UUID, filters service.ListOptions) (service.TransactionList, error) {
 	ret := _m.Called(ctx, walletID, filters)
@@ -36,3 +101,40 @@ func (_m *TransactionsReader) ListTransactions(ctx context.Context, walletID uui
 
 	return r0, r1
 }
+
+// ListTransactionsAfter provides a mock function with given fields: ctx, walletID, transactionID
+func (_m *TransactionsReader) ListTransactionsAfter(ctx context.Context, walletID uuid.UUID, transactionID uuid.UUID) ([]service.Transaction, error) {
+	ret := _m.Called(ctx, walletID, transactionID)
+
+	var r0 []service.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []service.Transaction); ok {
+		r0 = rf(ctx, walletID, transactionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, walletID, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamTransactions provides a mock function with given fields: ctx, walletID, fn
+func (_m *TransactionsReader) StreamTransactions(ctx context.Context, walletID uuid.UUID, fn func(service.Transaction) error) error {
+	ret := _m.Called(ctx, walletID, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, func(service.Transaction) error) error); ok {
+		r0 = rf(ctx, walletID, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
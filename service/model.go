@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -43,11 +44,32 @@ func (w Wallet) IsOwner(u User) bool {
 	return w.UserID == u.ID
 }
 
+// WalletBalance balance of a wallet at a point in time.
+type WalletBalance struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	Balance  float64   `json:"balance"`
+	At       time.Time `json:"at"`
+}
+
 type TransactionType string
 
 const (
 	TransferType TransactionType = "transfer"
 	DepositType                  = "deposit"
+	FeeType                      = "fee"
+)
+
+// SystemAccount ledger account that is not a wallet, it is the
+// counterpart of the funds that enter or leave the wallets.
+type SystemAccount string
+
+const (
+	// CashInAccount funds deposited in the wallets.
+	CashInAccount SystemAccount = "cash_in"
+	// FeesAccount fees charged to the wallets.
+	FeesAccount SystemAccount = "fees"
+	// SuspenseAccount funds that cannot be attributed yet.
+	SuspenseAccount SystemAccount = "suspense"
 )
 
 type Transaction struct {
@@ -57,14 +79,218 @@ type Transaction struct {
 	Type        TransactionType `json:"transaction_type"`
 	ReferenceID *uuid.UUID      `json:"reference_id"`
 	Date        time.Time       `json:"date"`
+	// Transfer details of the originating transfer, only when requested.
+	Transfer *TransactionTransfer `json:"transfer,omitempty"`
+}
+
+// TransactionTransfer details of the transfer that originated a transaction.
+type TransactionTransfer struct {
+	// CounterpartyWalletID the other wallet of the transfer.
+	CounterpartyWalletID uuid.UUID `json:"counterparty_wallet_id"`
+	IssuerID             uuid.UUID `json:"issuer_id"`
+	Message              *string   `json:"message"`
+}
+
+// Statement the wallet transactions of a period, from
+// inclusive to exclusive, with the balances at its boundaries.
+type Statement struct {
+	WalletID       uuid.UUID       `json:"wallet_id"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance float64         `json:"opening_balance"`
+	ClosingBalance float64         `json:"closing_balance"`
+	Lines          []StatementLine `json:"transactions"`
 }
 
+// StatementLine a wallet transaction with the details of the transfer that originated it.
+type StatementLine struct {
+	Transaction
+	CounterpartyWalletID *uuid.UUID `json:"counterparty_wallet_id"`
+	Message              *string    `json:"message"`
+}
+
+type TransferStatus string
+
+const (
+	// TransferCompleted the funds have been moved between the wallets.
+	TransferCompleted TransferStatus = "completed"
+	// TransferScheduled the transfer will be executed in the future.
+	TransferScheduled TransferStatus = "scheduled"
+	// TransferCancelled the scheduled transfer was cancelled before its execution.
+	TransferCancelled TransferStatus = "cancelled"
+	// TransferFailed the scheduled transfer could not be executed.
+	TransferFailed TransferStatus = "failed"
+)
+
 type Transfer struct {
-	ID                  uuid.UUID `json:"id"`
-	IssuerID            uuid.UUID `json:"issuer_id"`
-	OriginWalletID      uuid.UUID `json:"origin_wallet_id"`
-	DestinationWalletID uuid.UUID `json:"destination_wallet_id"`
-	Amount              float64   `json:"amount"`
-	Message             *string   `json:"message"`
-	Date                time.Time `json:"date"`
+	ID                  uuid.UUID      `json:"id"`
+	IssuerID            uuid.UUID      `json:"issuer_id"`
+	OriginWalletID      uuid.UUID      `json:"origin_wallet_id"`
+	DestinationWalletID uuid.UUID      `json:"destination_wallet_id"`
+	Amount              float64        `json:"amount"`
+	Message             *string        `json:"message"`
+	Date                time.Time      `json:"date"`
+	Status              TransferStatus `json:"status"`
+	ExecuteAt           *time.Time     `json:"execute_at,omitempty"`
+	FailureReason       *string        `json:"failure_reason,omitempty"`
+	BatchID             *uuid.UUID     `json:"batch_id,omitempty"`
+}
+
+type BatchMode string
+
+const (
+	// BatchAtomic all the transfers of the batch are executed or none.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort the transfers that cannot be executed are marked as failed.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// Batch group of transfers from one origin wallet to many destinations.
+type Batch struct {
+	ID             uuid.UUID  `json:"id"`
+	IssuerID       uuid.UUID  `json:"issuer_id"`
+	OriginWalletID uuid.UUID  `json:"origin_wallet_id"`
+	Mode           BatchMode  `json:"mode"`
+	Date           time.Time  `json:"date"`
+	Transfers      []Transfer `json:"transfers"`
+}
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderActive    StandingOrderStatus = "active"
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+	StandingOrderFinished  StandingOrderStatus = "finished"
+)
+
+type StandingOrder struct {
+	ID                  uuid.UUID           `json:"id"`
+	IssuerID            uuid.UUID           `json:"issuer_id"`
+	OriginWalletID      uuid.UUID           `json:"origin_wallet_id"`
+	DestinationWalletID uuid.UUID           `json:"destination_wallet_id"`
+	Amount              float64             `json:"amount"`
+	Message             *string             `json:"message"`
+	Recurrence          Recurrence          `json:"recurrence"`
+	Status              StandingOrderStatus `json:"status"`
+	Runs                int                 `json:"runs"`
+	NextRunAt           *time.Time          `json:"next_run_at"`
+	CreatedAt           time.Time           `json:"created_at"`
+}
+
+// StandingOrderRun result of a standing order execution.
+type StandingOrderRun struct {
+	ID              uuid.UUID `json:"id"`
+	StandingOrderID uuid.UUID `json:"standing_order_id"`
+	// TransferID transfer created by the execution, empty if it failed.
+	TransferID    *uuid.UUID     `json:"transfer_id"`
+	ScheduledAt   time.Time      `json:"scheduled_at"`
+	Date          time.Time      `json:"date"`
+	Status        TransferStatus `json:"status"`
+	FailureReason *string        `json:"failure_reason"`
+}
+
+type PaymentRequestStatus string
+
+const (
+	// PaymentRequestPending the payer has not answered the request yet.
+	PaymentRequestPending PaymentRequestStatus = "pending"
+	// PaymentRequestAccepted the payer has transferred the funds.
+	PaymentRequestAccepted PaymentRequestStatus = "accepted"
+	// PaymentRequestDeclined the payer has rejected the request.
+	PaymentRequestDeclined PaymentRequestStatus = "declined"
+	// PaymentRequestExpired the payer did not answer before the expiration date.
+	PaymentRequestExpired PaymentRequestStatus = "expired"
+)
+
+// PaymentRequest request of funds from one user to the wallet of another.
+type PaymentRequest struct {
+	ID          uuid.UUID `json:"id"`
+	RequesterID uuid.UUID `json:"requester_id"`
+	// RequesterWalletID wallet that will receive the funds.
+	RequesterWalletID uuid.UUID `json:"requester_wallet_id"`
+	PayerID           uuid.UUID `json:"payer_id"`
+	// PayerWalletID wallet that will send the funds.
+	PayerWalletID uuid.UUID            `json:"payer_wallet_id"`
+	Amount        float64              `json:"amount"`
+	Message       *string              `json:"message"`
+	Status        PaymentRequestStatus `json:"status"`
+	// TransferID transfer created when the request is accepted.
+	TransferID *uuid.UUID `json:"transfer_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// EventType type of the domain events.
+type EventType string
+
+const (
+	// TransferCreatedEvent funds have been transferred between two wallets.
+	TransferCreatedEvent EventType = "transfer.created"
+	// DepositCreatedEvent funds have been deposited in a wallet.
+	DepositCreatedEvent EventType = "deposit.created"
+)
+
+// Event something that happened to a wallet. The sequence
+// orders the events of the same wallet as they happened.
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Sequence  int64           `json:"sequence"`
+	WalletID  uuid.UUID       `json:"wallet_id"`
+	Type      EventType       `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// WebhookSubscription endpoint of a user that is notified of the events of its wallets.
+type WebhookSubscription struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	URL    string    `json:"url"`
+	// Secret used to sign the payloads, it is never returned.
+	Secret     string      `json:"-"`
+	EventTypes []EventType `json:"event_types"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// IsOwner checks if the user is the owner of the subscription.
+func (s WebhookSubscription) IsOwner(u User) bool {
+	return s.UserID == u.ID
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending the event has not been delivered yet.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered the endpoint has acknowledged the event.
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead all the delivery attempts have failed.
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery an event to be delivered to a subscription.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	EventType      EventType             `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	// Log the delivery attempts in order.
+	Log []WebhookDeliveryAttempt `json:"log"`
+}
+
+// WebhookDeliveryAttempt result of a delivery attempt.
+type WebhookDeliveryAttempt struct {
+	Attempt int `json:"attempt"`
+	// StatusCode response status, empty if the request failed.
+	StatusCode *int      `json:"status_code"`
+	Error      *string   `json:"error"`
+	DurationMS int       `json:"duration_ms"`
+	Date       time.Time `json:"date"`
 }
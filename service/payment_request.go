@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPaymentRequestTTL time to answer a payment request when no expiration date is given.
+const DefaultPaymentRequestTTL = 7 * 24 * time.Hour
+
+var (
+	ErrPaymentRequestNotFound   = errors.New("payment request not found")
+	ErrPaymentRequestNotPending = errors.New("payment request is not pending")
+	ErrPaymentRequestExpired    = errors.New("payment request has expired")
+	ErrInvalidExpirationDate    = errors.New("expiration date must be in the future")
+)
+
+type PaymentRequestDirection string
+
+const (
+	// IncomingPaymentRequests requests where the user is the payer.
+	IncomingPaymentRequests PaymentRequestDirection = "incoming"
+	// OutgoingPaymentRequests requests where the user is the requester.
+	OutgoingPaymentRequests PaymentRequestDirection = "outgoing"
+)
+
+type PaymentRequestRepository interface {
+	// CreatePaymentRequest stores a new payment request.
+	CreatePaymentRequest(ctx context.Context, p PaymentRequest) (PaymentRequest, error)
+
+	// GetPaymentRequest returns a payment request by the ID.
+	// Errors:
+	// - ErrPaymentRequestNotFound: if the payment request does not exists.
+	GetPaymentRequest(ctx context.Context, requestID uuid.UUID) (PaymentRequest, error)
+
+	// ListPaymentRequests returns the payment requests of the user
+	// in the given direction ordered by creation date, the newest first.
+	// Optionally filtered by status.
+	ListPaymentRequests(
+		ctx context.Context,
+		userID uuid.UUID,
+		direction PaymentRequestDirection,
+		status *PaymentRequestStatus,
+	) ([]PaymentRequest, error)
+
+	// UpdatePaymentRequest updates the status and the transfer of the
+	// payment request only if its current status is the given one.
+	// Errors:
+	// - ErrPaymentRequestNotPending: if the status has changed.
+	UpdatePaymentRequest(ctx context.Context, p PaymentRequest, current PaymentRequestStatus) (PaymentRequest, error)
+
+	// AcceptPaymentRequest accepts a pending payment request and creates the
+	// transfer that pays it atomically, on error neither of them is stored.
+	// Errors:
+	// - ErrPaymentRequestNotPending: if the status has changed.
+	// - ErrPaymentRequestExpired: if the payment request has expired.
+	// - Any error of TransferCreator.CreateTransfer.
+	AcceptPaymentRequest(ctx context.Context, p PaymentRequest, req TransferRequest) (PaymentRequest, error)
+}
+
+type PaymentRequestExpirer interface {
+	// ExpirePaymentRequests marks as expired the pending
+	// payment requests that expire before the given time.
+	// Returns:
+	// - list: the expired payment requests.
+	ExpirePaymentRequests(ctx context.Context, until time.Time) (list []PaymentRequest, err error)
+}
+
+type PaymentRequestRequest struct {
+	Requester User
+	// WalletID requester's wallet that will receive the funds.
+	WalletID      uuid.UUID
+	PayerWalletID uuid.UUID
+	Amount        float64
+	Message       *string
+	// ExpiresAt optional expiration date, DefaultPaymentRequestTTL from now by default.
+	ExpiresAt *time.Time
+}
+
+type PaymentRequestService struct {
+	WalletReader WalletReader
+	Repository   PaymentRequestRepository
+	// Observer optional observer of the transfers that pay the requests.
+	Observer TransferObserver
+	// Auditor optional audit log of the transfers that pay the requests.
+	Auditor Auditor
+}
+
+// CreatePaymentRequest requests funds from the owner of another wallet.
+func (svc *PaymentRequestService) CreatePaymentRequest(ctx context.Context, req PaymentRequestRequest) (p PaymentRequest, err error) {
+	if req.Amount <= 0 {
+		return p, ErrInvalidTransactionAmount
+	}
+	if req.WalletID == req.PayerWalletID {
+		return p, ErrSameTransferWallets
+	}
+	expiresAt := time.Now().Add(DefaultPaymentRequestTTL)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return p, ErrInvalidExpirationDate
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	w, err := svc.WalletReader.GetByID(ctx, req.WalletID)
+	if err != nil {
+		return p, fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if !req.Requester.CanWrite(w) {
+		return p, ErrWalletAccessDenied
+	}
+	payer, err := svc.WalletReader.GetByID(ctx, req.PayerWalletID)
+	if err != nil {
+		return p, fmt.Errorf("cannot get payer wallet: %w", err)
+	}
+
+	p, err = svc.Repository.CreatePaymentRequest(ctx, PaymentRequest{
+		RequesterID:       req.Requester.ID,
+		RequesterWalletID: req.WalletID,
+		PayerID:           payer.UserID,
+		PayerWalletID:     payer.ID,
+		Amount:            req.Amount,
+		Message:           req.Message,
+		Status:            PaymentRequestPending,
+		ExpiresAt:         expiresAt,
+	})
+	if err != nil {
+		return p, fmt.Errorf("cannot create payment request: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetPaymentRequest returns a payment request where the user is the requester or the payer.
+func (svc *PaymentRequestService) GetPaymentRequest(ctx context.Context, user User, requestID uuid.UUID) (p PaymentRequest, err error) {
+	p, err = svc.Repository.GetPaymentRequest(ctx, requestID)
+	if err != nil {
+		return p, fmt.Errorf("cannot get payment request: %w", err)
+	}
+	// hide the existence of the request to other users.
+	if p.RequesterID != user.ID && p.PayerID != user.ID {
+		return PaymentRequest{}, ErrPaymentRequestNotFound
+	}
+
+	return p, nil
+}
+
+// ListPaymentRequests returns the incoming or outgoing payment requests of the user.
+func (svc *PaymentRequestService) ListPaymentRequests(
+	ctx context.Context,
+	user User,
+	direction PaymentRequestDirection,
+	status *PaymentRequestStatus,
+) (l []PaymentRequest, err error) {
+	l, err = svc.Repository.ListPaymentRequests(ctx, user.ID, direction, status)
+	if err != nil {
+		return l, fmt.Errorf("cannot list payment requests: %w", err)
+	}
+
+	return l, nil
+}
+
+// AcceptPaymentRequest transfers the requested funds from the payer wallet.
+// The request is accepted in the same DB transaction as the transfer, so it
+// cannot be paid twice and it remains pending if the transfer fails.
+func (svc *PaymentRequestService) AcceptPaymentRequest(ctx context.Context, user User, requestID uuid.UUID) (p PaymentRequest, err error) {
+	ctx, end := startSpan(ctx, "PaymentRequestService.AcceptPaymentRequest")
+	defer end(&err)
+
+	p, err = svc.pendingRequest(ctx, user, requestID)
+	if err != nil {
+		return p, err
+	}
+
+	req := TransferRequest{
+		Issuer:              user,
+		OriginWalletID:      p.PayerWalletID,
+		DestinationWalletID: p.RequesterWalletID,
+		Amount:              p.Amount,
+		Message:             p.Message,
+	}
+	p, err = svc.Repository.AcceptPaymentRequest(ctx, p, req)
+
+	// the transfer is observed and audited like the ones of the wallet service.
+	if svc.Observer != nil {
+		svc.Observer.ObserveTransfer(req, err)
+	}
+	Audit(ctx, svc.Auditor, AuditEntry{
+		Actor:    user.ID.String(),
+		Action:   AuditTransfer,
+		Resource: walletResource(req.OriginWalletID),
+		Outcome:  auditOutcome(err),
+	})
+
+	if err != nil {
+		return p, fmt.Errorf("cannot accept payment request: %w", err)
+	}
+
+	return p, nil
+}
+
+// DeclinePaymentRequest rejects a pending payment request.
+func (svc *PaymentRequestService) DeclinePaymentRequest(ctx context.Context, user User, requestID uuid.UUID) (p PaymentRequest, err error) {
+	p, err = svc.pendingRequest(ctx, user, requestID)
+	if err != nil {
+		return p, err
+	}
+
+	p.Status = PaymentRequestDeclined
+	if p, err = svc.Repository.UpdatePaymentRequest(ctx, p, PaymentRequestPending); err != nil {
+		return p, fmt.Errorf("cannot decline payment request: %w", err)
+	}
+
+	return p, nil
+}
+
+// pendingRequest loads a payment request that the user can answer,
+// marking it as expired if the expiration date has passed.
+func (svc *PaymentRequestService) pendingRequest(ctx context.Context, user User, requestID uuid.UUID) (p PaymentRequest, err error) {
+	p, err = svc.GetPaymentRequest(ctx, user, requestID)
+	if err != nil {
+		return p, err
+	}
+	if p.PayerID != user.ID {
+		return p, ErrWalletAccessDenied
+	}
+	if p.Status != PaymentRequestPending {
+		return p, fmt.Errorf("%w: payment request is %s", ErrPaymentRequestNotPending, p.Status)
+	}
+	if !p.ExpiresAt.After(time.Now()) {
+		p.Status = PaymentRequestExpired
+		if p, err = svc.Repository.UpdatePaymentRequest(ctx, p, PaymentRequestPending); err != nil {
+			return p, fmt.Errorf("cannot expire payment request: %w", err)
+		}
+		return p, ErrPaymentRequestExpired
+	}
+
+	return p, nil
+}
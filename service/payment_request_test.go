@@ -0,0 +1,345 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreatePaymentRequest(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx           = context.Background()
+		dummyErr      = fmt.Errorf("dummy error")
+		requester     = service.User{ID: uuid.New()}
+		payerID       = uuid.New()
+		walletID      = uuid.New()
+		payerWalletID = uuid.New()
+		wallet        = service.Wallet{ID: walletID, UserID: requester.ID}
+		payerWallet   = service.Wallet{ID: payerWalletID, UserID: payerID}
+		expiresAt     = time.Now().Add(time.Hour).Round(0)
+		past          = time.Now().Add(-time.Hour)
+		request       = service.PaymentRequestRequest{
+			Requester:     requester,
+			WalletID:      walletID,
+			PayerWalletID: payerWalletID,
+			Amount:        10,
+			ExpiresAt:     &expiresAt,
+		}
+		pending = service.PaymentRequest{
+			RequesterID:       requester.ID,
+			RequesterWalletID: walletID,
+			PayerID:           payerID,
+			PayerWalletID:     payerWalletID,
+			Amount:            10,
+			Status:            service.PaymentRequestPending,
+			ExpiresAt:         expiresAt,
+		}
+		created = pending
+	)
+	created.ID = uuid.New()
+
+	tt := []struct {
+		name        string
+		req         service.PaymentRequestRequest
+		expect      func(*mocks.WalletReader, *mocks.PaymentRequestRepository)
+		wantRequest service.PaymentRequest
+		wantErr     error
+	}{
+		{
+			name:    "invalid amount",
+			req:     service.PaymentRequestRequest{Amount: -1},
+			wantErr: service.ErrInvalidTransactionAmount,
+		}, {
+			name:    "same wallets",
+			req:     service.PaymentRequestRequest{WalletID: walletID, PayerWalletID: walletID, Amount: 10},
+			wantErr: service.ErrSameTransferWallets,
+		}, {
+			name: "expiration date in the past",
+			req: service.PaymentRequestRequest{
+				WalletID:      walletID,
+				PayerWalletID: payerWalletID,
+				Amount:        10,
+				ExpiresAt:     &past,
+			},
+			wantErr: service.ErrInvalidExpirationDate,
+		}, {
+			name: "wallet access denied",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.PaymentRequestRepository) {
+				wr.On("GetByID", ctx, walletID).Return(service.Wallet{ID: walletID}, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "payer wallet not found",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.PaymentRequestRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				wr.On("GetByID", ctx, payerWalletID).Return(service.Wallet{}, service.ErrWalletNotFound)
+			},
+			wantErr: service.ErrWalletNotFound,
+		}, {
+			name: "repository error",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.PaymentRequestRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				wr.On("GetByID", ctx, payerWalletID).Return(payerWallet, nil)
+				r.On("CreatePaymentRequest", ctx, pending).Return(service.PaymentRequest{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "created",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.PaymentRequestRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				wr.On("GetByID", ctx, payerWalletID).Return(payerWallet, nil)
+				r.On("CreatePaymentRequest", ctx, pending).Return(created, nil)
+			},
+			wantRequest: created,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			r := &mocks.PaymentRequestRepository{}
+			if tc.expect != nil {
+				tc.expect(wr, r)
+			}
+
+			svc := service.PaymentRequestService{
+				WalletReader: wr,
+				Repository:   r,
+			}
+
+			got, err := svc.CreatePaymentRequest(ctx, tc.req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantRequest) {
+				t.Fatalf("unexpected payment request: \n got:  %+v \n want: %+v", got, tc.wantRequest)
+			}
+		})
+	}
+}
+
+func TestAcceptPaymentRequest(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx       = context.Background()
+		dummyErr  = fmt.Errorf("dummy error")
+		payer     = service.User{ID: uuid.New()}
+		requestID = uuid.New()
+		pending   = service.PaymentRequest{
+			ID:                requestID,
+			RequesterID:       uuid.New(),
+			RequesterWalletID: uuid.New(),
+			PayerID:           payer.ID,
+			PayerWalletID:     uuid.New(),
+			Amount:            10,
+			Status:            service.PaymentRequestPending,
+			ExpiresAt:         time.Now().Add(time.Hour),
+		}
+		transfer = service.Transfer{ID: uuid.New()}
+	)
+
+	with := func(status service.PaymentRequestStatus, expiresAt time.Time, transferID *uuid.UUID) service.PaymentRequest {
+		p := pending
+		p.Status = status
+		p.ExpiresAt = expiresAt
+		p.TransferID = transferID
+		return p
+	}
+	var (
+		paid        = with(service.PaymentRequestAccepted, pending.ExpiresAt, &transfer.ID)
+		declined    = with(service.PaymentRequestDeclined, pending.ExpiresAt, nil)
+		overdue     = with(service.PaymentRequestPending, time.Now().Add(-time.Hour), nil)
+		expired     = with(service.PaymentRequestExpired, overdue.ExpiresAt, nil)
+		transferReq = service.TransferRequest{
+			Issuer:              payer,
+			OriginWalletID:      pending.PayerWalletID,
+			DestinationWalletID: pending.RequesterWalletID,
+			Amount:              pending.Amount,
+		}
+	)
+
+	tt := []struct {
+		name        string
+		user        service.User
+		expect      func(*mocks.PaymentRequestRepository)
+		transfer    bool
+		wantRequest service.PaymentRequest
+		wantErr     error
+	}{
+		{
+			name: "not found",
+			user: payer,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(service.PaymentRequest{}, service.ErrPaymentRequestNotFound)
+			},
+			wantErr: service.ErrPaymentRequestNotFound,
+		}, {
+			name: "another user",
+			user: service.User{ID: uuid.New()},
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+			},
+			wantErr: service.ErrPaymentRequestNotFound,
+		}, {
+			name: "requester cannot accept",
+			user: service.User{ID: pending.RequesterID},
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "not pending",
+			user: payer,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(declined, nil)
+			},
+			wantErr: service.ErrPaymentRequestNotPending,
+		}, {
+			name: "expired",
+			user: payer,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(overdue, nil)
+				r.On("UpdatePaymentRequest", anyCtx, expired, service.PaymentRequestPending).Return(expired, nil)
+			},
+			wantErr: service.ErrPaymentRequestExpired,
+		}, {
+			name:     "answered concurrently",
+			user:     payer,
+			transfer: true,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+				r.On("AcceptPaymentRequest", anyCtx, pending, transferReq).
+					Return(service.PaymentRequest{}, service.ErrPaymentRequestNotPending)
+			},
+			wantErr: service.ErrPaymentRequestNotPending,
+		}, {
+			name:     "expired before accepting",
+			user:     payer,
+			transfer: true,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+				r.On("AcceptPaymentRequest", anyCtx, pending, transferReq).
+					Return(service.PaymentRequest{}, service.ErrPaymentRequestExpired)
+			},
+			wantErr: service.ErrPaymentRequestExpired,
+		}, {
+			name:     "transfer error",
+			user:     payer,
+			transfer: true,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+				r.On("AcceptPaymentRequest", anyCtx, pending, transferReq).Return(pending, service.ErrInsufficientFunds)
+			},
+			wantErr: service.ErrInsufficientFunds,
+		}, {
+			name:     "repository error",
+			user:     payer,
+			transfer: true,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+				r.On("AcceptPaymentRequest", anyCtx, pending, transferReq).Return(pending, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name:     "accepted",
+			user:     payer,
+			transfer: true,
+			expect: func(r *mocks.PaymentRequestRepository) {
+				r.On("GetPaymentRequest", anyCtx, requestID).Return(pending, nil)
+				r.On("AcceptPaymentRequest", anyCtx, pending, transferReq).Return(paid, nil)
+			},
+			wantRequest: paid,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := &mocks.PaymentRequestRepository{}
+			tc.expect(r)
+			o := &mocks.TransferObserver{}
+			a := &mocks.Auditor{}
+			if tc.transfer {
+				outcome := service.AuditSuccess
+				if tc.wantErr != nil {
+					outcome = service.AuditFailure
+				}
+				o.On("ObserveTransfer", transferReq, mock.MatchedBy(func(err error) bool {
+					return errors.Is(err, tc.wantErr)
+				})).Once()
+				a.On("Audit", anyCtx, service.AuditEntry{
+					Actor:    payer.ID.String(),
+					Action:   service.AuditTransfer,
+					Resource: "wallet:" + pending.PayerWalletID.String(),
+					Outcome:  outcome,
+				}).Return(nil).Once()
+			}
+
+			svc := service.PaymentRequestService{
+				Repository: r,
+				Observer:   o,
+				Auditor:    a,
+			}
+
+			got, err := svc.AcceptPaymentRequest(ctx, tc.user, requestID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantRequest) {
+				t.Fatalf("unexpected payment request: \n got:  %+v \n want: %+v", got, tc.wantRequest)
+			}
+			o.AssertExpectations(t)
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeclinePaymentRequest(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx       = context.Background()
+		payer     = service.User{ID: uuid.New()}
+		requestID = uuid.New()
+		pending   = service.PaymentRequest{
+			ID:        requestID,
+			PayerID:   payer.ID,
+			Status:    service.PaymentRequestPending,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		declined = pending
+	)
+	declined.Status = service.PaymentRequestDeclined
+
+	r := &mocks.PaymentRequestRepository{}
+	r.On("GetPaymentRequest", ctx, requestID).Return(pending, nil)
+	r.On("UpdatePaymentRequest", ctx, mock.Anything, service.PaymentRequestPending).
+		Return(func(_ context.Context, p service.PaymentRequest, _ service.PaymentRequestStatus) service.PaymentRequest {
+			return p
+		}, nil)
+
+	svc := service.PaymentRequestService{
+		Repository: r,
+	}
+
+	got, err := svc.DeclinePaymentRequest(ctx, payer, requestID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, declined) {
+		t.Fatalf("unexpected payment request: \n got:  %+v \n want: %+v", got, declined)
+	}
+}
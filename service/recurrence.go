@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+)
+
+// Recurrence rule of a standing order, the days and
+// the time of the executions are calculated in UTC.
+type Recurrence struct {
+	// Frequency of the executions: daily, weekly or monthly.
+	Frequency Frequency `json:"frequency"`
+	// DayOfMonth day of the execution for monthly recurrences,
+	// it's clamped to the last day on shorter months.
+	// Defaults to the day of the start date.
+	DayOfMonth int `json:"day_of_month,omitempty"`
+	// StartDate date of the first execution, the time of
+	// the day is used for all the following executions.
+	StartDate time.Time `json:"start_date"`
+	// EndDate optional date after which there are no more executions.
+	EndDate *time.Time `json:"end_date"`
+	// Count optional maximum number of executions.
+	Count *int `json:"count"`
+}
+
+// UTC returns the recurrence with the dates in UTC, the same dates
+// that are loaded from the DB to calculate the following executions.
+func (r Recurrence) UTC() Recurrence {
+	r.StartDate = r.StartDate.UTC()
+	if r.EndDate != nil {
+		end := r.EndDate.UTC()
+		r.EndDate = &end
+	}
+	return r
+}
+
+// Validate checks the recurrence rule is valid.
+func (r Recurrence) Validate() error {
+	switch r.Frequency {
+	case Daily, Weekly:
+		if r.DayOfMonth != 0 {
+			return fmt.Errorf("%w: day of month is only valid for monthly recurrences", ErrInvalidRecurrence)
+		}
+	case Monthly:
+		if r.DayOfMonth < 0 || r.DayOfMonth > 31 {
+			return fmt.Errorf("%w: day of month must be between 1 and 31", ErrInvalidRecurrence)
+		}
+	default:
+		return fmt.Errorf("%w: %q is not a valid frequency. Valid values: daily, weekly, monthly", ErrInvalidRecurrence, r.Frequency)
+	}
+	if r.StartDate.IsZero() {
+		return fmt.Errorf("%w: missing start date", ErrInvalidRecurrence)
+	}
+	if r.EndDate != nil && r.EndDate.Before(r.StartDate) {
+		return fmt.Errorf("%w: end date before start date", ErrInvalidRecurrence)
+	}
+	if r.Count != nil && *r.Count <= 0 {
+		return fmt.Errorf("%w: count must be greater than zero", ErrInvalidRecurrence)
+	}
+
+	return nil
+}
+
+// Occurrence returns the date of the nth execution, starting at zero.
+// Returns false if the recurrence has ended before that execution.
+func (r Recurrence) Occurrence(n int) (time.Time, bool) {
+	if n < 0 || (r.Count != nil && n >= *r.Count) {
+		return time.Time{}, false
+	}
+
+	var d time.Time
+	switch r.Frequency {
+	case Daily:
+		d = r.StartDate.AddDate(0, 0, n)
+	case Weekly:
+		d = r.StartDate.AddDate(0, 0, 7*n)
+	case Monthly:
+		d = r.monthlyOccurrence(n)
+	default:
+		return time.Time{}, false
+	}
+
+	if r.EndDate != nil && d.After(*r.EndDate) {
+		return time.Time{}, false
+	}
+
+	return d, true
+}
+
+// monthlyOccurrence returns the nth monthly execution, it's calculated
+// from the start date instead of the previous execution so a clamped
+// day (In. ex. 31 of January to 28 of February) does not shift the
+// day of the following months.
+func (r Recurrence) monthlyOccurrence(n int) time.Time {
+	s := r.StartDate
+	day := r.DayOfMonth
+	if day == 0 {
+		day = s.Day()
+	}
+
+	// The first execution is in the next month if
+	// the day has already passed in the start month.
+	year, month := s.Year(), s.Month()
+	if clampDay(year, month, day) < s.Day() {
+		n++
+	}
+
+	// Normalize the month before clamping the day, using
+	// the first day of the month to avoid overflows.
+	first := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, s.Location())
+	year, month = first.Year(), first.Month()
+
+	return time.Date(year, month, clampDay(year, month, day), s.Hour(), s.Minute(), s.Second(), s.Nanosecond(), s.Location())
+}
+
+// clampDay returns the given day or the last day of the month if it's shorter.
+func clampDay(year int, month time.Month, day int) int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		return last
+	}
+	return day
+}
@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+)
+
+func TestRecurrenceValidate(t *testing.T) {
+	t.Parallel()
+	var (
+		start  = time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC)
+		before = start.Add(-time.Hour)
+		zero   = 0
+	)
+	tt := []struct {
+		name       string
+		recurrence service.Recurrence
+		wantErr    error
+	}{
+		{
+			name:       "invalid frequency",
+			recurrence: service.Recurrence{Frequency: "yearly", StartDate: start},
+			wantErr:    service.ErrInvalidRecurrence,
+		}, {
+			name:       "day of month on weekly recurrence",
+			recurrence: service.Recurrence{Frequency: service.Weekly, DayOfMonth: 3, StartDate: start},
+			wantErr:    service.ErrInvalidRecurrence,
+		}, {
+			name:       "invalid day of month",
+			recurrence: service.Recurrence{Frequency: service.Monthly, DayOfMonth: 32, StartDate: start},
+			wantErr:    service.ErrInvalidRecurrence,
+		}, {
+			name:       "missing start date",
+			recurrence: service.Recurrence{Frequency: service.Daily},
+			wantErr:    service.ErrInvalidRecurrence,
+		}, {
+			name:       "end date before start",
+			recurrence: service.Recurrence{Frequency: service.Daily, StartDate: start, EndDate: &before},
+			wantErr:    service.ErrInvalidRecurrence,
+		}, {
+			name:       "invalid count",
+			recurrence: service.Recurrence{Frequency: service.Daily, StartDate: start, Count: &zero},
+			wantErr:    service.ErrInvalidRecurrence,
+		}, {
+			name:       "valid",
+			recurrence: service.Recurrence{Frequency: service.Monthly, DayOfMonth: 31, StartDate: start},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.recurrence.Validate(); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrence(t *testing.T) {
+	t.Parallel()
+	var (
+		date = func(year int, month time.Month, day int) time.Time {
+			return time.Date(year, month, day, 10, 0, 0, 0, time.UTC)
+		}
+		two = 2
+		end = date(2020, 3, 31)
+	)
+	tt := []struct {
+		name       string
+		recurrence service.Recurrence
+		want       []time.Time
+	}{
+		{
+			name:       "daily",
+			recurrence: service.Recurrence{Frequency: service.Daily, StartDate: date(2020, 2, 28)},
+			want:       []time.Time{date(2020, 2, 28), date(2020, 2, 29), date(2020, 3, 1)},
+		}, {
+			name:       "weekly",
+			recurrence: service.Recurrence{Frequency: service.Weekly, StartDate: date(2020, 12, 24)},
+			want:       []time.Time{date(2020, 12, 24), date(2020, 12, 31), date(2021, 1, 7)},
+		}, {
+			name:       "monthly with day clamping",
+			recurrence: service.Recurrence{Frequency: service.Monthly, StartDate: date(2020, 1, 31)},
+			want:       []time.Time{date(2020, 1, 31), date(2020, 2, 29), date(2020, 3, 31), date(2020, 4, 30)},
+		}, {
+			name:       "monthly day already passed in the start month",
+			recurrence: service.Recurrence{Frequency: service.Monthly, DayOfMonth: 1, StartDate: date(2020, 11, 15)},
+			want:       []time.Time{date(2020, 12, 1), date(2021, 1, 1), date(2021, 2, 1)},
+		}, {
+			name:       "monthly clamped day in the start month",
+			recurrence: service.Recurrence{Frequency: service.Monthly, DayOfMonth: 31, StartDate: date(2021, 2, 28)},
+			want:       []time.Time{date(2021, 2, 28), date(2021, 3, 31), date(2021, 4, 30)},
+		}, {
+			name:       "limited by count",
+			recurrence: service.Recurrence{Frequency: service.Daily, StartDate: date(2020, 1, 1), Count: &two},
+			want:       []time.Time{date(2020, 1, 1), date(2020, 1, 2)},
+		}, {
+			name:       "limited by end date",
+			recurrence: service.Recurrence{Frequency: service.Monthly, StartDate: date(2020, 1, 31), EndDate: &end},
+			want:       []time.Time{date(2020, 1, 31), date(2020, 2, 29), date(2020, 3, 31)},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			for i, want := range tc.want {
+				got, ok := tc.recurrence.Occurrence(i)
+				if !ok {
+					t.Fatalf("unexpected end of recurrence at occurrence %d", i)
+				}
+				if !got.Equal(want) {
+					t.Fatalf("unexpected occurrence %d: \n got:  %v \n want: %v", i, got, want)
+				}
+			}
+			if tc.recurrence.Count == nil && tc.recurrence.EndDate == nil {
+				return
+			}
+			if got, ok := tc.recurrence.Occurrence(len(tc.want)); ok {
+				t.Fatalf("unexpected occurrence after the end of recurrence: %v", got)
+			}
+		})
+	}
+}
+
+func TestRecurrenceUTC(t *testing.T) {
+	t.Parallel()
+	var (
+		zone  = time.FixedZone("UTC+2", 2*60*60)
+		start = time.Date(2026, 11, 1, 0, 30, 0, 0, zone)
+		end   = time.Date(2027, 3, 1, 0, 30, 0, 0, zone)
+		r     = service.Recurrence{Frequency: service.Monthly, DayOfMonth: 1, StartDate: start, EndDate: &end}
+	)
+
+	got := r.UTC()
+	if got.StartDate.Location() != time.UTC || got.EndDate.Location() != time.UTC {
+		t.Fatalf("unexpected recurrence zones: %v, %v", got.StartDate, got.EndDate)
+	}
+	if !got.StartDate.Equal(start) || !got.EndDate.Equal(end) {
+		t.Fatalf("unexpected recurrence dates: %v, %v", got.StartDate, got.EndDate)
+	}
+	if r.EndDate.Location() != zone {
+		t.Fatalf("the original recurrence has been modified: %v", r.EndDate)
+	}
+
+	// the start is the 31st of October in UTC, so the first execution is on the 1st of November.
+	want := time.Date(2026, 11, 1, 22, 30, 0, 0, time.UTC)
+	if next, ok := got.Occurrence(0); !ok || !next.Equal(want) {
+		t.Fatalf("unexpected first occurrence: got: %v, want: %v", next, want)
+	}
+}
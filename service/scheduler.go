@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const DefaultSchedulerInterval = time.Minute
+
+type ScheduledTransferExecutor interface {
+	// ExecuteScheduledTransfers executes the scheduled transfers that
+	// are due at the given time. A transfer that cannot be executed,
+	// for example due ErrInsufficientFunds, is marked as failed.
+	// Returns:
+	// - list: the processed transfers, both completed and failed.
+	ExecuteScheduledTransfers(ctx context.Context, until time.Time) (list []Transfer, err error)
+}
+
+// TransferScheduleWorker executes periodically the
+// scheduled transfers once they are due.
+type TransferScheduleWorker struct {
+	Executor ScheduledTransferExecutor
+	// Interval time between executions, by default one minute.
+	Interval time.Duration
+}
+
+// Run executes the due transfers until the context is done.
+func (w *TransferScheduleWorker) Run(ctx context.Context) {
+	runEvery(ctx, w.Interval, w.execute)
+}
+
+func (w *TransferScheduleWorker) execute(ctx context.Context) {
+	list, err := w.Executor.ExecuteScheduledTransfers(ctx, time.Now())
+	for _, t := range list {
+		e := log.Info()
+		if t.Status == TransferFailed {
+			e = log.Warn()
+		}
+		e.Str("transfer_id", t.ID.String()).
+			Str("status", string(t.Status)).
+			Msg("scheduled transfer executed")
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msg("cannot execute scheduled transfers")
+	}
+}
+
+// StandingOrderWorker executes periodically
+// the standing orders once they are due.
+type StandingOrderWorker struct {
+	Executor StandingOrderExecutor
+	// Interval time between executions, by default one minute.
+	Interval time.Duration
+}
+
+// Run executes the due standing orders until the context is done.
+func (w *StandingOrderWorker) Run(ctx context.Context) {
+	runEvery(ctx, w.Interval, w.execute)
+}
+
+func (w *StandingOrderWorker) execute(ctx context.Context) {
+	list, err := w.Executor.ExecuteStandingOrders(ctx, time.Now())
+	for _, r := range list {
+		e := log.Info()
+		if r.Status == TransferFailed {
+			e = log.Warn()
+		}
+		e.Str("standing_order_id", r.StandingOrderID.String()).
+			Str("status", string(r.Status)).
+			Msg("standing order executed")
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msg("cannot execute standing orders")
+	}
+}
+
+// PaymentRequestExpiryWorker marks periodically as
+// expired the payment requests that were not answered.
+type PaymentRequestExpiryWorker struct {
+	Expirer PaymentRequestExpirer
+	// Interval time between executions, by default one minute.
+	Interval time.Duration
+}
+
+// Run expires the payment requests until the context is done.
+func (w *PaymentRequestExpiryWorker) Run(ctx context.Context) {
+	runEvery(ctx, w.Interval, w.execute)
+}
+
+func (w *PaymentRequestExpiryWorker) execute(ctx context.Context) {
+	list, err := w.Expirer.ExpirePaymentRequests(ctx, time.Now())
+	for _, p := range list {
+		log.Info().
+			Str("payment_request_id", p.ID.String()).
+			Msg("payment request expired")
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msg("cannot expire payment requests")
+	}
+}
+
+// runEvery calls the function immediately and then
+// every interval until the context is done.
+func runEvery(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	if interval <= 0 {
+		interval = DefaultSchedulerInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		fn(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	m.Run()
+}
+
+func TestTransferScheduleWorker(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name string
+		list []service.Transfer
+		err  error
+	}{
+		{
+			name: "executor error",
+			err:  fmt.Errorf("dummy error"),
+		}, {
+			name: "transfers executed",
+			list: []service.Transfer{
+				{Status: service.TransferCompleted},
+				{Status: service.TransferFailed},
+			},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			executed := make(chan struct{})
+			e := &mocks.ScheduledTransferExecutor{}
+			e.On("ExecuteScheduledTransfers", mock.Anything, mock.AnythingOfType("time.Time")).
+				Return(tc.list, tc.err).
+				Run(func(mock.Arguments) {
+					select {
+					case executed <- struct{}{}:
+					default:
+					}
+				})
+
+			w := service.TransferScheduleWorker{
+				Executor: e,
+				Interval: time.Millisecond,
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				w.Run(ctx)
+			}()
+
+			select {
+			case <-executed:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the scheduled transfers execution")
+			}
+
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the worker to stop")
+			}
+		})
+	}
+}
+
+func TestStandingOrderWorker(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executed := make(chan struct{})
+	e := &mocks.StandingOrderExecutor{}
+	e.On("ExecuteStandingOrders", mock.Anything, mock.AnythingOfType("time.Time")).
+		Return([]service.StandingOrderRun{
+			{Status: service.TransferCompleted},
+			{Status: service.TransferFailed},
+		}, nil).
+		Run(func(mock.Arguments) {
+			select {
+			case executed <- struct{}{}:
+			default:
+			}
+		})
+
+	w := service.StandingOrderWorker{
+		Executor: e,
+		Interval: time.Millisecond,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the standing orders execution")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the worker to stop")
+	}
+}
+
+func TestPaymentRequestExpiryWorker(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executed := make(chan struct{})
+	e := &mocks.PaymentRequestExpirer{}
+	e.On("ExpirePaymentRequests", mock.Anything, mock.AnythingOfType("time.Time")).
+		Return([]service.PaymentRequest{{Status: service.PaymentRequestExpired}}, nil).
+		Run(func(mock.Arguments) {
+			select {
+			case executed <- struct{}{}:
+			default:
+			}
+		})
+
+	w := service.PaymentRequestExpiryWorker{
+		Expirer:  e,
+		Interval: time.Millisecond,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the payment requests expiration")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the worker to stop")
+	}
+}
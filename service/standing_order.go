@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrStandingOrderNotFound  = errors.New("standing order not found")
+	ErrStandingOrderNotActive = errors.New("standing order is not active")
+)
+
+type StandingOrderRepository interface {
+	// CreateStandingOrder stores a new standing order.
+	CreateStandingOrder(ctx context.Context, o StandingOrder) (StandingOrder, error)
+
+	// GetStandingOrder returns a standing order of the wallet.
+	// Errors:
+	// - ErrStandingOrderNotFound: if the wallet does not have the standing order.
+	GetStandingOrder(ctx context.Context, walletID, orderID uuid.UUID) (StandingOrder, error)
+
+	// ListStandingOrders returns the standing orders of the wallet ordered by creation date.
+	ListStandingOrders(ctx context.Context, walletID uuid.UUID) ([]StandingOrder, error)
+
+	// UpdateStandingOrder updates the amount and the message of an active standing order.
+	// Errors:
+	// - ErrStandingOrderNotActive: if the standing order does not exists or is not active.
+	UpdateStandingOrder(ctx context.Context, orderID uuid.UUID, upd StandingOrderUpdate) (StandingOrder, error)
+
+	// CancelStandingOrder cancels an active standing order.
+	// Errors:
+	// - ErrStandingOrderNotActive: if the standing order does not exists or is not active.
+	CancelStandingOrder(ctx context.Context, orderID uuid.UUID) (StandingOrder, error)
+
+	// ListStandingOrderRuns returns the executions of a standing order ordered by date.
+	ListStandingOrderRuns(ctx context.Context, orderID uuid.UUID) ([]StandingOrderRun, error)
+}
+
+type StandingOrderExecutor interface {
+	// ExecuteStandingOrders creates the transfers of the active standing orders
+	// that are due at the given time. Failed executions, for example due
+	// ErrInsufficientFunds, are stored in the history of the standing order.
+	// Returns:
+	// - list: the executions, both completed and failed.
+	ExecuteStandingOrders(ctx context.Context, until time.Time) (list []StandingOrderRun, err error)
+}
+
+type StandingOrderRequest struct {
+	Issuer              User
+	OriginWalletID      uuid.UUID
+	DestinationWalletID uuid.UUID
+	Amount              float64
+	Message             *string
+	Recurrence          Recurrence
+}
+
+// StandingOrderUpdate contains the fields to update, nil values are ignored.
+type StandingOrderUpdate struct {
+	Amount  *float64
+	Message *string
+}
+
+type StandingOrderService struct {
+	WalletReader WalletReader
+	Repository   StandingOrderRepository
+}
+
+// CreateStandingOrder creates a standing order to transfer funds periodically.
+func (svc *StandingOrderService) CreateStandingOrder(ctx context.Context, req StandingOrderRequest) (o StandingOrder, err error) {
+	if req.Amount <= 0 {
+		return o, ErrInvalidTransactionAmount
+	}
+	if req.DestinationWalletID == req.OriginWalletID {
+		return o, ErrSameTransferWallets
+	}
+	// The first execution is calculated in UTC like the rest of them.
+	req.Recurrence = req.Recurrence.UTC()
+	if err = req.Recurrence.Validate(); err != nil {
+		return o, err
+	}
+	if !req.Recurrence.StartDate.After(time.Now()) {
+		return o, fmt.Errorf("%w: start date must be in the future", ErrInvalidRecurrence)
+	}
+	next, ok := req.Recurrence.Occurrence(0)
+	if !ok {
+		return o, fmt.Errorf("%w: the recurrence has no executions", ErrInvalidRecurrence)
+	}
+
+	if _, err = svc.wallet(ctx, req.Issuer, req.OriginWalletID, true); err != nil {
+		return o, err
+	}
+	if _, err = svc.WalletReader.GetByID(ctx, req.DestinationWalletID); err != nil {
+		return o, fmt.Errorf("cannot get destination wallet: %w", err)
+	}
+
+	o, err = svc.Repository.CreateStandingOrder(ctx, StandingOrder{
+		IssuerID:            req.Issuer.ID,
+		OriginWalletID:      req.OriginWalletID,
+		DestinationWalletID: req.DestinationWalletID,
+		Amount:              req.Amount,
+		Message:             req.Message,
+		Recurrence:          req.Recurrence,
+		Status:              StandingOrderActive,
+		NextRunAt:           &next,
+	})
+	if err != nil {
+		return o, fmt.Errorf("cannot create standing order: %w", err)
+	}
+
+	return o, nil
+}
+
+// GetStandingOrder returns a standing order of the wallet.
+func (svc *StandingOrderService) GetStandingOrder(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	orderID uuid.UUID,
+) (o StandingOrder, err error) {
+	if _, err = svc.wallet(ctx, user, walletID, false); err != nil {
+		return o, err
+	}
+
+	o, err = svc.Repository.GetStandingOrder(ctx, walletID, orderID)
+	if err != nil {
+		return o, fmt.Errorf("cannot get standing order: %w", err)
+	}
+
+	return o, nil
+}
+
+// ListStandingOrders returns the standing orders of the wallet.
+func (svc *StandingOrderService) ListStandingOrders(ctx context.Context, user User, walletID uuid.UUID) (l []StandingOrder, err error) {
+	if _, err = svc.wallet(ctx, user, walletID, false); err != nil {
+		return l, err
+	}
+
+	l, err = svc.Repository.ListStandingOrders(ctx, walletID)
+	if err != nil {
+		return l, fmt.Errorf("cannot list standing orders: %w", err)
+	}
+
+	return l, nil
+}
+
+// UpdateStandingOrder updates the amount or the message of an active standing order.
+func (svc *StandingOrderService) UpdateStandingOrder(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	orderID uuid.UUID,
+	upd StandingOrderUpdate,
+) (o StandingOrder, err error) {
+	if upd.Amount != nil && *upd.Amount <= 0 {
+		return o, ErrInvalidTransactionAmount
+	}
+
+	if _, err = svc.activeOrder(ctx, user, walletID, orderID); err != nil {
+		return o, err
+	}
+
+	o, err = svc.Repository.UpdateStandingOrder(ctx, orderID, upd)
+	if err != nil {
+		return o, fmt.Errorf("cannot update standing order: %w", err)
+	}
+
+	return o, nil
+}
+
+// CancelStandingOrder cancels the following executions of a standing order.
+func (svc *StandingOrderService) CancelStandingOrder(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	orderID uuid.UUID,
+) (o StandingOrder, err error) {
+	if _, err = svc.activeOrder(ctx, user, walletID, orderID); err != nil {
+		return o, err
+	}
+
+	o, err = svc.Repository.CancelStandingOrder(ctx, orderID)
+	if err != nil {
+		return o, fmt.Errorf("cannot cancel standing order: %w", err)
+	}
+
+	return o, nil
+}
+
+// ListStandingOrderRuns returns the execution history of a standing order.
+func (svc *StandingOrderService) ListStandingOrderRuns(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	orderID uuid.UUID,
+) (l []StandingOrderRun, err error) {
+	o, err := svc.GetStandingOrder(ctx, user, walletID, orderID)
+	if err != nil {
+		return l, err
+	}
+
+	l, err = svc.Repository.ListStandingOrderRuns(ctx, o.ID)
+	if err != nil {
+		return l, fmt.Errorf("cannot list standing order runs: %w", err)
+	}
+
+	return l, nil
+}
+
+// activeOrder loads a standing order that the user can modify.
+func (svc *StandingOrderService) activeOrder(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	orderID uuid.UUID,
+) (o StandingOrder, err error) {
+	if _, err = svc.wallet(ctx, user, walletID, true); err != nil {
+		return o, err
+	}
+
+	o, err = svc.Repository.GetStandingOrder(ctx, walletID, orderID)
+	if err != nil {
+		return o, fmt.Errorf("cannot get standing order: %w", err)
+	}
+	if o.Status != StandingOrderActive {
+		return o, fmt.Errorf("%w: standing order is %s", ErrStandingOrderNotActive, o.Status)
+	}
+
+	return o, nil
+}
+
+// wallet loads the wallet checking the user access.
+func (svc *StandingOrderService) wallet(ctx context.Context, user User, walletID uuid.UUID, write bool) (w Wallet, err error) {
+	w, err = svc.WalletReader.GetByID(ctx, walletID)
+	if err != nil {
+		return w, fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if (write && !user.CanWrite(w)) || (!write && !user.CanRead(w)) {
+		return w, ErrWalletAccessDenied
+	}
+
+	return w, nil
+}
@@ -0,0 +1,278 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+)
+
+func TestCreateStandingOrder(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx           = context.Background()
+		dummyErr      = fmt.Errorf("dummy error")
+		user          = service.User{ID: uuid.New()}
+		originID      = uuid.New()
+		destinationID = uuid.New()
+		origin        = service.Wallet{ID: originID, UserID: user.ID}
+		destination   = service.Wallet{ID: destinationID}
+		start         = time.Now().Add(time.Hour).Round(0).UTC()
+		past          = time.Now().Add(-time.Hour)
+		zone          = time.FixedZone("UTC+2", 2*60*60)
+		recurrence    = service.Recurrence{Frequency: service.Weekly, StartDate: start}
+		request       = service.StandingOrderRequest{
+			Issuer:              user,
+			OriginWalletID:      originID,
+			DestinationWalletID: destinationID,
+			Amount:              10,
+			// the order is stored in UTC.
+			Recurrence: service.Recurrence{Frequency: service.Weekly, StartDate: start.In(zone)},
+		}
+		order = service.StandingOrder{
+			IssuerID:            user.ID,
+			OriginWalletID:      originID,
+			DestinationWalletID: destinationID,
+			Amount:              10,
+			Recurrence:          recurrence,
+			Status:              service.StandingOrderActive,
+			NextRunAt:           &start,
+		}
+		created = order
+	)
+	created.ID = uuid.New()
+
+	withRecurrence := func(r service.Recurrence) service.StandingOrderRequest {
+		req := request
+		req.Recurrence = r
+		return req
+	}
+
+	tt := []struct {
+		name      string
+		req       service.StandingOrderRequest
+		expect    func(*mocks.WalletReader, *mocks.StandingOrderRepository)
+		wantOrder service.StandingOrder
+		wantErr   error
+	}{
+		{
+			name:    "invalid amount",
+			req:     service.StandingOrderRequest{Amount: 0},
+			wantErr: service.ErrInvalidTransactionAmount,
+		}, {
+			name: "same wallets",
+			req: service.StandingOrderRequest{
+				OriginWalletID:      originID,
+				DestinationWalletID: originID,
+				Amount:              10,
+			},
+			wantErr: service.ErrSameTransferWallets,
+		}, {
+			name:    "invalid recurrence",
+			req:     withRecurrence(service.Recurrence{Frequency: "yearly", StartDate: start}),
+			wantErr: service.ErrInvalidRecurrence,
+		}, {
+			name:    "start date in the past",
+			req:     withRecurrence(service.Recurrence{Frequency: service.Daily, StartDate: past}),
+			wantErr: service.ErrInvalidRecurrence,
+		}, {
+			name: "origin access denied",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, originID).Return(service.Wallet{ID: originID}, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "destination not found",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, originID).Return(origin, nil)
+				wr.On("GetByID", ctx, destinationID).Return(service.Wallet{}, service.ErrWalletNotFound)
+			},
+			wantErr: service.ErrWalletNotFound,
+		}, {
+			name: "repository error",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, originID).Return(origin, nil)
+				wr.On("GetByID", ctx, destinationID).Return(destination, nil)
+				r.On("CreateStandingOrder", ctx, order).Return(service.StandingOrder{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "created",
+			req:  request,
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, originID).Return(origin, nil)
+				wr.On("GetByID", ctx, destinationID).Return(destination, nil)
+				r.On("CreateStandingOrder", ctx, order).Return(created, nil)
+			},
+			wantOrder: created,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			r := &mocks.StandingOrderRepository{}
+			if tc.expect != nil {
+				tc.expect(wr, r)
+			}
+
+			svc := service.StandingOrderService{
+				WalletReader: wr,
+				Repository:   r,
+			}
+
+			got, err := svc.CreateStandingOrder(ctx, tc.req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantOrder) {
+				t.Fatalf("unexpected standing order: \n got:  %+v \n want: %+v", got, tc.wantOrder)
+			}
+		})
+	}
+}
+
+func TestUpdateStandingOrder(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		user     = service.User{ID: uuid.New()}
+		walletID = uuid.New()
+		orderID  = uuid.New()
+		wallet   = service.Wallet{ID: walletID, UserID: user.ID}
+		amount   = 20.0
+		negative = -1.0
+		order    = service.StandingOrder{ID: orderID, OriginWalletID: walletID, Amount: 10, Status: service.StandingOrderActive}
+		updated  = service.StandingOrder{ID: orderID, OriginWalletID: walletID, Amount: 20, Status: service.StandingOrderActive}
+		finished = service.StandingOrder{ID: orderID, OriginWalletID: walletID, Amount: 10, Status: service.StandingOrderFinished}
+	)
+	tt := []struct {
+		name      string
+		user      service.User
+		upd       service.StandingOrderUpdate
+		expect    func(*mocks.WalletReader, *mocks.StandingOrderRepository)
+		wantOrder service.StandingOrder
+		wantErr   error
+	}{
+		{
+			name:    "invalid amount",
+			user:    user,
+			upd:     service.StandingOrderUpdate{Amount: &negative},
+			wantErr: service.ErrInvalidTransactionAmount,
+		}, {
+			name: "access denied",
+			user: service.User{ID: uuid.New()},
+			upd:  service.StandingOrderUpdate{Amount: &amount},
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "standing order not found",
+			user: user,
+			upd:  service.StandingOrderUpdate{Amount: &amount},
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				r.On("GetStandingOrder", ctx, walletID, orderID).Return(service.StandingOrder{}, service.ErrStandingOrderNotFound)
+			},
+			wantErr: service.ErrStandingOrderNotFound,
+		}, {
+			name: "standing order not active",
+			user: user,
+			upd:  service.StandingOrderUpdate{Amount: &amount},
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				r.On("GetStandingOrder", ctx, walletID, orderID).Return(finished, nil)
+			},
+			wantErr: service.ErrStandingOrderNotActive,
+		}, {
+			name: "updated",
+			user: user,
+			upd:  service.StandingOrderUpdate{Amount: &amount},
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				r.On("GetStandingOrder", ctx, walletID, orderID).Return(order, nil)
+				r.On("UpdateStandingOrder", ctx, orderID, service.StandingOrderUpdate{Amount: &amount}).Return(updated, nil)
+			},
+			wantOrder: updated,
+		}, {
+			name: "standing order finished meanwhile",
+			user: user,
+			upd:  service.StandingOrderUpdate{Amount: &amount},
+			expect: func(wr *mocks.WalletReader, r *mocks.StandingOrderRepository) {
+				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+				r.On("GetStandingOrder", ctx, walletID, orderID).Return(order, nil)
+				r.On("UpdateStandingOrder", ctx, orderID, service.StandingOrderUpdate{Amount: &amount}).
+					Return(service.StandingOrder{}, service.ErrStandingOrderNotActive)
+			},
+			wantErr: service.ErrStandingOrderNotActive,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			r := &mocks.StandingOrderRepository{}
+			if tc.expect != nil {
+				tc.expect(wr, r)
+			}
+
+			svc := service.StandingOrderService{
+				WalletReader: wr,
+				Repository:   r,
+			}
+
+			got, err := svc.UpdateStandingOrder(ctx, tc.user, walletID, orderID, tc.upd)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantOrder) {
+				t.Fatalf("unexpected standing order: \n got:  %+v \n want: %+v", got, tc.wantOrder)
+			}
+		})
+	}
+}
+
+func TestCancelStandingOrder(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx       = context.Background()
+		user      = service.User{ID: uuid.New()}
+		walletID  = uuid.New()
+		orderID   = uuid.New()
+		wallet    = service.Wallet{ID: walletID, UserID: user.ID}
+		next      = time.Now().Add(time.Hour)
+		order     = service.StandingOrder{ID: orderID, OriginWalletID: walletID, Status: service.StandingOrderActive, NextRunAt: &next}
+		cancelled = service.StandingOrder{ID: orderID, OriginWalletID: walletID, Status: service.StandingOrderCancelled}
+	)
+
+	wr := &mocks.WalletReader{}
+	wr.On("GetByID", ctx, walletID).Return(wallet, nil)
+	r := &mocks.StandingOrderRepository{}
+	r.On("GetStandingOrder", ctx, walletID, orderID).Return(order, nil)
+	r.On("CancelStandingOrder", ctx, orderID).Return(cancelled, nil)
+
+	svc := service.StandingOrderService{
+		WalletReader: wr,
+		Repository:   r,
+	}
+
+	got, err := svc.CancelStandingOrder(ctx, user, walletID, orderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, cancelled) {
+		t.Fatalf("unexpected standing order: \n got:  %+v \n want: %+v", got, cancelled)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statement generates the statement of the wallet for the month of the given date.
+func (svc *WalletService) Statement(ctx context.Context, user User, walletID uuid.UUID, month time.Time) (s Statement, err error) {
+	ctx, end := startSpan(ctx, "WalletService.Statement", walletAttr(walletID))
+	defer end(&err)
+
+	w, err := svc.WalletReader.GetByID(ctx, walletID)
+	if err != nil {
+		return s, fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if !user.CanRead(w) {
+		return s, ErrWalletAccessDenied
+	}
+
+	month = month.UTC()
+	s.WalletID = walletID
+	s.From = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	s.To = s.From.AddDate(0, 1, 0)
+
+	s.Lines, err = svc.TransactionsReader.ListStatementLines(ctx, walletID, s.From, s.To)
+	if err != nil {
+		return s, fmt.Errorf("cannot list statement transactions: %w", err)
+	}
+
+	// Without transactions in the period the balance
+	// is the one of the last transaction before it.
+	if len(s.Lines) == 0 {
+		s.Lines = []StatementLine{}
+		s.OpeningBalance, err = svc.TransactionsReader.BalanceAt(ctx, walletID, s.From)
+		if err != nil {
+			return s, fmt.Errorf("cannot get opening balance: %w", err)
+		}
+		s.ClosingBalance = s.OpeningBalance
+
+		return s, nil
+	}
+
+	first, last := s.Lines[0], s.Lines[len(s.Lines)-1]
+	// amounts have four decimals, avoid floating point residues.
+	s.OpeningBalance = math.Round((first.Balance-first.Amount)*10000) / 10000
+	s.ClosingBalance = last.Balance
+
+	return s, nil
+}
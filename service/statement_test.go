@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+)
+
+func TestStatement(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx         = context.Background()
+		dummyErr    = fmt.Errorf("dummy error")
+		userID      = uuid.New()
+		user        = service.User{ID: userID}
+		walletID    = uuid.New()
+		wallet      = service.Wallet{ID: walletID, UserID: userID}
+		month       = time.Date(2020, 9, 15, 10, 0, 0, 0, time.UTC)
+		from        = time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+		to          = time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+		referenceID = uuid.New()
+		message     = "dinner"
+		lines       = []service.StatementLine{{
+			Transaction: service.Transaction{
+				ID:      uuid.New(),
+				Amount:  20,
+				Balance: 25.5,
+				Type:    service.DepositType,
+				Date:    time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC),
+			},
+		}, {
+			Transaction: service.Transaction{
+				ID:          uuid.New(),
+				Amount:      -7.25,
+				Balance:     18.25,
+				Type:        service.TransferType,
+				ReferenceID: &referenceID,
+				Date:        time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC),
+			},
+			CounterpartyWalletID: &walletID,
+			Message:              &message,
+		}}
+	)
+	tt := []struct {
+		name          string
+		user          service.User
+		expect        func(*mocks.WalletReader, *mocks.TransactionsReader)
+		wantStatement service.Statement
+		wantErr       error
+	}{
+		{
+			name: "wallet access denied",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "list error",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("ListStatementLines", anyCtx, walletID, from, to).Return(nil, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "balance error",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("ListStatementLines", anyCtx, walletID, from, to).Return(nil, nil)
+				tr.On("BalanceAt", anyCtx, walletID, from).Return(float64(0), dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "no transactions in the period",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("ListStatementLines", anyCtx, walletID, from, to).Return(nil, nil)
+				tr.On("BalanceAt", anyCtx, walletID, from).Return(5.5, nil)
+			},
+			wantStatement: service.Statement{
+				WalletID:       walletID,
+				From:           from,
+				To:             to,
+				OpeningBalance: 5.5,
+				ClosingBalance: 5.5,
+				Lines:          []service.StatementLine{},
+			},
+		}, {
+			name: "statement ok",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("ListStatementLines", anyCtx, walletID, from, to).Return(lines, nil)
+			},
+			wantStatement: service.Statement{
+				WalletID:       walletID,
+				From:           from,
+				To:             to,
+				OpeningBalance: 5.5,
+				ClosingBalance: 18.25,
+				Lines:          lines,
+			},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			tr := &mocks.TransactionsReader{}
+			tc.expect(wr, tr)
+
+			svc := service.WalletService{
+				WalletReader:       wr,
+				TransactionsReader: tr,
+			}
+
+			got, err := svc.Statement(ctx, tc.user, walletID, month)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantStatement) {
+				t.Fatalf("unexpected statement: \n got:  %+v \n want: %+v", got, tc.wantStatement)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName name of the tracer of the service operations.
+const tracerName = "github.com/hmoragrega/paybile/service"
+
+// startSpan starts the span of a service operation, the returned
+// function ends it recording the error the operation returns:
+//
+//	ctx, end := startSpan(ctx, "WalletService.GetWallet")
+//	defer end(&err)
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(*error)) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+
+	return ctx, func(err *error) {
+		if err != nil && *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+// walletAttr attribute with the ID of the wallet of the operation.
+func walletAttr(walletID uuid.UUID) attribute.KeyValue {
+	return attribute.String("paybile.wallet_id", walletID.String())
+}
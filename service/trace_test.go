@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// anyCtx matches the context the services pass to their dependencies,
+// it holds the span of the operation instead of the caller's context.
+var anyCtx = mock.MatchedBy(func(context.Context) bool { return true })
+
+func TestWalletServiceSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	var (
+		user     = service.User{ID: uuid.New()}
+		walletID = uuid.New()
+	)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	var r mocks.WalletReader
+	r.On("GetByID", mock.MatchedBy(func(ctx context.Context) bool {
+		// the repositories receive the span of the operation.
+		return trace.SpanContextFromContext(ctx).SpanID() != parent.SpanContext().SpanID()
+	}), walletID).Return(service.Wallet{ID: walletID}, nil)
+
+	svc := service.WalletService{WalletReader: &r}
+	_, err := svc.GetWallet(ctx, user, walletID)
+	parent.End()
+	if err != service.ErrWalletAccessDenied {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of spans: %d", len(spans))
+	}
+	span := spans[0]
+	if got, want := span.Name, "WalletService.GetWallet"; got != want {
+		t.Fatalf("unexpected span name: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := span.Parent.SpanID(), parent.SpanContext().SpanID(); got != want {
+		t.Fatalf("unexpected parent span: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := span.StatusCode, codes.Error; got != want {
+		t.Fatalf("unexpected span status: \n got:  %+v \n want: %+v", got, want)
+	}
+}
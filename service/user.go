@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 )
 
+// DefaultSuccessAuditWindow how long the successful logins of
+// a login from the same IP are audited only once.
+const DefaultSuccessAuditWindow = time.Hour
+
 var (
 	ErrUserNotFound    = errors.New("user not found")
 	ErrInvalidPassword = errors.New("password incorrect")
@@ -20,17 +26,112 @@ type UserReader interface {
 
 type UserService struct {
 	Reader UserReader
+	// Auditor optional audit log of the logins.
+	Auditor Auditor
+	// SuccessAuditWindow how long the successful logins of a login from
+	// the same IP are audited only once, DefaultSuccessAuditWindow if not set.
+	SuccessAuditWindow time.Duration
+	// Attempts optional store of the failed login attempts,
+	// it enables the brute-force protection.
+	Attempts LoginAttemptStore
+	// LoginPolicy throttling of the failed attempts per login,
+	// DefaultLoginPolicy if not set.
+	LoginPolicy LoginPolicy
+	// IPPolicy throttling of the failed attempts per IP,
+	// DefaultIPPolicy if not set.
+	IPPolicy LoginPolicy
+
+	mu      sync.Mutex
+	audited map[string]time.Time
+	swept   time.Time
 }
 
+// Login authenticates the user. The failed attempts are tracked
+// per login and per IP, once they exceed the free attempts of the
+// policy the next ones are rejected with a LoginLockedError until
+// a delay, that doubles with each failure, has passed. The failures
+// are counted atomically, so the ones that exceed the maximum attempts
+// are rejected too even if they were checked concurrently.
+//
+// Every failed attempt is audited, but the API authenticates every
+// request so only the first success of a login from an IP within the
+// audit window is.
 func (svc *UserService) Login(ctx context.Context, login string, password string) (u User, err error) {
+	ip := AuditMetadataFromContext(ctx).IP
+	defer func() {
+		e := AuditEntry{Actor: login, Action: AuditLogin, Outcome: AuditFailure}
+		switch {
+		case err == nil && !svc.firstSuccess(login, ip, time.Now()):
+			return
+		case err == nil:
+			e.Actor, e.Outcome = u.ID.String(), AuditSuccess
+		case errors.Is(err, ErrLoginLocked):
+			e.Outcome = AuditDenied
+		}
+		Audit(ctx, svc.Auditor, e)
+	}()
+
+	failed, err := svc.checkAttempts(ctx, login, ip)
+	if err != nil {
+		return u, fmt.Errorf("cannot login user: %w", err)
+	}
+
 	u, err = svc.Reader.GetByLogin(ctx, login)
+	if errors.Is(err, ErrUserNotFound) {
+		// unknown logins count too, so they cannot be told apart.
+		if failErr := svc.addFailure(ctx, login, ip); failErr != nil {
+			return u, fmt.Errorf("cannot login user: %w", failErr)
+		}
+	}
 	if err != nil {
 		return u, fmt.Errorf("cannot login user: %w", err)
 	}
 
 	if !u.IsCorrectPass(password) {
+		if err = svc.addFailure(ctx, login, ip); err != nil {
+			return u, fmt.Errorf("cannot login user: %w", err)
+		}
 		return u, fmt.Errorf("cannot login user: %w", ErrInvalidPassword)
 	}
 
+	if !failed {
+		return u, nil
+	}
+	if err = svc.resetLogin(ctx, login); err != nil {
+		return u, fmt.Errorf("cannot login user: %w", err)
+	}
+
 	return u, nil
 }
+
+// firstSuccess returns true if it is the first successful login
+// of the login from the IP within the audit window.
+func (svc *UserService) firstSuccess(login, ip string, now time.Time) bool {
+	window := svc.SuccessAuditWindow
+	if window == 0 {
+		window = DefaultSuccessAuditWindow
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	if svc.audited == nil {
+		svc.audited = make(map[string]time.Time)
+	}
+	if now.Sub(svc.swept) >= window {
+		for k, at := range svc.audited {
+			if now.Sub(at) >= window {
+				delete(svc.audited, k)
+			}
+		}
+		svc.swept = now
+	}
+
+	key := login + " " + ip
+	if at, ok := svc.audited[key]; ok && now.Sub(at) < window {
+		return false
+	}
+	svc.audited[key] = now
+
+	return true
+}
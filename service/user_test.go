@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/hmoragrega/paybile/service"
 	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestLogin(t *testing.T) {
@@ -75,3 +76,92 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginAudit(t *testing.T) {
+	t.Parallel()
+	var (
+		meta = service.AuditMetadata{IP: "127.0.0.1", UserAgent: "curl", RequestID: "test-request"}
+		ctx  = service.ContextWithAuditMetadata(context.Background(), meta)
+		user = service.User{ID: uuid.New(), Login: "foo", HashedPassword: "bar"}
+	)
+	tt := []struct {
+		name      string
+		password  string
+		wantEntry service.AuditEntry
+	}{
+		{
+			name:     "failure",
+			password: "wrong",
+			wantEntry: service.AuditEntry{
+				Actor:         user.Login,
+				Action:        service.AuditLogin,
+				Outcome:       service.AuditFailure,
+				AuditMetadata: meta,
+			},
+		}, {
+			name:     "success",
+			password: user.HashedPassword,
+			wantEntry: service.AuditEntry{
+				Actor:         user.ID.String(),
+				Action:        service.AuditLogin,
+				Outcome:       service.AuditSuccess,
+				AuditMetadata: meta,
+			},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ur := &mocks.UserReader{}
+			ur.On("GetByLogin", ctx, user.Login).Return(user, nil)
+			a := &mocks.Auditor{}
+			if tc.wantEntry.Action != "" {
+				a.On("Audit", ctx, tc.wantEntry).Return(errors.New("audit errors are not returned"))
+			}
+
+			svc := service.UserService{
+				Reader:  ur,
+				Auditor: a,
+			}
+
+			_, _ = svc.Login(ctx, user.Login, tc.password)
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginAuditSuccessOnce(t *testing.T) {
+	t.Parallel()
+	var (
+		user  = service.User{ID: uuid.New(), Login: "foo", HashedPassword: "bar"}
+		metaA = service.AuditMetadata{IP: "127.0.0.1"}
+		metaB = service.AuditMetadata{IP: "127.0.0.2"}
+		ctxA  = service.ContextWithAuditMetadata(context.Background(), metaA)
+		ctxB  = service.ContextWithAuditMetadata(context.Background(), metaB)
+		entry = func(meta service.AuditMetadata) service.AuditEntry {
+			return service.AuditEntry{
+				Actor:         user.ID.String(),
+				Action:        service.AuditLogin,
+				Outcome:       service.AuditSuccess,
+				AuditMetadata: meta,
+			}
+		}
+	)
+	ur := &mocks.UserReader{}
+	ur.On("GetByLogin", mock.Anything, user.Login).Return(user, nil)
+	a := &mocks.Auditor{}
+	a.On("Audit", ctxA, entry(metaA)).Return(nil).Once()
+	a.On("Audit", ctxB, entry(metaB)).Return(nil).Once()
+
+	svc := service.UserService{
+		Reader:  ur,
+		Auditor: a,
+	}
+	for _, ctx := range []context.Context{ctxA, ctxA, ctxB, ctxA, ctxB} {
+		if _, err := svc.Login(ctx, user.Login, user.HashedPassword); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	a.AssertExpectations(t)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,6 +15,10 @@ var (
 	ErrWalletNotFound           = errors.New("wallet not found")
 	ErrInsufficientFunds        = errors.New("origin has insufficient funds")
 	ErrSameTransferWallets      = errors.New("same origin and destination")
+	ErrInvalidExecutionDate     = errors.New("execution date must be in the future")
+	ErrTransferNotFound         = errors.New("transfer not found")
+	ErrTransferNotCancellable   = errors.New("only scheduled transfers can be cancelled")
+	ErrTransactionNotFound      = errors.New("transaction not found")
 )
 
 type WalletReader interface {
@@ -33,18 +38,81 @@ type TransferCreator interface {
 	CreateTransfer(ctx context.Context, req TransferRequest) (Transfer, error)
 }
 
+type TransferScheduler interface {
+	// ScheduleTransfer stores a transfer to be executed in the
+	// future, the funds are not moved until its execution.
+	// Errors:
+	// - ErrInvalidTransactionAmount: if the amount is zero or less.
+	// - ErrWalletNotFound: if a wallet involved does not exists.
+	// - ErrWalletAccessDenied: if the issuer cannot transfer funds from the wallet.
+	ScheduleTransfer(ctx context.Context, req TransferRequest) (Transfer, error)
+
+	// CancelTransfer cancels a scheduled transfer before its execution.
+	// Errors:
+	// - ErrWalletAccessDenied: if the issuer cannot transfer funds from the wallet,
+	//   whether the transfer exists or not.
+	// - ErrTransferNotFound: if the wallet does not have the transfer.
+	// - ErrTransferNotCancellable: if the transfer is not scheduled anymore.
+	CancelTransfer(ctx context.Context, issuer User, walletID, transferID uuid.UUID) (Transfer, error)
+}
+
 type TransactionsReader interface {
 	// ListTransactions returns a list transactions of a wallet ordered by date.
 	// Returns:
 	// - list: transactions for the current page.
 	// - next: the next transaction ID in the list, can be used to request the next page.
 	ListTransactions(ctx context.Context, walletID uuid.UUID, filters ListOptions) (list TransactionList, err error)
+
+	// BalanceAt returns the balance of the wallet after its last transaction at
+	// or before the given time, zero if the wallet had no transactions yet.
+	BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (balance float64, err error)
+
+	// ListStatementLines returns all the transactions of the wallet between the dates, from
+	// inclusive to exclusive, ordered by date with the details of the originating transfers.
+	ListStatementLines(ctx context.Context, walletID uuid.UUID, from, to time.Time) (lines []StatementLine, err error)
+
+	// StreamTransactions calls the function with every transaction of the wallet ordered
+	// by date as they are read, stopping at the first error returned by the function.
+	StreamTransactions(ctx context.Context, walletID uuid.UUID, fn func(Transaction) error) error
+
+	// GetTransaction returns a transaction of the wallet.
+	// Errors:
+	// - ErrTransactionNotFound: if the wallet does not have the transaction.
+	GetTransaction(ctx context.Context, walletID, transactionID uuid.UUID) (Transaction, error)
+
+	// ListTransactionsAfter returns the transactions of the wallet that
+	// follow the given one, ordered by date.
+	// Errors:
+	// - ErrTransactionNotFound: if the wallet does not have the transaction.
+	ListTransactionsAfter(ctx context.Context, walletID, transactionID uuid.UUID) ([]Transaction, error)
+}
+
+type TransactionListener interface {
+	// ListenTransactions returns the IDs of the wallet transactions as they are
+	// committed until the context is done. The channel is closed if notifications
+	// may have been lost, the subscriber must resume from the transaction history.
+	ListenTransactions(ctx context.Context, walletID uuid.UUID) (<-chan uuid.UUID, error)
+}
+
+type TransferObserver interface {
+	// ObserveTransfer records the outcome of a transfer request, the
+	// error is nil if the transfer has been completed or scheduled.
+	ObserveTransfer(req TransferRequest, err error)
 }
 
 type WalletService struct {
 	WalletReader       WalletReader
 	TransferCreator    TransferCreator
+	TransferScheduler  TransferScheduler
+	BatchCreator       BatchTransferCreator
 	TransactionsReader TransactionsReader
+	Listener           TransactionListener
+	// Observer optional observer of the transfer requests.
+	Observer TransferObserver
+	// Auditor optional audit log of the transfers.
+	Auditor Auditor
+	// PageLimits of the transaction lists.
+	PageLimits PageLimits
 }
 
 type TransferRequest struct {
@@ -53,6 +121,9 @@ type TransferRequest struct {
 	DestinationWalletID uuid.UUID
 	Amount              float64
 	Message             *string
+	// ExecuteAt if provided the transfer will be scheduled
+	// to be executed at the given time.
+	ExecuteAt *time.Time
 }
 
 type TransactionList struct {
@@ -61,6 +132,9 @@ type TransactionList struct {
 }
 
 func (svc *WalletService) GetWallet(ctx context.Context, user User, walletID uuid.UUID) (w Wallet, err error) {
+	ctx, end := startSpan(ctx, "WalletService.GetWallet", walletAttr(walletID))
+	defer end(&err)
+
 	w, err = svc.WalletReader.GetByID(ctx, walletID)
 	if err != nil {
 		return w, fmt.Errorf("cannot get wallet: %w", err)
@@ -74,6 +148,21 @@ func (svc *WalletService) GetWallet(ctx context.Context, user User, walletID uui
 
 // Transfer transfer funds to another wallet.
 func (svc *WalletService) TransferFunds(ctx context.Context, req TransferRequest) (t Transfer, err error) {
+	ctx, end := startSpan(ctx, "WalletService.TransferFunds", walletAttr(req.OriginWalletID))
+	defer end(&err)
+
+	defer func() {
+		if svc.Observer != nil {
+			svc.Observer.ObserveTransfer(req, err)
+		}
+		Audit(ctx, svc.Auditor, AuditEntry{
+			Actor:    req.Issuer.ID.String(),
+			Action:   AuditTransfer,
+			Resource: walletResource(req.OriginWalletID),
+			Outcome:  auditOutcome(err),
+		})
+	}()
+
 	if req.Amount <= 0 {
 		return t, ErrInvalidTransactionAmount
 	}
@@ -81,6 +170,19 @@ func (svc *WalletService) TransferFunds(ctx context.Context, req TransferRequest
 		return t, ErrSameTransferWallets
 	}
 
+	if req.ExecuteAt != nil {
+		if !req.ExecuteAt.After(time.Now()) {
+			return t, ErrInvalidExecutionDate
+		}
+
+		t, err = svc.TransferScheduler.ScheduleTransfer(ctx, req)
+		if err != nil {
+			return t, fmt.Errorf("cannot schedule transfer: %w", err)
+		}
+
+		return t, nil
+	}
+
 	t, err = svc.TransferCreator.CreateTransfer(ctx, req)
 	if err != nil {
 		return t, fmt.Errorf("cannot transfer funds: %w", err)
@@ -89,12 +191,42 @@ func (svc *WalletService) TransferFunds(ctx context.Context, req TransferRequest
 	return t, nil
 }
 
+// CancelTransfer cancels a scheduled transfer of the wallet.
+func (svc *WalletService) CancelTransfer(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	transferID uuid.UUID,
+) (t Transfer, err error) {
+	ctx, end := startSpan(ctx, "WalletService.CancelTransfer", walletAttr(walletID))
+	defer end(&err)
+
+	defer func() {
+		Audit(ctx, svc.Auditor, AuditEntry{
+			Actor:    user.ID.String(),
+			Action:   AuditTransferCancel,
+			Resource: "transfer:" + transferID.String(),
+			Outcome:  auditOutcome(err),
+		})
+	}()
+
+	t, err = svc.TransferScheduler.CancelTransfer(ctx, user, walletID, transferID)
+	if err != nil {
+		return t, fmt.Errorf("cannot cancel transfer: %w", err)
+	}
+
+	return t, nil
+}
+
 func (svc *WalletService) ListTransactions(
 	ctx context.Context,
 	user User,
 	walletID uuid.UUID,
 	opt ListOptions,
 ) (l TransactionList, err error) {
+	ctx, end := startSpan(ctx, "WalletService.ListTransactions", walletAttr(walletID))
+	defer end(&err)
+
 	w, err := svc.WalletReader.GetByID(ctx, walletID)
 	if err != nil {
 		return l, fmt.Errorf("cannot get wallet: %w", err)
@@ -104,7 +236,7 @@ func (svc *WalletService) ListTransactions(
 	}
 
 	if opt.PerPage <= 0 {
-		opt.PerPage = DefaultPerPage
+		opt.PerPage = svc.PageLimits.DefaultPerPage()
 	}
 
 	l, err = svc.TransactionsReader.ListTransactions(ctx, walletID, opt)
@@ -114,3 +246,53 @@ func (svc *WalletService) ListTransactions(
 
 	return l, nil
 }
+
+// ExportTransactions streams the whole transaction history of the wallet to the function.
+func (svc *WalletService) ExportTransactions(
+	ctx context.Context,
+	user User,
+	walletID uuid.UUID,
+	fn func(Transaction) error,
+) (err error) {
+	ctx, end := startSpan(ctx, "WalletService.ExportTransactions", walletAttr(walletID))
+	defer end(&err)
+
+	w, err := svc.WalletReader.GetByID(ctx, walletID)
+	if err != nil {
+		return fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if !user.CanRead(w) {
+		return ErrWalletAccessDenied
+	}
+
+	if err = svc.TransactionsReader.StreamTransactions(ctx, walletID, fn); err != nil {
+		return fmt.Errorf("cannot export transactions: %w", err)
+	}
+
+	return nil
+}
+
+// BalanceAt returns the balance the wallet had at the given time.
+func (svc *WalletService) BalanceAt(ctx context.Context, user User, walletID uuid.UUID, at time.Time) (b WalletBalance, err error) {
+	ctx, end := startSpan(ctx, "WalletService.BalanceAt", walletAttr(walletID))
+	defer end(&err)
+
+	w, err := svc.WalletReader.GetByID(ctx, walletID)
+	if err != nil {
+		return b, fmt.Errorf("cannot get wallet: %w", err)
+	}
+	if !user.CanRead(w) {
+		return b, ErrWalletAccessDenied
+	}
+
+	balance, err := svc.TransactionsReader.BalanceAt(ctx, walletID, at)
+	if err != nil {
+		return b, fmt.Errorf("cannot get balance: %w", err)
+	}
+
+	return WalletBalance{
+		WalletID: walletID,
+		Balance:  balance,
+		At:       at,
+	}, nil
+}
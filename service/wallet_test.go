@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/hmoragrega/paybile/service"
 	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetWallet(t *testing.T) {
@@ -35,7 +36,7 @@ func TestGetWallet(t *testing.T) {
 			user:     user,
 			walletID: walletID,
 			expect: func(r *mocks.WalletReader) {
-				r.On("GetByID", ctx, walletID).Return(service.Wallet{}, dummyErr)
+				r.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, dummyErr)
 			},
 			wantErr: dummyErr,
 		}, {
@@ -43,7 +44,7 @@ func TestGetWallet(t *testing.T) {
 			user:     user,
 			walletID: walletID,
 			expect: func(r *mocks.WalletReader) {
-				r.On("GetByID", ctx, walletID).Return(service.Wallet{}, nil)
+				r.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, nil)
 			},
 			wantErr: service.ErrWalletAccessDenied,
 		}, {
@@ -51,7 +52,7 @@ func TestGetWallet(t *testing.T) {
 			user:     user,
 			walletID: walletID,
 			expect: func(r *mocks.WalletReader) {
-				r.On("GetByID", ctx, walletID).Return(wallet, nil)
+				r.On("GetByID", anyCtx, walletID).Return(wallet, nil)
 			},
 			wantWallet: wallet,
 		},
@@ -101,13 +102,22 @@ func TestTransferFunds(t *testing.T) {
 			Amount:              25,
 			Message:             &message,
 			Date:                time.Now(),
+			Status:              service.TransferCompleted,
 		}
+		executeAt        = time.Now().Add(time.Hour)
+		pastDate         = time.Now().Add(-time.Hour)
+		scheduledRequest = request
+		scheduled        = transfer
 	)
+	scheduledRequest.ExecuteAt = &executeAt
+	scheduled.Status = service.TransferScheduled
+	scheduled.ExecuteAt = &executeAt
+
 	tt := []struct {
 		name         string
 		req          service.TransferRequest
 		walletID     uuid.UUID
-		expect       func(*mocks.TransferCreator)
+		expect       func(*mocks.TransferCreator, *mocks.TransferScheduler)
 		wantTransfer service.Transfer
 		wantErr      error
 	}{
@@ -128,27 +138,60 @@ func TestTransferFunds(t *testing.T) {
 		}, {
 			name: "transfer error",
 			req:  request,
-			expect: func(r *mocks.TransferCreator) {
-				r.On("CreateTransfer", ctx, request).Return(transfer, dummyErr)
+			expect: func(r *mocks.TransferCreator, s *mocks.TransferScheduler) {
+				r.On("CreateTransfer", anyCtx, request).Return(service.Transfer{}, dummyErr)
 			},
 			wantErr: dummyErr,
 		}, {
 			name: "transfer ok",
 			req:  request,
-			expect: func(r *mocks.TransferCreator) {
-				r.On("CreateTransfer", ctx, request).Return(transfer, nil)
+			expect: func(r *mocks.TransferCreator, s *mocks.TransferScheduler) {
+				r.On("CreateTransfer", anyCtx, request).Return(transfer, nil)
 			},
 			wantTransfer: transfer,
+		}, {
+			name: "execution date in the past",
+			req: service.TransferRequest{
+				OriginWalletID:      originID,
+				DestinationWalletID: destinationID,
+				Amount:              10,
+				ExecuteAt:           &pastDate,
+			},
+			wantErr: service.ErrInvalidExecutionDate,
+		}, {
+			name: "schedule error",
+			req:  scheduledRequest,
+			expect: func(r *mocks.TransferCreator, s *mocks.TransferScheduler) {
+				s.On("ScheduleTransfer", anyCtx, scheduledRequest).Return(service.Transfer{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "schedule ok",
+			req:  scheduledRequest,
+			expect: func(r *mocks.TransferCreator, s *mocks.TransferScheduler) {
+				s.On("ScheduleTransfer", anyCtx, scheduledRequest).Return(scheduled, nil)
+			},
+			wantTransfer: scheduled,
 		},
 	}
 	for _, tc := range tt {
+		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 			c := &mocks.TransferCreator{}
-			tc.expect(c)
+			s := &mocks.TransferScheduler{}
+			o := &mocks.TransferObserver{}
+			if tc.expect != nil {
+				tc.expect(c, s)
+			}
+			o.On("ObserveTransfer", tc.req, mock.MatchedBy(func(err error) bool {
+				return errors.Is(err, tc.wantErr)
+			})).Once()
 
 			svc := service.WalletService{
-				TransferCreator: c,
+				TransferCreator:   c,
+				TransferScheduler: s,
+				Observer:          o,
 			}
 
 			got, err := svc.TransferFunds(ctx, tc.req)
@@ -158,6 +201,70 @@ func TestTransferFunds(t *testing.T) {
 			if !reflect.DeepEqual(got, tc.wantTransfer) {
 				t.Fatalf("unexpected transfer: \n got:  %+v \n want: %+v", got, tc.wantTransfer)
 			}
+			o.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelTransfer(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx        = context.Background()
+		dummyErr   = fmt.Errorf("dummy error")
+		user       = service.User{ID: uuid.New()}
+		walletID   = uuid.New()
+		transferID = uuid.New()
+		transfer   = service.Transfer{
+			ID:             transferID,
+			IssuerID:       user.ID,
+			OriginWalletID: walletID,
+			Status:         service.TransferCancelled,
+		}
+	)
+	tt := []struct {
+		name         string
+		expect       func(*mocks.TransferScheduler)
+		wantTransfer service.Transfer
+		wantErr      error
+	}{
+		{
+			name: "cancel error",
+			expect: func(s *mocks.TransferScheduler) {
+				s.On("CancelTransfer", anyCtx, user, walletID, transferID).Return(service.Transfer{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "not cancellable",
+			expect: func(s *mocks.TransferScheduler) {
+				s.On("CancelTransfer", anyCtx, user, walletID, transferID).Return(service.Transfer{}, service.ErrTransferNotCancellable)
+			},
+			wantErr: service.ErrTransferNotCancellable,
+		}, {
+			name: "cancel ok",
+			expect: func(s *mocks.TransferScheduler) {
+				s.On("CancelTransfer", anyCtx, user, walletID, transferID).Return(transfer, nil)
+			},
+			wantTransfer: transfer,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := &mocks.TransferScheduler{}
+			tc.expect(s)
+
+			svc := service.WalletService{
+				TransferScheduler: s,
+			}
+
+			got, err := svc.CancelTransfer(ctx, user, walletID, transferID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantTransfer) {
+				t.Fatalf("unexpected transfer: \n got:  %+v \n want: %+v", got, tc.wantTransfer)
+			}
 		})
 	}
 }
@@ -187,7 +294,7 @@ func TestListTransactions(t *testing.T) {
 			name:     "error loading wallet",
 			walletID: walletID,
 			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
-				wr.On("GetByID", ctx, walletID).Return(service.Wallet{}, dummyErr)
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, dummyErr)
 			},
 			wantErr: dummyErr,
 		}, {
@@ -195,7 +302,7 @@ func TestListTransactions(t *testing.T) {
 			walletID: walletID,
 			user:     user,
 			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
-				wr.On("GetByID", ctx, walletID).Return(service.Wallet{}, nil)
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, nil)
 			},
 			wantErr: service.ErrWalletAccessDenied,
 		}, {
@@ -204,8 +311,8 @@ func TestListTransactions(t *testing.T) {
 			user:     user,
 			opts:     opts,
 			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
-				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
-				tr.On("ListTransactions", ctx, walletID, opts).Return(service.TransactionList{}, dummyErr)
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("ListTransactions", anyCtx, walletID, opts).Return(service.TransactionList{}, dummyErr)
 			},
 			wantErr: dummyErr,
 		}, {
@@ -214,8 +321,8 @@ func TestListTransactions(t *testing.T) {
 			user:     user,
 			opts:     opts,
 			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
-				wr.On("GetByID", ctx, walletID).Return(wallet, nil)
-				tr.On("ListTransactions", ctx, walletID, opts).Return(list, nil)
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("ListTransactions", anyCtx, walletID, opts).Return(list, nil)
 			},
 			wantList: list,
 		},
@@ -242,3 +349,145 @@ func TestListTransactions(t *testing.T) {
 		})
 	}
 }
+
+func TestBalanceAt(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		issuerID = uuid.New()
+		user     = service.User{ID: issuerID}
+		walletID = uuid.New()
+		wallet   = service.Wallet{ID: walletID, UserID: issuerID}
+		at       = time.Date(2020, 3, 31, 23, 59, 59, 0, time.UTC)
+	)
+	tt := []struct {
+		name        string
+		user        service.User
+		expect      func(*mocks.WalletReader, *mocks.TransactionsReader)
+		wantBalance service.WalletBalance
+		wantErr     error
+	}{
+		{
+			name: "error loading wallet",
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "wallet access denied",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "balance error",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("BalanceAt", anyCtx, walletID, at).Return(float64(0), dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "balance ok",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("BalanceAt", anyCtx, walletID, at).Return(12.75, nil)
+			},
+			wantBalance: service.WalletBalance{WalletID: walletID, Balance: 12.75, At: at},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			tr := &mocks.TransactionsReader{}
+			tc.expect(wr, tr)
+
+			svc := service.WalletService{
+				WalletReader:       wr,
+				TransactionsReader: tr,
+			}
+
+			got, err := svc.BalanceAt(ctx, tc.user, walletID, at)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantBalance) {
+				t.Fatalf("unexpected balance: \n got:  %+v \n want: %+v", got, tc.wantBalance)
+			}
+		})
+	}
+}
+
+func TestExportTransactions(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		issuerID = uuid.New()
+		user     = service.User{ID: issuerID}
+		walletID = uuid.New()
+		wallet   = service.Wallet{ID: walletID, UserID: issuerID}
+	)
+	tt := []struct {
+		name    string
+		user    service.User
+		expect  func(*mocks.WalletReader, *mocks.TransactionsReader)
+		wantErr error
+	}{
+		{
+			name: "error loading wallet",
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "wallet access denied",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(service.Wallet{}, nil)
+			},
+			wantErr: service.ErrWalletAccessDenied,
+		}, {
+			name: "stream error",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("StreamTransactions", anyCtx, walletID, mock.Anything).Return(dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "export ok",
+			user: user,
+			expect: func(wr *mocks.WalletReader, tr *mocks.TransactionsReader) {
+				wr.On("GetByID", anyCtx, walletID).Return(wallet, nil)
+				tr.On("StreamTransactions", anyCtx, walletID, mock.Anything).Return(nil)
+			},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			wr := &mocks.WalletReader{}
+			tr := &mocks.TransactionsReader{}
+			tc.expect(wr, tr)
+
+			svc := service.WalletService{
+				WalletReader:       wr,
+				TransactionsReader: tr,
+			}
+
+			err := svc.ExportTransactions(ctx, tc.user, walletID, func(service.Transaction) error {
+				return nil
+			})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// WebhookSignatureHeader header with the signature of the payload.
+	WebhookSignatureHeader = "X-Paybile-Signature"
+	// WebhookEventHeader header with the type of the event.
+	WebhookEventHeader = "X-Paybile-Event"
+	// WebhookDeliveryHeader header with the ID of the delivery.
+	WebhookDeliveryHeader = "X-Paybile-Delivery"
+
+	MinWebhookSecretLength    = 16
+	MaxWebhookDeliveries      = 50
+	DefaultWebhookInterval    = 5 * time.Second
+	DefaultWebhookMaxAttempts = 8
+	DefaultWebhookBackoff     = 30 * time.Second
+	DefaultWebhookBatchSize   = 100
+	DefaultWebhookTimeout     = 10 * time.Second
+)
+
+var (
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, s WebhookSubscription) (WebhookSubscription, error)
+
+	// GetWebhook returns a subscription by its ID.
+	// Errors:
+	// - ErrWebhookNotFound: if the subscription does not exist.
+	GetWebhook(ctx context.Context, subscriptionID uuid.UUID) (WebhookSubscription, error)
+
+	// ListWebhooks returns the subscriptions of the user ordered by creation date.
+	ListWebhooks(ctx context.Context, userID uuid.UUID) ([]WebhookSubscription, error)
+
+	// DeleteWebhook deletes the subscription with its pending deliveries.
+	// Errors:
+	// - ErrWebhookNotFound: if the subscription does not exist.
+	DeleteWebhook(ctx context.Context, subscriptionID uuid.UUID) error
+
+	// ListWebhookDeliveries returns the latest deliveries of the
+	// subscription with their attempts log, most recent first.
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]WebhookDelivery, error)
+}
+
+type WebhookOutbox interface {
+	// ClaimWebhookDeliveries returns the pending deliveries due at the given time, postponing
+	// them for the lease duration so other dispatchers do not deliver them at the same time.
+	ClaimWebhookDeliveries(ctx context.Context, until time.Time, lease time.Duration, limit int) ([]PendingWebhook, error)
+
+	// RecordWebhookAttempt stores the attempt in the log and updates the delivery.
+	RecordWebhookAttempt(ctx context.Context, d WebhookDelivery, a WebhookDeliveryAttempt) error
+
+	// EnqueueWebhooks stores a pending delivery of the event for every subscription
+	// of the owner of the event wallet to the event type. Enqueuing the same event
+	// again does not duplicate its deliveries.
+	EnqueueWebhooks(ctx context.Context, e Event) error
+}
+
+// PendingWebhook delivery ready to be sent to its subscription.
+type PendingWebhook struct {
+	Delivery     WebhookDelivery
+	Subscription WebhookSubscription
+}
+
+// WebhookEvent body of the webhook requests.
+type WebhookEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      EventType       `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type WebhookRequest struct {
+	User       User
+	URL        string
+	Secret     string
+	EventTypes []EventType
+}
+
+// Validate checks the subscription request.
+func (req WebhookRequest) Validate() error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(req.Secret) < MinWebhookSecretLength {
+		return fmt.Errorf("%w: secret must have at least %d characters", ErrInvalidWebhook, MinWebhookSecretLength)
+	}
+	if len(req.EventTypes) == 0 {
+		return fmt.Errorf("%w: no event types", ErrInvalidWebhook)
+	}
+	for _, x := range req.EventTypes {
+		switch x {
+		case TransferCreatedEvent, DepositCreatedEvent:
+		default:
+			return fmt.Errorf("%w: %q is not a valid event type. Valid values: %s, %s",
+				ErrInvalidWebhook, x, TransferCreatedEvent, DepositCreatedEvent)
+		}
+	}
+
+	return nil
+}
+
+type WebhookService struct {
+	Repository WebhookRepository
+}
+
+func (svc *WebhookService) CreateWebhook(ctx context.Context, req WebhookRequest) (s WebhookSubscription, err error) {
+	if err = req.Validate(); err != nil {
+		return s, err
+	}
+
+	s, err = svc.Repository.CreateWebhook(ctx, WebhookSubscription{
+		UserID:     req.User.ID,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		return s, fmt.Errorf("cannot create webhook: %w", err)
+	}
+
+	return s, nil
+}
+
+func (svc *WebhookService) ListWebhooks(ctx context.Context, user User) (list []WebhookSubscription, err error) {
+	list, err = svc.Repository.ListWebhooks(ctx, user.ID)
+	if err != nil {
+		return list, fmt.Errorf("cannot list webhooks: %w", err)
+	}
+
+	return list, nil
+}
+
+func (svc *WebhookService) DeleteWebhook(ctx context.Context, user User, subscriptionID uuid.UUID) error {
+	if _, err := svc.ownWebhook(ctx, user, subscriptionID); err != nil {
+		return err
+	}
+	if err := svc.Repository.DeleteWebhook(ctx, subscriptionID); err != nil {
+		return fmt.Errorf("cannot delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns the delivery log of the subscription.
+func (svc *WebhookService) ListWebhookDeliveries(
+	ctx context.Context,
+	user User,
+	subscriptionID uuid.UUID,
+) (list []WebhookDelivery, err error) {
+	if _, err = svc.ownWebhook(ctx, user, subscriptionID); err != nil {
+		return list, err
+	}
+
+	list, err = svc.Repository.ListWebhookDeliveries(ctx, subscriptionID, MaxWebhookDeliveries)
+	if err != nil {
+		return list, fmt.Errorf("cannot list webhook deliveries: %w", err)
+	}
+
+	return list, nil
+}
+
+// ownWebhook returns the subscription, the ones from
+// other users are not found to avoid disclosing them.
+func (svc *WebhookService) ownWebhook(ctx context.Context, user User, subscriptionID uuid.UUID) (s WebhookSubscription, err error) {
+	s, err = svc.Repository.GetWebhook(ctx, subscriptionID)
+	if err != nil {
+		return s, fmt.Errorf("cannot get webhook: %w", err)
+	}
+	if !user.CanRead(s) {
+		return s, ErrWebhookNotFound
+	}
+
+	return s, nil
+}
+
+// WebhookPublisher publishes the events relayed from the outbox
+// as webhook deliveries, which are sent by the WebhookDispatcher.
+type WebhookPublisher struct {
+	Outbox WebhookOutbox
+}
+
+func (p WebhookPublisher) Publish(ctx context.Context, e Event) error {
+	if err := p.Outbox.EnqueueWebhooks(ctx, e); err != nil {
+		return fmt.Errorf("cannot enqueue webhooks: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookDispatcher delivers periodically the pending webhooks, retrying
+// the failed ones with exponential backoff until they are dead.
+type WebhookDispatcher struct {
+	Outbox WebhookOutbox
+	// Client HTTP client, by default NewWebhookClient with a timeout of
+	// ten seconds, which does not connect to private networks.
+	Client *http.Client
+	// Interval time between executions, by default five seconds.
+	Interval time.Duration
+	// MaxAttempts attempts before a delivery is dead, by default eight.
+	MaxAttempts int
+	// Backoff delay after the first failed attempt, it doubles on every
+	// attempt, by default thirty seconds.
+	Backoff time.Duration
+	// BatchSize maximum deliveries claimed per execution, by default one hundred.
+	BatchSize int
+}
+
+// Run delivers the pending webhooks until the context is done.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = DefaultWebhookInterval
+	}
+
+	runEvery(ctx, interval, func(ctx context.Context) {
+		if err := d.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("cannot dispatch webhooks")
+		}
+	})
+}
+
+// DispatchPending delivers the webhooks that are due.
+func (d *WebhookDispatcher) DispatchPending(ctx context.Context) error {
+	batchSize := d.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultWebhookBatchSize
+	}
+
+	list, err := d.Outbox.ClaimWebhookDeliveries(ctx, time.Now(), d.lease(), batchSize)
+	if err != nil {
+		return fmt.Errorf("cannot claim webhook deliveries: %w", err)
+	}
+
+	for _, p := range list {
+		delivery, attempt := d.deliver(ctx, p)
+		if err := d.Outbox.RecordWebhookAttempt(ctx, delivery, attempt); err != nil {
+			return fmt.Errorf("cannot record webhook attempt: %w", err)
+		}
+
+		e := log.Info()
+		if delivery.Status != WebhookDeliveryDelivered {
+			e = log.Warn()
+		}
+		e.Str("delivery_id", delivery.ID.String()).
+			Str("status", string(delivery.Status)).
+			Int("attempt", attempt.Attempt).
+			Msg("webhook attempted")
+	}
+
+	return nil
+}
+
+// deliver sends the webhook and returns the delivery updated with the result.
+func (d *WebhookDispatcher) deliver(ctx context.Context, p PendingWebhook) (WebhookDelivery, WebhookDeliveryAttempt) {
+	var (
+		delivery = p.Delivery
+		start    = time.Now()
+		attempt  = WebhookDeliveryAttempt{Attempt: delivery.Attempts + 1}
+	)
+
+	statusCode, err := d.send(ctx, p)
+	attempt.Date = time.Now().UTC()
+	attempt.DurationMS = int(attempt.Date.Sub(start) / time.Millisecond)
+	if statusCode > 0 {
+		attempt.StatusCode = &statusCode
+	}
+
+	delivery.Attempts = attempt.Attempt
+	if err == nil {
+		delivery.Status = WebhookDeliveryDelivered
+		delivery.DeliveredAt = &attempt.Date
+		return delivery, attempt
+	}
+
+	msg := err.Error()
+	attempt.Error = &msg
+	if delivery.Attempts >= d.maxAttempts() {
+		delivery.Status = WebhookDeliveryDead
+		return delivery, attempt
+	}
+
+	delivery.Status = WebhookDeliveryPending
+	delivery.NextAttemptAt = attempt.Date.Add(d.backoff(delivery.Attempts))
+
+	return delivery, attempt
+}
+
+// send posts the signed event, any response other than 2xx is an error.
+func (d *WebhookDispatcher) send(ctx context.Context, p PendingWebhook) (statusCode int, err error) {
+	body, err := json.Marshal(WebhookEvent{
+		ID:        p.Delivery.EventID,
+		Type:      p.Delivery.EventType,
+		CreatedAt: p.Delivery.CreatedAt,
+		Data:      p.Delivery.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot marshal event: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("cannot build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(p.Delivery.EventType))
+	req.Header.Set(WebhookDeliveryHeader, p.Delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(p.Subscription.Secret, time.Now(), body))
+
+	client := d.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// The response body is never stored, the attempts
+	// log is visible to the owner of the subscription.
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// lease time a claimed delivery is hidden from other
+// dispatchers, enough to attempt the whole batch.
+func (d *WebhookDispatcher) lease() time.Duration {
+	timeout := DefaultWebhookTimeout
+	if d.Client != nil && d.Client.Timeout > 0 {
+		timeout = d.Client.Timeout
+	}
+	batchSize := d.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultWebhookBatchSize
+	}
+
+	return timeout * time.Duration(batchSize+1)
+}
+
+func (d *WebhookDispatcher) maxAttempts() int {
+	if d.MaxAttempts <= 0 {
+		return DefaultWebhookMaxAttempts
+	}
+	return d.MaxAttempts
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	base := d.Backoff
+	if base <= 0 {
+		base = DefaultWebhookBackoff
+	}
+	return base << uint(attempts-1)
+}
+
+// SignWebhook returns the signature of the webhook body: the timestamp
+// and the hex HMAC-SHA256 of "timestamp.body" using the subscription
+// secret, with the format "t=<unix timestamp>,v1=<signature>".
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(ts))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
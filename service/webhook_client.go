@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookAddressNotAllowed the webhook resolves to an address of a private network.
+var ErrWebhookAddressNotAllowed = errors.New("webhook address not allowed")
+
+// blockedNetworks private, loopback, link-local and other special purpose
+// networks, the webhooks cannot reach them to avoid exposing internal services.
+var blockedNetworks = mustParseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+var defaultWebhookClient = NewWebhookClient(DefaultWebhookTimeout)
+
+// NewWebhookClient returns the HTTP client that delivers the webhooks. It refuses
+// to connect to private, loopback and link-local addresses unless they belong to
+// one of the allowed networks. The address is checked when connecting, once the
+// host is resolved, so a DNS record cannot point the webhook to them later on.
+func NewWebhookClient(timeout time.Duration, allowed ...*net.IPNet) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkWebhookAddress(address, allowed)
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy, the dialer has to see the address of the webhook.
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func checkWebhookAddress(address string, allowed []*net.IPNet) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookAddressNotAllowed, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s is not an IP", ErrWebhookAddressNotAllowed, host)
+	}
+	for _, n := range allowed {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	for _, n := range blockedNetworks {
+		if n.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, ip)
+		}
+	}
+
+	return nil
+}
+
+func mustParseNetworks(cidrs ...string) []*net.IPNet {
+	list := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		list = append(list, n)
+	}
+
+	return list
+}
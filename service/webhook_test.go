@@ -0,0 +1,483 @@
+package service_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		user     = service.User{ID: uuid.New()}
+		secret   = "0123456789abcdef"
+		request  = service.WebhookRequest{
+			User:       user,
+			URL:        "https://example.com/hooks",
+			Secret:     secret,
+			EventTypes: []service.EventType{service.TransferCreatedEvent, service.DepositCreatedEvent},
+		}
+		subscription = service.WebhookSubscription{
+			UserID:     user.ID,
+			URL:        request.URL,
+			Secret:     secret,
+			EventTypes: request.EventTypes,
+		}
+		created = subscription
+	)
+	created.ID = uuid.New()
+
+	with := func(fn func(r *service.WebhookRequest)) service.WebhookRequest {
+		r := request
+		fn(&r)
+		return r
+	}
+
+	tt := []struct {
+		name      string
+		req       service.WebhookRequest
+		expect    func(*mocks.WebhookRepository)
+		wantHook  service.WebhookSubscription
+		wantErr   error
+		wantError string
+	}{
+		{
+			name:      "relative url",
+			req:       with(func(r *service.WebhookRequest) { r.URL = "/hooks" }),
+			wantErr:   service.ErrInvalidWebhook,
+			wantError: "invalid webhook: url must be an absolute http or https URL",
+		}, {
+			name:      "unsupported scheme",
+			req:       with(func(r *service.WebhookRequest) { r.URL = "ftp://example.com" }),
+			wantErr:   service.ErrInvalidWebhook,
+			wantError: "invalid webhook: url must be an absolute http or https URL",
+		}, {
+			name:      "short secret",
+			req:       with(func(r *service.WebhookRequest) { r.Secret = "secret" }),
+			wantErr:   service.ErrInvalidWebhook,
+			wantError: "invalid webhook: secret must have at least 16 characters",
+		}, {
+			name:      "no event types",
+			req:       with(func(r *service.WebhookRequest) { r.EventTypes = nil }),
+			wantErr:   service.ErrInvalidWebhook,
+			wantError: "invalid webhook: no event types",
+		}, {
+			name:      "invalid event type",
+			req:       with(func(r *service.WebhookRequest) { r.EventTypes = []service.EventType{"foo"} }),
+			wantErr:   service.ErrInvalidWebhook,
+			wantError: `invalid webhook: "foo" is not a valid event type. Valid values: transfer.created, deposit.created`,
+		}, {
+			name: "repository error",
+			req:  request,
+			expect: func(r *mocks.WebhookRepository) {
+				r.On("CreateWebhook", ctx, subscription).Return(service.WebhookSubscription{}, dummyErr)
+			},
+			wantErr: dummyErr,
+		}, {
+			name: "created",
+			req:  request,
+			expect: func(r *mocks.WebhookRepository) {
+				r.On("CreateWebhook", ctx, subscription).Return(created, nil)
+			},
+			wantHook: created,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := &mocks.WebhookRepository{}
+			if tc.expect != nil {
+				tc.expect(r)
+			}
+
+			svc := service.WebhookService{Repository: r}
+
+			got, err := svc.CreateWebhook(ctx, tc.req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantError != "" && err.Error() != tc.wantError {
+				t.Fatalf("unexpected error message: got: %q, want %q", err.Error(), tc.wantError)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantHook) {
+				t.Fatalf("unexpected webhook: \n got:  %+v \n want: %+v", got, tc.wantHook)
+			}
+		})
+	}
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx            = context.Background()
+		user           = service.User{ID: uuid.New()}
+		subscriptionID = uuid.New()
+		subscription   = service.WebhookSubscription{ID: subscriptionID, UserID: user.ID}
+		deliveries     = []service.WebhookDelivery{{ID: uuid.New(), SubscriptionID: subscriptionID}}
+	)
+
+	tt := []struct {
+		name           string
+		user           service.User
+		expect         func(*mocks.WebhookRepository)
+		wantDeliveries []service.WebhookDelivery
+		wantErr        error
+	}{
+		{
+			name: "not found",
+			user: user,
+			expect: func(r *mocks.WebhookRepository) {
+				r.On("GetWebhook", ctx, subscriptionID).Return(service.WebhookSubscription{}, service.ErrWebhookNotFound)
+			},
+			wantErr: service.ErrWebhookNotFound,
+		}, {
+			name: "another user",
+			user: service.User{ID: uuid.New()},
+			expect: func(r *mocks.WebhookRepository) {
+				r.On("GetWebhook", ctx, subscriptionID).Return(subscription, nil)
+			},
+			wantErr: service.ErrWebhookNotFound,
+		}, {
+			name: "listed",
+			user: user,
+			expect: func(r *mocks.WebhookRepository) {
+				r.On("GetWebhook", ctx, subscriptionID).Return(subscription, nil)
+				r.On("ListWebhookDeliveries", ctx, subscriptionID, service.MaxWebhookDeliveries).Return(deliveries, nil)
+			},
+			wantDeliveries: deliveries,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := &mocks.WebhookRepository{}
+			tc.expect(r)
+
+			svc := service.WebhookService{Repository: r}
+
+			got, err := svc.ListWebhookDeliveries(ctx, tc.user, subscriptionID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.wantDeliveries) {
+				t.Fatalf("unexpected deliveries: \n got:  %+v \n want: %+v", got, tc.wantDeliveries)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	t.Parallel()
+	const secret = "0123456789abcdef"
+
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+
+	tt := []struct {
+		name         string
+		status       int
+		attempts     int
+		wantStatus   service.WebhookDeliveryStatus
+		wantAttempt  int
+		wantBackoff  time.Duration
+		wantErrorMsg string
+	}{
+		{
+			name:        "delivered",
+			status:      http.StatusNoContent,
+			wantStatus:  service.WebhookDeliveryDelivered,
+			wantAttempt: 1,
+		}, {
+			name:         "first failure",
+			status:       http.StatusInternalServerError,
+			wantStatus:   service.WebhookDeliveryPending,
+			wantAttempt:  1,
+			wantBackoff:  time.Minute,
+			wantErrorMsg: "unexpected response status 500",
+		}, {
+			name:         "backoff doubles",
+			status:       http.StatusBadGateway,
+			attempts:     2,
+			wantStatus:   service.WebhookDeliveryPending,
+			wantAttempt:  3,
+			wantBackoff:  4 * time.Minute,
+			wantErrorMsg: "unexpected response status 502",
+		}, {
+			name:         "dead letter",
+			status:       http.StatusInternalServerError,
+			attempts:     4,
+			wantStatus:   service.WebhookDeliveryDead,
+			wantAttempt:  5,
+			wantErrorMsg: "unexpected response status 500",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var (
+				ctx      = context.Background()
+				requests = make(chan received, 1)
+			)
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				requests <- received{header: r.Header, body: b}
+				w.WriteHeader(tc.status)
+				if tc.status >= 300 {
+					_, _ = w.Write([]byte("boom"))
+				}
+			}))
+			defer receiver.Close()
+
+			pending := service.PendingWebhook{
+				Delivery: service.WebhookDelivery{
+					ID:        uuid.New(),
+					EventID:   uuid.New(),
+					EventType: service.DepositCreatedEvent,
+					Payload:   json.RawMessage(`{"amount":10}`),
+					Status:    service.WebhookDeliveryPending,
+					Attempts:  tc.attempts,
+					CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				Subscription: service.WebhookSubscription{URL: receiver.URL, Secret: secret},
+			}
+
+			var (
+				gotDelivery service.WebhookDelivery
+				gotAttempt  service.WebhookDeliveryAttempt
+			)
+			outbox := &mocks.WebhookOutbox{}
+			outbox.On("ClaimWebhookDeliveries", ctx, mock.Anything, mock.Anything, 10).
+				Return([]service.PendingWebhook{pending}, nil)
+			outbox.On("RecordWebhookAttempt", ctx, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					gotDelivery = args.Get(1).(service.WebhookDelivery)
+					gotAttempt = args.Get(2).(service.WebhookDeliveryAttempt)
+				}).
+				Return(nil)
+
+			d := service.WebhookDispatcher{
+				Outbox:      outbox,
+				Client:      service.NewWebhookClient(time.Second, loopback(t)),
+				MaxAttempts: 5,
+				Backoff:     time.Minute,
+				BatchSize:   10,
+			}
+			if err := d.DispatchPending(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			req := <-requests
+			wantBody := fmt.Sprintf(`{"id":"%s","type":"deposit.created","created_at":"2030-01-01T00:00:00Z","data":{"amount":10}}`,
+				pending.Delivery.EventID)
+			if got := string(req.body); got != wantBody {
+				t.Fatalf("unexpected body: \n got:  %s \n want: %s", got, wantBody)
+			}
+			if got := req.header.Get(service.WebhookEventHeader); got != "deposit.created" {
+				t.Fatalf("unexpected event header: %s", got)
+			}
+			if got := req.header.Get(service.WebhookDeliveryHeader); got != pending.Delivery.ID.String() {
+				t.Fatalf("unexpected delivery header: %s", got)
+			}
+			verifySignature(t, req.header.Get(service.WebhookSignatureHeader), secret, req.body)
+
+			if gotDelivery.Status != tc.wantStatus {
+				t.Fatalf("unexpected status: got: %s, want %s", gotDelivery.Status, tc.wantStatus)
+			}
+			if gotDelivery.Attempts != tc.wantAttempt || gotAttempt.Attempt != tc.wantAttempt {
+				t.Fatalf("unexpected attempt: got: %d, %d, want %d", gotDelivery.Attempts, gotAttempt.Attempt, tc.wantAttempt)
+			}
+			if gotAttempt.StatusCode == nil || *gotAttempt.StatusCode != tc.status {
+				t.Fatalf("unexpected attempt status code: got: %v, want %d", gotAttempt.StatusCode, tc.status)
+			}
+			if got := gotDelivery.DeliveredAt != nil; got != (tc.wantStatus == service.WebhookDeliveryDelivered) {
+				t.Fatalf("unexpected delivery date: %v", gotDelivery.DeliveredAt)
+			}
+			if tc.wantErrorMsg == "" && gotAttempt.Error != nil {
+				t.Fatalf("unexpected attempt error: %s", *gotAttempt.Error)
+			}
+			if tc.wantErrorMsg != "" && (gotAttempt.Error == nil || *gotAttempt.Error != tc.wantErrorMsg) {
+				t.Fatalf("unexpected attempt error: got: %v, want %s", gotAttempt.Error, tc.wantErrorMsg)
+			}
+			if tc.wantBackoff > 0 {
+				if got := gotDelivery.NextAttemptAt.Sub(gotAttempt.Date); got != tc.wantBackoff {
+					t.Fatalf("unexpected backoff: got: %v, want %v", got, tc.wantBackoff)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcherConnectionError(t *testing.T) {
+	t.Parallel()
+	var ctx = context.Background()
+
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	pending := service.PendingWebhook{
+		Delivery:     service.WebhookDelivery{ID: uuid.New(), Status: service.WebhookDeliveryPending},
+		Subscription: service.WebhookSubscription{URL: receiver.URL},
+	}
+
+	var (
+		gotDelivery service.WebhookDelivery
+		gotAttempt  service.WebhookDeliveryAttempt
+	)
+	outbox := &mocks.WebhookOutbox{}
+	outbox.On("ClaimWebhookDeliveries", ctx, mock.Anything, mock.Anything, service.DefaultWebhookBatchSize).
+		Return([]service.PendingWebhook{pending}, nil)
+	outbox.On("RecordWebhookAttempt", ctx, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			gotDelivery = args.Get(1).(service.WebhookDelivery)
+			gotAttempt = args.Get(2).(service.WebhookDeliveryAttempt)
+		}).
+		Return(nil)
+
+	d := service.WebhookDispatcher{
+		Outbox: outbox,
+		Client: service.NewWebhookClient(time.Second, loopback(t)),
+	}
+	if err := d.DispatchPending(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotDelivery.Status != service.WebhookDeliveryPending || gotDelivery.Attempts != 1 {
+		t.Fatalf("unexpected delivery: %+v", gotDelivery)
+	}
+	if got := gotDelivery.NextAttemptAt.Sub(gotAttempt.Date); got != service.DefaultWebhookBackoff {
+		t.Fatalf("unexpected backoff: got: %v, want %v", got, service.DefaultWebhookBackoff)
+	}
+	if gotAttempt.StatusCode != nil || gotAttempt.Error == nil {
+		t.Fatalf("unexpected attempt: %+v", gotAttempt)
+	}
+}
+
+func TestWebhookClient(t *testing.T) {
+	t.Parallel()
+
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	defer receiver.Close()
+
+	tt := []struct {
+		name    string
+		url     string
+		allowed []*net.IPNet
+		wantErr error
+	}{
+		{
+			name:    "loopback",
+			url:     receiver.URL,
+			wantErr: service.ErrWebhookAddressNotAllowed,
+		},
+		{
+			name:    "localhost",
+			url:     strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1),
+			wantErr: service.ErrWebhookAddressNotAllowed,
+		},
+		{
+			name:    "cloud metadata",
+			url:     "http://169.254.169.254/latest/meta-data/",
+			wantErr: service.ErrWebhookAddressNotAllowed,
+		},
+		{
+			name:    "private network",
+			url:     "http://10.0.0.1/",
+			wantErr: service.ErrWebhookAddressNotAllowed,
+		},
+		{
+			name:    "private network mapped to IPv6",
+			url:     "http://[::ffff:192.168.1.1]/",
+			wantErr: service.ErrWebhookAddressNotAllowed,
+		},
+		{
+			name:    "IPv6 loopback",
+			url:     "http://[::1]/",
+			wantErr: service.ErrWebhookAddressNotAllowed,
+		},
+		{
+			name:    "allowed network",
+			url:     receiver.URL,
+			allowed: []*net.IPNet{loopback(t)},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			client := service.NewWebhookClient(time.Second, tc.allowed...)
+			res, err := client.Post(tc.url, "application/json", nil)
+			if err == nil {
+				_ = res.Body.Close()
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got: %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func loopback(t *testing.T) *net.IPNet {
+	t.Helper()
+	_, n, err := net.ParseCIDR("127.0.0.0/8")
+	if err != nil {
+		t.Fatalf("cannot parse network: %v", err)
+	}
+	return n
+}
+
+func verifySignature(t *testing.T, header, secret string, body []byte) {
+	t.Helper()
+
+	parts := strings.Split(header, ",")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "t=") || !strings.HasPrefix(parts[1], "v1=") {
+		t.Fatalf("unexpected signature header: %s", header)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strings.TrimPrefix(parts[0], "t=") + "."))
+	_, _ = mac.Write(body)
+	if want := hex.EncodeToString(mac.Sum(nil)); strings.TrimPrefix(parts[1], "v1=") != want {
+		t.Fatalf("invalid signature: got %s, want %s", parts[1], want)
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx      = context.Background()
+		dummyErr = fmt.Errorf("dummy error")
+		e        = service.Event{ID: uuid.New(), WalletID: uuid.New(), Type: service.TransferCreatedEvent}
+	)
+
+	outbox := &mocks.WebhookOutbox{}
+	outbox.On("EnqueueWebhooks", ctx, e).Return(dummyErr)
+
+	p := service.WebhookPublisher{Outbox: outbox}
+	if err := p.Publish(ctx, e); !errors.Is(err, dummyErr) {
+		t.Fatalf("unexpected error: got: %v, want %v", err, dummyErr)
+	}
+	outbox.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName name of the tracer of the database queries.
+const tracerName = "github.com/hmoragrega/paybile/tracing"
+
+// InstrumentConnector returns a connector whose connections start a
+// span for every query, statement and transaction commit or rollback
+// executed by the repositories, as a child of the span in the context.
+func InstrumentConnector(c driver.Connector) driver.Connector {
+	return &connector{Connector: c}
+}
+
+type connector struct {
+	driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{Conn: cn}, nil
+}
+
+// conn forwards the calls to the driver connection, tracing
+// the queries. Prepared statements are not traced.
+type conn struct {
+	driver.Conn
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx, err error) {
+	_, end := startSpan(ctx, "BEGIN")
+	defer end(&err)
+
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(ctx, opts)
+	} else if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		err = errors.New("the driver does not support transaction options")
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction{Tx: tx, ctx: ctx}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Rows, err error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, end := startSpan(ctx, queryOperation(query), semconv.DBStatementKey.String(query))
+	defer end(&err)
+
+	return q.QueryContext(ctx, query, args)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Result, err error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, end := startSpan(ctx, queryOperation(query), semconv.DBStatementKey.String(query))
+	defer end(&err)
+
+	return e.ExecContext(ctx, query, args)
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+// transaction traces the commit and rollback of a transaction
+// with the context it was started with.
+type transaction struct {
+	driver.Tx
+	ctx context.Context
+}
+
+func (t *transaction) Commit() (err error) {
+	_, end := startSpan(t.ctx, "COMMIT")
+	defer end(&err)
+
+	return t.Tx.Commit()
+}
+
+func (t *transaction) Rollback() (err error) {
+	_, end := startSpan(t.ctx, "ROLLBACK")
+	defer end(&err)
+
+	return t.Tx.Rollback()
+}
+
+// startSpan starts the client span of a database call, the
+// returned function ends it recording the error of the call.
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, func(*error)) {
+	attrs = append(attrs,
+		semconv.DBSystemPostgres,
+		semconv.DBOperationKey.String(operation),
+	)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, func(err *error) {
+		if *err != nil && *err != driver.ErrSkip {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+// queryOperation returns the SQL command of the query.
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,133 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/hmoragrega/paybile/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errDummy = errors.New("dummy error")
+
+func TestInstrumentConnector(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	db := sql.OpenDB(tracing.InstrumentConnector(fakeConnector{}))
+	defer db.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("cannot begin transaction: %v", err)
+	}
+	if _, err = tx.ExecContext(ctx, "INSERT INTO foo VALUES ($1)", 1); err != nil {
+		t.Fatalf("cannot exec: %v", err)
+	}
+	rows, err := tx.QueryContext(ctx, "\n\tSELECT id FROM foo")
+	if err != nil {
+		t.Fatalf("cannot query: %v", err)
+	}
+	_ = rows.Close()
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("cannot commit: %v", err)
+	}
+	if _, err = db.ExecContext(ctx, "DELETE FROM foo"); !errors.Is(err, errDummy) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parent.End()
+
+	var (
+		names    []string
+		statuses []codes.Code
+	)
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "parent" {
+			continue
+		}
+		if got, want := s.Parent.SpanID(), parent.SpanContext().SpanID(); got != want {
+			t.Fatalf("unexpected parent span of %s: \n got:  %+v \n want: %+v", s.Name, got, want)
+		}
+		names = append(names, s.Name)
+		statuses = append(statuses, s.StatusCode)
+	}
+
+	wantNames := []string{"postgres BEGIN", "postgres INSERT", "postgres SELECT", "postgres COMMIT", "postgres DELETE"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("unexpected spans: \n got:  %+v \n want: %+v", names, wantNames)
+	}
+	wantStatuses := []codes.Code{codes.Unset, codes.Unset, codes.Unset, codes.Unset, codes.Error}
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Fatalf("unexpected span statuses: \n got:  %+v \n want: %+v", statuses, wantStatuses)
+	}
+}
+
+type fakeConnector struct{}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "DELETE FROM foo" {
+		return nil, errDummy
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (fakeRows) Close() error {
+	return nil
+}
+
+func (fakeRows) Next([]driver.Value) error {
+	return io.EOF
+}
@@ -0,0 +1,78 @@
+// Package tracing configures the OpenTelemetry tracing of the service
+// and traces the database queries.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// Supported span exporters.
+const (
+	// ExporterNone does not export the spans.
+	ExporterNone = "none"
+	// ExporterStdout prints the spans, useful for local debugging.
+	ExporterStdout = "stdout"
+	// ExporterOTLP sends the spans to an OTLP gRPC collector.
+	ExporterOTLP = "otlp"
+)
+
+// serviceName name of the service in the exported spans.
+const serviceName = "paybile"
+
+// Config configures the span exporter.
+type Config struct {
+	// Exporter one of ExporterNone, ExporterStdout or ExporterOTLP.
+	Exporter string
+	// OTLPEndpoint address of the OTLP collector.
+	OTLPEndpoint string
+}
+
+// Setup registers the global tracer provider and the W3C trace
+// context propagator. The returned function flushes the pending
+// spans and stops the exporter.
+func Setup(ctx context.Context, conf Config) (func(context.Context) error, error) {
+	// The trace context is propagated even if the spans are not exported.
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	switch conf.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		e, err := stdout.NewExporter(stdout.WithWriter(os.Stdout), stdout.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("cannot create stdout exporter: %w", err)
+		}
+		exporter = e
+	case ExporterOTLP:
+		e, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(
+			otlpgrpc.WithInsecure(),
+			otlpgrpc.WithEndpoint(conf.OTLPEndpoint),
+		))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create OTLP exporter: %w", err)
+		}
+		exporter = e
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", conf.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,55 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hmoragrega/paybile/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	tt := []struct {
+		name    string
+		conf    tracing.Config
+		wantErr bool
+	}{
+		{
+			name: "no exporter",
+			conf: tracing.Config{},
+		}, {
+			name: "none exporter",
+			conf: tracing.Config{Exporter: tracing.ExporterNone},
+		}, {
+			name: "stdout exporter",
+			conf: tracing.Config{Exporter: tracing.ExporterStdout},
+		}, {
+			name:    "unknown exporter",
+			conf:    tracing.Config{Exporter: "foo"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			shutdown, err := tracing.Setup(ctx, tc.conf)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if err = shutdown(ctx); err != nil {
+				t.Fatalf("cannot shutdown: %v", err)
+			}
+			if _, ok := otel.GetTextMapPropagator().(propagation.TraceContext); !ok {
+				t.Fatalf("unexpected propagator: %T", otel.GetTextMapPropagator())
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// userIDCtxKey context key that holds the user request.
+type userIDCtxKey struct{}
+
+// requestIDKey metadata with the ID of the request, the client
+// can send it and it is returned in the response headers.
+const requestIDKey = "x-request-id"
+
+type ApiService struct {
+	pb.UnimplementedWalletServiceServer
+
+	LoginService      LoginService
+	WalletGetter      WalletGetter
+	TransactionLister TransactionLister
+	TransferCreator   TransferCreator
+	// Auditor optional audit log of the denied requests.
+	Auditor service.Auditor
+	// PageLimits of the lists, DefaultPerPage and MaxPerPage by default.
+	PageLimits service.PageLimits
+	// RateLimiter optional rate limit of the calls, the policies
+	// of the methods are keyed by their full name, like
+	// "/paybile.v1.WalletService/TransferFunds".
+	RateLimiter *httptransport.RateLimiter
+}
+
+// Server returns a gRPC server with the wallet service registered.
+// The interceptors given in the options, like the metrics, run
+// before the ones of the API.
+func (svc *ApiService) Server(opt ...grpc.ServerOption) *grpc.Server {
+	opt = append(opt, grpc.ChainUnaryInterceptor(
+		svc.auditMetadataInterceptor(),
+		svc.tracingInterceptor(),
+		svc.ipRateLimitInterceptor(),
+		svc.authInterceptor(),
+		svc.rateLimitInterceptor(),
+	))
+
+	s := grpc.NewServer(opt...)
+	pb.RegisterWalletServiceServer(s, svc)
+
+	return s
+}
+
+var (
+	unauthorizedErr = apiError{
+		Code:  codes.Unauthenticated,
+		Key:   "unauthorized",
+		error: errors.New("unauthorized"),
+	}
+	forbiddenErr = apiError{
+		Code:  codes.PermissionDenied,
+		Key:   "forbidden",
+		error: errors.New("forbidden"),
+	}
+	notFoundErr = apiError{
+		Code:  codes.NotFound,
+		Key:   "not_found",
+		error: errors.New("not found"),
+	}
+	invalidArgumentErr = apiError{
+		Code:  codes.InvalidArgument,
+		Key:   "invalid_parameter",
+		error: errors.New("invalid argument"),
+	}
+	serverErr = apiError{
+		Code:  codes.Internal,
+		Key:   "internal_error",
+		error: errors.New("internal server error"),
+	}
+)
+
+// apiError the gRPC counterpart of the HTTP API errors.
+type apiError struct {
+	Code codes.Code
+	// Key stable code of the error, the same code of the HTTP API.
+	Key string
+	error
+}
+
+func (e apiError) Err(err error) apiError {
+	e.error = err
+	return e
+}
+
+// statusError logs the error and returns the status to send to the client.
+func statusError(ctx context.Context, apiError apiError, err error) error {
+	var e *zerolog.Event
+	if apiError.Code == codes.Internal {
+		e = log.Error()
+	} else {
+		e = log.Warn()
+	}
+
+	method, _ := grpc.Method(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		e = e.Str("trace_id", sc.TraceID().String())
+	}
+	e.Stack().
+		Str("request_id", service.AuditMetadataFromContext(ctx).RequestID).
+		Str("method", method).
+		Str("key", apiError.Key).
+		Err(apiError).
+		Err(err).
+		Msg("API error")
+
+	return status.Error(apiError.Code, apiError.Error())
+}
+
+type LoginService interface {
+	Login(ctx context.Context, login, password string) (service.User, error)
+}
+
+// authInterceptor authenticates the calls with the basic
+// auth credentials sent in the "authorization" metadata.
+func (svc *ApiService) authInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		user, pass, ok := basicAuth(ctx)
+		if !ok {
+			return nil, statusError(ctx, unauthorizedErr, fmt.Errorf("no valid basic auth found"))
+		}
+
+		u, err := svc.LoginService.Login(ctx, user, pass)
+		if err != nil {
+			var locked *service.LoginLockedError
+			if errors.As(err, &locked) {
+				_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter(time.Now(), locked.Until)))
+			}
+			return nil, serviceStatusError(ctx, err)
+		}
+
+		// Store the request user in the context.
+		ctx = context.WithValue(ctx, userIDCtxKey{}, u)
+		res, err := handler(ctx, req)
+
+		// The user is authenticated, so a permission denied
+		// status means it cannot access the resource.
+		if status.Code(err) == codes.PermissionDenied {
+			service.Audit(ctx, svc.Auditor, service.AuditEntry{
+				Actor:    u.ID.String(),
+				Action:   service.AuditAccessDenied,
+				Resource: info.FullMethod,
+				Outcome:  service.AuditDenied,
+			})
+		}
+
+		return res, err
+	}
+}
+
+// auditMetadataInterceptor stores the metadata of the call in the
+// context, so the audited actions can be traced back to it.
+func (svc *ApiService) auditMetadataInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var m service.AuditMetadata
+		if p, ok := peer.FromContext(ctx); ok {
+			m.IP = p.Addr.String()
+			if ip, _, err := net.SplitHostPort(m.IP); err == nil {
+				m.IP = ip
+			}
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("user-agent"); len(values) > 0 {
+			m.UserAgent = values[0]
+		}
+		if values := md.Get(requestIDKey); len(values) > 0 && values[0] != "" {
+			m.RequestID = values[0]
+		} else {
+			m.RequestID = uuid.New().String()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, m.RequestID))
+
+		return handler(service.ContextWithAuditMetadata(ctx, m), req)
+	}
+}
+
+// basicAuth returns the credentials of the "authorization" metadata.
+func basicAuth(ctx context.Context) (login, password string, ok bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", "", false
+	}
+
+	const prefix = "Basic "
+	auth := values[0]
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	c, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	cs := string(c)
+	i := strings.IndexByte(cs, ':')
+	if i < 0 {
+		return "", "", false
+	}
+
+	return cs[:i], cs[i+1:], true
+}
+
+func requestUser(ctx context.Context) service.User {
+	return ctx.Value(userIDCtxKey{}).(service.User)
+}
+
+// retryAfter returns the seconds from now until
+// the given time, rounded up, at least one.
+func retryAfter(now, t time.Time) string {
+	seconds := int(math.Ceil(t.Sub(now).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
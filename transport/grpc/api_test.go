@@ -0,0 +1,207 @@
+package grpc_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	servicemocks "github.com/hmoragrega/paybile/service/mocks"
+	grpctransport "github.com/hmoragrega/paybile/transport/grpc"
+	"github.com/hmoragrega/paybile/transport/grpc/mocks"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	login = "foo"
+	pass  = "bar"
+)
+
+var user = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	m.Run()
+}
+
+// dial serves the API in memory and returns a client connected to it.
+func dial(t *testing.T, api *grpctransport.ApiService, opt ...grpc.DialOption) pb.WalletServiceClient {
+	l := bufconn.Listen(1024 * 1024)
+	s := api.Server()
+	go func() {
+		_ = s.Serve(l)
+	}()
+	t.Cleanup(s.Stop)
+
+	opt = append(opt,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.DialContext(context.Background(), "bufnet", opt...)
+	if err != nil {
+		t.Fatalf("cannot dial gRPC server: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewWalletServiceClient(conn)
+}
+
+// authContext returns a context with the basic auth credentials.
+func authContext(login, pass string) context.Context {
+	auth := base64.StdEncoding.EncodeToString([]byte(login + ":" + pass))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+auth)
+}
+
+func assertStatus(t *testing.T, err error, wantCode codes.Code, wantMessage string) {
+	t.Helper()
+	s, _ := status.FromError(err)
+	if s.Code() != wantCode || s.Message() != wantMessage {
+		t.Fatalf("unexpected status: \n got:  %v %q \n want: %v %q", s.Code(), s.Message(), wantCode, wantMessage)
+	}
+}
+
+func TestServiceErrors(t *testing.T) {
+	t.Parallel()
+	walletID := uuid.New()
+	for code, err := range service.Errors() {
+		code, err := code, err
+		t.Run(string(code), func(t *testing.T) {
+			t.Parallel()
+			ls := &mocks.LoginService{}
+			ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			wg := &mocks.WalletGetter{}
+			wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, fmt.Errorf("cannot get wallet: %w", err))
+
+			c := dial(t, &grpctransport.ApiService{
+				LoginService: ls,
+				WalletGetter: wg,
+			})
+			_, err := c.GetWallet(authContext(login, pass), &pb.GetWalletRequest{WalletId: walletID.String()})
+			if s, _ := status.FromError(err); s.Code() == codes.OK || s.Code() == codes.Internal {
+				t.Fatalf("unregistered service error: %v", s)
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name        string
+		ctx         context.Context
+		expect      func(ls *mocks.LoginService)
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "missing credentials",
+			ctx:         context.Background(),
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "unauthorized",
+		},
+		{
+			name:        "invalid credentials",
+			ctx:         metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic foo"),
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "unauthorized",
+		},
+		{
+			name: "user not found",
+			ctx:  authContext(login, pass),
+			expect: func(ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(service.User{}, fmt.Errorf("cannot login user: %w", service.ErrUserNotFound))
+			},
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "unauthorized",
+		},
+		{
+			name: "wrong password",
+			ctx:  authContext(login, pass),
+			expect: func(ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(service.User{}, fmt.Errorf("cannot login user: %w", service.ErrInvalidPassword))
+			},
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "forbidden",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(ls)
+			}
+
+			c := dial(t, &grpctransport.ApiService{LoginService: ls})
+			_, err := c.GetWallet(tc.ctx, &pb.GetWalletRequest{WalletId: uuid.New().String()})
+			assertStatus(t, err, tc.wantCode, tc.wantMessage)
+		})
+	}
+}
+
+func TestLoginLocked(t *testing.T) {
+	t.Parallel()
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, login, pass).Return(service.User{}, fmt.Errorf("cannot login user: %w", &service.LoginLockedError{
+		Until: time.Now().Add(90 * time.Second),
+	}))
+
+	c := dial(t, &grpctransport.ApiService{LoginService: ls})
+	var header metadata.MD
+	_, err := c.GetWallet(authContext(login, pass), &pb.GetWalletRequest{WalletId: uuid.New().String()}, grpc.Header(&header))
+	assertStatus(t, err, codes.ResourceExhausted, service.ErrLoginLocked.Error())
+	if got := header.Get("retry-after"); len(got) != 1 || (got[0] != "90" && got[0] != "89") {
+		t.Fatalf("unexpected retry after: %v", got)
+	}
+}
+
+func TestAccessDeniedAudit(t *testing.T) {
+	t.Parallel()
+	const requestID = "d6b1d0c2-3c5e-4f0d-8d8b-3a8f0b4c1e2a"
+	walletID := uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+	wg := &mocks.WalletGetter{}
+	wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, service.ErrWalletAccessDenied)
+	a := &servicemocks.Auditor{}
+	a.On("Audit", mock.Anything, mock.MatchedBy(func(e service.AuditEntry) bool {
+		return e.Actor == user.ID.String() &&
+			e.Action == service.AuditAccessDenied &&
+			e.Resource == "/paybile.v1.WalletService/GetWallet" &&
+			e.Outcome == service.AuditDenied &&
+			e.RequestID == requestID &&
+			strings.HasPrefix(e.UserAgent, "paybile-test")
+	})).Return(nil)
+
+	c := dial(t, &grpctransport.ApiService{
+		LoginService: ls,
+		WalletGetter: wg,
+		Auditor:      a,
+	}, grpc.WithUserAgent("paybile-test"))
+	ctx := metadata.AppendToOutgoingContext(authContext(login, pass), "x-request-id", requestID)
+	var header metadata.MD
+	_, err := c.GetWallet(ctx, &pb.GetWalletRequest{WalletId: walletID.String()}, grpc.Header(&header))
+	assertStatus(t, err, codes.PermissionDenied, service.ErrWalletAccessDenied.Error())
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != requestID {
+		t.Fatalf("unexpected request ID header: %v", got)
+	}
+	a.AssertExpectations(t)
+}
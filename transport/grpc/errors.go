@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/hmoragrega/paybile/service"
+	"google.golang.org/grpc/codes"
+)
+
+// serviceErrors maps the codes of the service errors to the status
+// codes returned to the clients, the equivalent of the HTTP statuses.
+//
+// When the API error has no message, the message displayed to the
+// user is the message of the service error.
+var serviceErrors = map[service.ErrorCode]apiError{
+	// users
+	service.CodeUnauthorized: unauthorizedErr,
+	service.CodeForbidden:    forbiddenErr,
+	service.CodeLoginLocked:  {Code: codes.ResourceExhausted},
+
+	// wallets and transfers
+	service.CodeWalletNotFound:         {Code: codes.NotFound},
+	service.CodeWalletAccessDenied:     {Code: codes.PermissionDenied},
+	service.CodeInvalidAmount:          {Code: codes.InvalidArgument},
+	service.CodeInsufficientFunds:      {Code: codes.FailedPrecondition},
+	service.CodeSameWallets:            {Code: codes.InvalidArgument},
+	service.CodeInvalidExecutionDate:   {Code: codes.InvalidArgument},
+	service.CodeTransferNotFound:       {Code: codes.NotFound},
+	service.CodeTransferNotCancellable: {Code: codes.FailedPrecondition},
+	service.CodeTransactionNotFound:    {Code: codes.NotFound},
+
+	// lists
+	service.CodeInvalidListOrder:   {Code: codes.InvalidArgument},
+	service.CodeInvalidPerPage:     {Code: codes.InvalidArgument},
+	service.CodeMaxPerPageExceeded: {Code: codes.InvalidArgument},
+	service.CodeInvalidExpand:      {Code: codes.InvalidArgument},
+
+	// batches
+	service.CodeInvalidBatch:  {Code: codes.InvalidArgument},
+	service.CodeBatchNotFound: {Code: codes.NotFound},
+
+	// standing orders
+	service.CodeInvalidRecurrence:      {Code: codes.InvalidArgument},
+	service.CodeStandingOrderNotFound:  {Code: codes.NotFound},
+	service.CodeStandingOrderNotActive: {Code: codes.FailedPrecondition},
+
+	// payment requests
+	service.CodePaymentRequestNotFound:   {Code: codes.NotFound},
+	service.CodePaymentRequestNotPending: {Code: codes.FailedPrecondition},
+	service.CodePaymentRequestExpired:    {Code: codes.FailedPrecondition},
+	service.CodeInvalidExpirationDate:    {Code: codes.InvalidArgument},
+
+	// webhooks
+	service.CodeInvalidWebhook:  {Code: codes.InvalidArgument},
+	service.CodeWebhookNotFound: {Code: codes.NotFound},
+}
+
+// serviceStatusError logs the error and returns the status registered for
+// the service error, or an internal server error if there is none.
+func serviceStatusError(ctx context.Context, err error) error {
+	return statusError(ctx, serviceAPIError(err), err)
+}
+
+// serviceAPIError returns the API error of the service error code.
+func serviceAPIError(err error) apiError {
+	code, public, ok := service.ErrorCodeOf(err)
+	if !ok {
+		return serverErr
+	}
+	apiErr, ok := serviceErrors[code]
+	if !ok {
+		return serverErr
+	}
+	apiErr.Key = string(code)
+	if apiErr.error == nil {
+		apiErr.error = public
+	}
+
+	return apiErr
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// TransferFundsMethod full name of the method that moves funds,
+// it usually has a stricter rate limit policy.
+const TransferFundsMethod = "/paybile.v1.WalletService/TransferFunds"
+
+var rateLimitedErr = apiError{
+	Code:  codes.ResourceExhausted,
+	Key:   "rate_limited",
+	error: errors.New("too many requests"),
+}
+
+// ipRateLimitInterceptor rejects the calls of the client IPs that
+// exceed the IP policy, it runs before the authentication so the
+// calls with wrong credentials are limited too.
+func (svc *ApiService) ipRateLimitInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		l := svc.RateLimiter
+		if l == nil || l.IP.Limit <= 0 {
+			return handler(ctx, req)
+		}
+
+		now := time.Now()
+		ip := service.AuditMetadataFromContext(ctx).IP
+		res, err := l.Store.Take(ctx, "ip:"+ip, l.IP, now)
+		if err != nil {
+			log.Error().Err(err).Str("ip", ip).Msg("cannot rate limit call")
+			return handler(ctx, req)
+		}
+		if !res.Allowed {
+			_ = grpc.SetHeader(ctx, rateLimitMetadata(l.IP, res, now))
+			return nil, statusError(ctx, rateLimitedErr, nil)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitInterceptor rejects the calls that exceed the policy of the
+// method, it runs after the authentication so the limits are applied
+// per user. The calls are allowed if the store fails.
+func (svc *ApiService) rateLimitInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		l := svc.RateLimiter
+		if l == nil {
+			return handler(ctx, req)
+		}
+
+		p := l.Policy(info.FullMethod)
+		if p.Limit <= 0 {
+			return handler(ctx, req)
+		}
+
+		now := time.Now()
+		res, err := l.Store.Take(ctx, info.FullMethod+" "+rateLimitKey(ctx), p, now)
+		if err != nil {
+			log.Error().Err(err).Str("method", info.FullMethod).Msg("cannot rate limit call")
+			return handler(ctx, req)
+		}
+
+		_ = grpc.SetHeader(ctx, rateLimitMetadata(p, res, now))
+		if !res.Allowed {
+			return nil, statusError(ctx, rateLimitedErr, nil)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitMetadata returns the headers of the bucket, and
+// when to retry if the call was not allowed.
+func rateLimitMetadata(p httptransport.RateLimitPolicy, res httptransport.RateLimitResult, now time.Time) metadata.MD {
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(p.Limit),
+		"ratelimit-remaining", strconv.Itoa(res.Remaining),
+		"ratelimit-reset", strconv.Itoa(secondsUntil(now, res.Reset)),
+	)
+	if !res.Allowed {
+		md.Set("retry-after", strconv.Itoa(secondsUntil(now, res.RetryAt)))
+	}
+
+	return md
+}
+
+// rateLimitKey identifies the client of the call, the
+// authenticated user or the IP if there is none.
+func rateLimitKey(ctx context.Context) string {
+	if u, ok := ctx.Value(userIDCtxKey{}).(service.User); ok {
+		return "user:" + u.ID.String()
+	}
+	return "ip:" + service.AuditMetadataFromContext(ctx).IP
+}
+
+// secondsUntil returns the seconds from now until the given time, rounded up.
+func secondsUntil(now, t time.Time) int {
+	if !t.After(now) {
+		return 0
+	}
+	return int(math.Ceil(t.Sub(now).Seconds()))
+}
@@ -0,0 +1,103 @@
+package grpc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	grpctransport "github.com/hmoragrega/paybile/transport/grpc"
+	"github.com/hmoragrega/paybile/transport/grpc/mocks"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	t.Parallel()
+	var (
+		userB    = service.User{ID: uuid.MustParse("a0d4b8ec-5b8a-4f0e-9f51-6c3f3fb4a1a2")}
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		req      = &pb.GetWalletRequest{WalletId: walletID.String()}
+	)
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+	ls.On("Login", mock.Anything, "user_b", pass).Return(userB, nil)
+	wg := &mocks.WalletGetter{}
+	wg.On("GetWallet", mock.Anything, mock.Anything, walletID).Return(service.Wallet{ID: walletID}, nil)
+
+	c := dial(t, &grpctransport.ApiService{
+		LoginService: ls,
+		WalletGetter: wg,
+		RateLimiter: &httptransport.RateLimiter{
+			Store:   &httptransport.MemoryRateLimitStore{},
+			Default: httptransport.RateLimitPolicy{Limit: 1, Period: time.Minute},
+			Routes: map[string]httptransport.RateLimitPolicy{
+				grpctransport.TransferFundsMethod: {Limit: 5, Period: time.Minute},
+			},
+		},
+	})
+
+	var header metadata.MD
+	if _, err := c.GetWallet(authContext(login, pass), req, grpc.Header(&header)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := header.Get("ratelimit-remaining"); len(got) != 1 || got[0] != "0" {
+		t.Fatalf("unexpected remaining calls: %v", got)
+	}
+
+	_, err := c.GetWallet(authContext(login, pass), req, grpc.Header(&header))
+	assertStatus(t, err, codes.ResourceExhausted, "too many requests")
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] != "60" {
+		t.Fatalf("unexpected retry after: %v", got)
+	}
+
+	// the limits are applied per user.
+	if _, err := c.GetWallet(authContext("user_b", pass), req); err != nil {
+		t.Fatalf("unexpected error of another user: %v", err)
+	}
+}
+
+func TestIPRateLimitInterceptor(t *testing.T) {
+	t.Parallel()
+	var (
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		req      = &pb.GetWalletRequest{WalletId: walletID.String()}
+	)
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+	ls.On("Login", mock.Anything, login, "wrong").Return(service.User{}, service.ErrInvalidPassword)
+	wg := &mocks.WalletGetter{}
+	wg.On("GetWallet", mock.Anything, mock.Anything, walletID).Return(service.Wallet{ID: walletID}, nil)
+
+	c := dial(t, &grpctransport.ApiService{
+		LoginService: ls,
+		WalletGetter: wg,
+		RateLimiter: &httptransport.RateLimiter{
+			Store:   &httptransport.MemoryRateLimitStore{},
+			IP:      httptransport.RateLimitPolicy{Limit: 2, Period: time.Minute},
+			Default: httptransport.RateLimitPolicy{Limit: 10, Period: time.Minute},
+		},
+	})
+
+	_, err := c.GetWallet(authContext(login, "wrong"), req)
+	assertStatus(t, err, codes.PermissionDenied, "forbidden")
+
+	if _, err := c.GetWallet(authContext(login, pass), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the IP is limited before the authentication.
+	var header metadata.MD
+	_, err = c.GetWallet(authContext(login, "wrong"), req, grpc.Header(&header))
+	assertStatus(t, err, codes.ResourceExhausted, "too many requests")
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] != "30" {
+		t.Fatalf("unexpected retry after: %v", got)
+	}
+	ls.AssertNumberOfCalls(t, "Login", 2)
+}
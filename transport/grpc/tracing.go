@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hmoragrega/paybile/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracerName name of the tracer of the gRPC calls.
+const tracerName = "github.com/hmoragrega/paybile/transport/grpc"
+
+// Attributes of the gRPC calls, so the spans can
+// be correlated with the logs and filtered by status.
+const (
+	requestIDAttr  = attribute.Key("rpc.request_id")
+	statusCodeAttr = attribute.Key("rpc.grpc.status_code")
+)
+
+// serverErrors status codes that are failures of the service,
+// the rest are caused by the client.
+var serverErrors = map[codes.Code]bool{
+	codes.Unknown:          true,
+	codes.DeadlineExceeded: true,
+	codes.Unimplemented:    true,
+	codes.Internal:         true,
+	codes.Unavailable:      true,
+	codes.DataLoss:         true,
+}
+
+// tracingInterceptor starts the server span of the call, continuing the
+// trace of the W3C trace context metadata if the client sent it.
+func (svc *ApiService) tracingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+		// The full method has the format "/package.service/method".
+		name := strings.TrimPrefix(info.FullMethod, "/")
+		attrs := []attribute.KeyValue{
+			semconv.RPCSystemKey.String("grpc"),
+			requestIDAttr.String(service.AuditMetadataFromContext(ctx).RequestID),
+		}
+		if i := strings.LastIndexByte(name, '/'); i >= 0 {
+			attrs = append(attrs,
+				semconv.RPCServiceKey.String(name[:i]),
+				semconv.RPCMethodKey.String(name[i+1:]),
+			)
+		}
+		ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		res, err := handler(ctx, req)
+
+		code := status.Code(err)
+		span.SetAttributes(statusCodeAttr.Int64(int64(code)))
+		if serverErrors[code] {
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+
+		return res, err
+	}
+}
+
+// metadataCarrier adapts the gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,77 @@
+package grpc_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	grpctransport "github.com/hmoragrega/paybile/transport/grpc"
+	"github.com/hmoragrega/paybile/transport/grpc/mocks"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	ctx := metadata.AppendToOutgoingContext(authContext(login, pass),
+		"traceparent", "00-"+traceID+"-"+parentSpanID+"-01",
+		"x-request-id", "d6b1d0c2-3c5e-4f0d-8d8b-3a8f0b4c1e2a",
+	)
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, login, pass).Return(service.User{}, service.ErrUserNotFound)
+
+	c := dial(t, &grpctransport.ApiService{LoginService: ls})
+	_, err := c.GetWallet(ctx, &pb.GetWalletRequest{WalletId: uuid.New().String()})
+	assertStatus(t, err, codes.Unauthenticated, "unauthorized")
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("unexpected number of spans: %d", len(spans))
+	}
+	span := spans[0]
+	if got, want := span.Name, "paybile.v1.WalletService/GetWallet"; got != want {
+		t.Fatalf("unexpected span name: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := span.SpanContext.TraceID().String(), traceID; got != want {
+		t.Fatalf("unexpected trace ID: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := span.Parent.SpanID().String(), parentSpanID; got != want {
+		t.Fatalf("unexpected parent span: \n got:  %+v \n want: %+v", got, want)
+	}
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if got, want := attrs["rpc.method"].AsString(), "GetWallet"; got != want {
+		t.Fatalf("unexpected method: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := attrs["rpc.request_id"].AsString(), "d6b1d0c2-3c5e-4f0d-8d8b-3a8f0b4c1e2a"; got != want {
+		t.Fatalf("unexpected request ID: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := attrs["rpc.grpc.status_code"].AsInt64(), int64(codes.Unauthenticated); got != want {
+		t.Fatalf("unexpected status code: \n got:  %+v \n want: %+v", got, want)
+	}
+	// client errors are not failures of the service.
+	if got := span.StatusCode; got == otelcodes.Error {
+		t.Fatalf("unexpected error status")
+	}
+}
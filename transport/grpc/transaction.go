@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type TransactionLister interface {
+	ListTransactions(ctx context.Context, user service.User, walletID uuid.UUID, opt service.ListOptions) (l service.TransactionList, err error)
+}
+
+func (svc *ApiService) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.TransactionList, error) {
+	walletID, err := uuid.Parse(req.WalletId)
+	if err != nil {
+		return nil, statusError(ctx, notFoundErr, nil)
+	}
+
+	opts := service.ListOptions{
+		Order:          service.Ascending,
+		PerPage:        int(req.PerPage),
+		ExpandTransfer: req.ExpandTransfer,
+	}
+	if req.Order == pb.ListOrder_LIST_ORDER_DESCENDING {
+		opts.Order = service.Descending
+	}
+	if opts.PerPage <= 0 || opts.PerPage > svc.PageLimits.MaxPerPage() {
+		opts.PerPage = svc.PageLimits.DefaultPerPage()
+	}
+	if req.FromId != "" {
+		x, err := uuid.Parse(req.FromId)
+		if err != nil {
+			return nil, statusError(ctx, invalidArgumentErr.Err(errors.New("from_id is not a valid UUID")), err)
+		}
+		opts.FromID = &x
+	}
+
+	l, err := svc.TransactionLister.ListTransactions(ctx, requestUser(ctx), walletID, opts)
+	if err != nil {
+		return nil, serviceStatusError(ctx, err)
+	}
+
+	res := &pb.TransactionList{
+		Results: make([]*pb.Transaction, 0, len(l.Results)),
+		NextId:  uuidOrNil(l.NextID),
+	}
+	for _, t := range l.Results {
+		res.Results = append(res.Results, encodeTransaction(t))
+	}
+
+	return res, nil
+}
+
+func encodeTransaction(t service.Transaction) *pb.Transaction {
+	x := &pb.Transaction{
+		Id:              t.ID.String(),
+		Amount:          t.Amount,
+		Balance:         t.Balance,
+		TransactionType: string(t.Type),
+		ReferenceId:     uuidOrNil(t.ReferenceID),
+		Date:            timestamppb.New(t.Date),
+	}
+	if t.Transfer != nil {
+		x.Transfer = &pb.TransactionTransfer{
+			CounterpartyWalletId: t.Transfer.CounterpartyWalletID.String(),
+			IssuerId:             t.Transfer.IssuerID.String(),
+			Message:              t.Transfer.Message,
+		}
+	}
+
+	return x
+}
+
+// uuidOrNil returns the string representation of the ID, if any.
+func uuidOrNil(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
@@ -0,0 +1,146 @@
+package grpc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	grpctransport "github.com/hmoragrega/paybile/transport/grpc"
+	"github.com/hmoragrega/paybile/transport/grpc/mocks"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestListTransactions(t *testing.T) {
+	t.Parallel()
+	var (
+		walletID     = uuid.MustParse("2f9b76dd-f689-456e-9080-6789718018a5")
+		referenceID  = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
+		nextID       = uuid.MustParse("ccf28188-92c7-4a30-8f60-70345694f893")
+		counterparty = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		message      = "dinner"
+		date         = time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC)
+		list         = service.TransactionList{
+			Results: []service.Transaction{{
+				ID:      uuid.MustParse("9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc"),
+				Amount:  20,
+				Balance: 20,
+				Type:    service.DepositType,
+				Date:    date,
+			}, {
+				ID:          uuid.MustParse("4bce4401-6b35-4fa1-94b9-ac5ce05d29b1"),
+				Amount:      -7.25,
+				Balance:     12.75,
+				Type:        service.TransferType,
+				ReferenceID: &referenceID,
+				Date:        date,
+				Transfer: &service.TransactionTransfer{
+					CounterpartyWalletID: counterparty,
+					IssuerID:             user.ID,
+					Message:              &message,
+				},
+			}},
+			NextID: &nextID,
+		}
+	)
+	tt := []struct {
+		name        string
+		req         *pb.ListTransactionsRequest
+		expect      func(tl *mocks.TransactionLister)
+		want        *pb.TransactionList
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "invalid wallet id",
+			req:         &pb.ListTransactionsRequest{WalletId: "foo"},
+			wantCode:    codes.NotFound,
+			wantMessage: "not found",
+		},
+		{
+			name: "invalid from id",
+			req: &pb.ListTransactionsRequest{
+				WalletId: walletID.String(),
+				FromId:   "foo",
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "from_id is not a valid UUID",
+		},
+		{
+			name: "wallet access denied",
+			req:  &pb.ListTransactionsRequest{WalletId: walletID.String()},
+			expect: func(tl *mocks.TransactionLister) {
+				tl.On("ListTransactions", mock.Anything, user, walletID, mock.Anything).
+					Return(service.TransactionList{}, service.ErrWalletAccessDenied)
+			},
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "wallet access denied",
+		},
+		{
+			name: "success",
+			req: &pb.ListTransactionsRequest{
+				WalletId:       walletID.String(),
+				Order:          pb.ListOrder_LIST_ORDER_DESCENDING,
+				PerPage:        100,
+				FromId:         referenceID.String(),
+				ExpandTransfer: true,
+			},
+			expect: func(tl *mocks.TransactionLister) {
+				tl.On("ListTransactions", mock.Anything, user, walletID, service.ListOptions{
+					FromID:         &referenceID,
+					Order:          service.Descending,
+					PerPage:        service.DefaultPerPage,
+					ExpandTransfer: true,
+				}).Return(list, nil)
+			},
+			want: &pb.TransactionList{
+				Results: []*pb.Transaction{{
+					Id:              "9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc",
+					Amount:          20,
+					Balance:         20,
+					TransactionType: "deposit",
+					Date:            timestamppb.New(date),
+				}, {
+					Id:              "4bce4401-6b35-4fa1-94b9-ac5ce05d29b1",
+					Amount:          -7.25,
+					Balance:         12.75,
+					TransactionType: "transfer",
+					ReferenceId:     proto.String(referenceID.String()),
+					Date:            timestamppb.New(date),
+					Transfer: &pb.TransactionTransfer{
+						CounterpartyWalletId: counterparty.String(),
+						IssuerId:             user.ID.String(),
+						Message:              proto.String(message),
+					},
+				}},
+				NextId: proto.String(nextID.String()),
+			},
+			wantCode: codes.OK,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tl := &mocks.TransactionLister{}
+			ls := &mocks.LoginService{}
+			ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			if tc.expect != nil {
+				tc.expect(tl)
+			}
+
+			c := dial(t, &grpctransport.ApiService{
+				LoginService:      ls,
+				TransactionLister: tl,
+			})
+			got, err := c.ListTransactions(authContext(login, pass), tc.req)
+			assertStatus(t, err, tc.wantCode, tc.wantMessage)
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("unexpected transactions: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+		})
+	}
+}
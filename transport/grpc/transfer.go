@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type TransferCreator interface {
+	TransferFunds(ctx context.Context, req service.TransferRequest) (t service.Transfer, err error)
+}
+
+func (svc *ApiService) TransferFunds(ctx context.Context, req *pb.TransferFundsRequest) (*pb.Transfer, error) {
+	originWalletID, err := uuid.Parse(req.OriginWalletId)
+	if err != nil {
+		return nil, statusError(ctx, notFoundErr, nil)
+	}
+	destinationWalletID, err := uuid.Parse(req.DestinationWalletId)
+	if err != nil {
+		return nil, statusError(ctx, invalidArgumentErr.Err(errors.New("destination_wallet_id is not a valid wallet ID")), err)
+	}
+	if req.ExecuteAt != nil {
+		if err = req.ExecuteAt.CheckValid(); err != nil {
+			return nil, statusError(ctx, invalidArgumentErr.Err(errors.New("execute_at is not a valid date")), err)
+		}
+	}
+
+	x := service.TransferRequest{
+		Issuer:              requestUser(ctx),
+		OriginWalletID:      originWalletID,
+		DestinationWalletID: destinationWalletID,
+		Amount:              req.Amount,
+		Message:             &req.Message,
+	}
+	if req.ExecuteAt != nil {
+		at := req.ExecuteAt.AsTime()
+		x.ExecuteAt = &at
+	}
+
+	t, err := svc.TransferCreator.TransferFunds(ctx, x)
+	if err != nil {
+		return nil, serviceStatusError(ctx, err)
+	}
+
+	return encodeTransfer(t), nil
+}
+
+func encodeTransfer(t service.Transfer) *pb.Transfer {
+	x := &pb.Transfer{
+		Id:                  t.ID.String(),
+		IssuerId:            t.IssuerID.String(),
+		OriginWalletId:      t.OriginWalletID.String(),
+		DestinationWalletId: t.DestinationWalletID.String(),
+		Amount:              t.Amount,
+		Message:             t.Message,
+		Date:                timestamppb.New(t.Date),
+		Status:              string(t.Status),
+		FailureReason:       t.FailureReason,
+		BatchId:             uuidOrNil(t.BatchID),
+	}
+	if t.ExecuteAt != nil {
+		x.ExecuteAt = timestamppb.New(*t.ExecuteAt)
+	}
+
+	return x
+}
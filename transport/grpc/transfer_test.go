@@ -0,0 +1,150 @@
+package grpc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	grpctransport "github.com/hmoragrega/paybile/transport/grpc"
+	"github.com/hmoragrega/paybile/transport/grpc/mocks"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTransferFunds(t *testing.T) {
+	t.Parallel()
+	var (
+		originID      = uuid.MustParse("2f9b76dd-f689-456e-9080-6789718018a5")
+		destinationID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		transferID    = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
+		message       = "dinner"
+		date          = time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC)
+		executeAt     = time.Date(2020, 9, 21, 10, 0, 0, 0, time.UTC)
+		req           = &pb.TransferFundsRequest{
+			OriginWalletId:      originID.String(),
+			DestinationWalletId: destinationID.String(),
+			Amount:              7.25,
+			Message:             message,
+		}
+		transferReq = service.TransferRequest{
+			Issuer:              user,
+			OriginWalletID:      originID,
+			DestinationWalletID: destinationID,
+			Amount:              7.25,
+			Message:             &message,
+		}
+	)
+	tt := []struct {
+		name        string
+		req         *pb.TransferFundsRequest
+		expect      func(tc *mocks.TransferCreator)
+		want        *pb.Transfer
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "invalid origin wallet id",
+			req:         &pb.TransferFundsRequest{OriginWalletId: "foo"},
+			wantCode:    codes.NotFound,
+			wantMessage: "not found",
+		},
+		{
+			name: "invalid destination wallet id",
+			req: &pb.TransferFundsRequest{
+				OriginWalletId:      originID.String(),
+				DestinationWalletId: "foo",
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "destination_wallet_id is not a valid wallet ID",
+		},
+		{
+			name: "invalid amount",
+			req:  req,
+			expect: func(tc *mocks.TransferCreator) {
+				tc.On("TransferFunds", mock.Anything, transferReq).Return(service.Transfer{}, service.ErrInvalidTransactionAmount)
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: service.ErrInvalidTransactionAmount.Error(),
+		},
+		{
+			name: "insufficient funds",
+			req:  req,
+			expect: func(tc *mocks.TransferCreator) {
+				tc.On("TransferFunds", mock.Anything, transferReq).Return(service.Transfer{}, service.ErrInsufficientFunds)
+			},
+			wantCode:    codes.FailedPrecondition,
+			wantMessage: service.ErrInsufficientFunds.Error(),
+		},
+		{
+			name: "wallet access denied",
+			req:  req,
+			expect: func(tc *mocks.TransferCreator) {
+				tc.On("TransferFunds", mock.Anything, transferReq).Return(service.Transfer{}, service.ErrWalletAccessDenied)
+			},
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "wallet access denied",
+		},
+		{
+			name: "scheduled transfer",
+			req: &pb.TransferFundsRequest{
+				OriginWalletId:      originID.String(),
+				DestinationWalletId: destinationID.String(),
+				Amount:              7.25,
+				Message:             message,
+				ExecuteAt:           timestamppb.New(executeAt),
+			},
+			expect: func(tc *mocks.TransferCreator) {
+				r := transferReq
+				r.ExecuteAt = &executeAt
+				tc.On("TransferFunds", mock.Anything, r).Return(service.Transfer{
+					ID:                  transferID,
+					IssuerID:            user.ID,
+					OriginWalletID:      originID,
+					DestinationWalletID: destinationID,
+					Amount:              7.25,
+					Message:             &message,
+					Date:                date,
+					Status:              service.TransferScheduled,
+					ExecuteAt:           &executeAt,
+				}, nil)
+			},
+			want: &pb.Transfer{
+				Id:                  transferID.String(),
+				IssuerId:            user.ID.String(),
+				OriginWalletId:      originID.String(),
+				DestinationWalletId: destinationID.String(),
+				Amount:              7.25,
+				Message:             proto.String(message),
+				Date:                timestamppb.New(date),
+				Status:              "scheduled",
+				ExecuteAt:           timestamppb.New(executeAt),
+			},
+			wantCode: codes.OK,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &mocks.TransferCreator{}
+			ls := &mocks.LoginService{}
+			ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			if tc.expect != nil {
+				tc.expect(tr)
+			}
+
+			c := dial(t, &grpctransport.ApiService{
+				LoginService:    ls,
+				TransferCreator: tr,
+			})
+			got, err := c.TransferFunds(authContext(login, pass), tc.req)
+			assertStatus(t, err, tc.wantCode, tc.wantMessage)
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("unexpected transfer: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+		})
+	}
+}
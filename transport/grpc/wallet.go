@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+)
+
+type WalletGetter interface {
+	GetWallet(ctx context.Context, user service.User, walletID uuid.UUID) (service.Wallet, error)
+}
+
+func (svc *ApiService) GetWallet(ctx context.Context, req *pb.GetWalletRequest) (*pb.Wallet, error) {
+	walletID, err := uuid.Parse(req.WalletId)
+	if err != nil {
+		return nil, statusError(ctx, notFoundErr, nil)
+	}
+
+	x, err := svc.WalletGetter.GetWallet(ctx, requestUser(ctx), walletID)
+	if err != nil {
+		return nil, serviceStatusError(ctx, err)
+	}
+
+	return &pb.Wallet{
+		Id:      x.ID.String(),
+		UserId:  x.UserID.String(),
+		Balance: x.Balance,
+	}, nil
+}
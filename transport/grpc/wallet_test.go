@@ -0,0 +1,103 @@
+package grpc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	grpctransport "github.com/hmoragrega/paybile/transport/grpc"
+	"github.com/hmoragrega/paybile/transport/grpc/mocks"
+	"github.com/hmoragrega/paybile/transport/grpc/pb"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetWallet(t *testing.T) {
+	t.Parallel()
+	var (
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		wallet   = service.Wallet{
+			ID:      walletID,
+			UserID:  user.ID,
+			Balance: 10.99,
+		}
+	)
+	tt := []struct {
+		name        string
+		req         *pb.GetWalletRequest
+		expect      func(wg *mocks.WalletGetter)
+		want        *pb.Wallet
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "invalid wallet id",
+			req:         &pb.GetWalletRequest{WalletId: "foo"},
+			wantCode:    codes.NotFound,
+			wantMessage: "not found",
+		},
+		{
+			name: "wallet not found",
+			req:  &pb.GetWalletRequest{WalletId: walletID.String()},
+			expect: func(wg *mocks.WalletGetter) {
+				wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, service.ErrWalletNotFound)
+			},
+			wantCode:    codes.NotFound,
+			wantMessage: "wallet not found",
+		},
+		{
+			name: "wallet access denied",
+			req:  &pb.GetWalletRequest{WalletId: walletID.String()},
+			expect: func(wg *mocks.WalletGetter) {
+				wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, service.ErrWalletAccessDenied)
+			},
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "wallet access denied",
+		},
+		{
+			name: "server error",
+			req:  &pb.GetWalletRequest{WalletId: walletID.String()},
+			expect: func(wg *mocks.WalletGetter) {
+				wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, errors.New("boom"))
+			},
+			wantCode:    codes.Internal,
+			wantMessage: "internal server error",
+		},
+		{
+			name: "success",
+			req:  &pb.GetWalletRequest{WalletId: walletID.String()},
+			expect: func(wg *mocks.WalletGetter) {
+				wg.On("GetWallet", mock.Anything, user, walletID).Return(wallet, nil)
+			},
+			want: &pb.Wallet{
+				Id:      walletID.String(),
+				UserId:  user.ID.String(),
+				Balance: 10.99,
+			},
+			wantCode: codes.OK,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			wg := &mocks.WalletGetter{}
+			ls := &mocks.LoginService{}
+			ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			if tc.expect != nil {
+				tc.expect(wg)
+			}
+
+			c := dial(t, &grpctransport.ApiService{
+				LoginService: ls,
+				WalletGetter: wg,
+			})
+			got, err := c.GetWallet(authContext(login, pass), tc.req)
+			assertStatus(t, err, tc.wantCode, tc.wantMessage)
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("unexpected wallet: \n got:  %+v \n want: %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -13,99 +16,173 @@ import (
 	"github.com/hmoragrega/paybile/service"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // userIDCtxKey context key that holds the user request.
 type userIDCtxKey struct{}
 
 type ApiService struct {
-	LoginService      LoginService
-	TransactionLister TransactionLister
-	TransferCreator   TransferCreator
-	WalletGetter      WalletGetter
+	LoginService        LoginService
+	TransactionLister   TransactionLister
+	TransferCreator     TransferCreator
+	TransferCanceller   TransferCanceller
+	WalletGetter        WalletGetter
+	BalanceGetter       BalanceGetter
+	Statements          StatementGenerator
+	TransactionExporter TransactionExporter
+	TransactionEvents   TransactionSubscriber
+	StandingOrders      StandingOrderManager
+	BatchTransferer     BatchTransferer
+	PaymentRequests     PaymentRequestManager
+	Webhooks            WebhookManager
+	// Auditor optional audit log of the denied requests.
+	Auditor service.Auditor
+	// PageLimits of the lists, DefaultPerPage and MaxPerPage by default.
+	PageLimits service.PageLimits
+	// RateLimiter optional rate limit of the requests.
+	RateLimiter *RateLimiter
+	// Timeout of the requests, except the streamed ones. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Readiness optional checks of the readiness probe.
+	Readiness ReadinessChecker
+	// ReadinessTimeout of the readiness checks. Defaults to DefaultReadinessTimeout.
+	ReadinessTimeout time.Duration
+
+	// draining set to 1 when the service is shutting down.
+	draining int32
 }
 
+// DefaultTimeout of the requests that are not streamed.
+const DefaultTimeout = 30 * time.Second
+
 func (svc *ApiService) Handler() http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.SetHeader("Content-Type", "application/json"))
 	r.Use(middleware.RequestID)
 	r.Use(svc.requestIDResponseHeader())
-	r.Use(middleware.Timeout(time.Second * 30))
+	r.Use(svc.auditMetadata())
 	r.Use(middleware.Heartbeat("/api/v1/health"))
+	r.Use(svc.probes())
+	r.Use(svc.tracingMiddleware())
+	r.Use(svc.ipRateLimitMiddleware())
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, notFoundErr, nil)
 	})
 	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, methodNotAllowedErr, nil)
 	})
+	r.With(svc.rateLimitMiddleware()).Get("/api/v1/openapi.json", OpenAPIHandler())
 
-	auth := r.With(svc.authMiddleware())
+	// Long running exports and live events stream their responses without timeout.
+	stream := r.With(svc.authMiddleware(), svc.rateLimitMiddleware())
+	stream.Get("/api/v1/wallet/{walletID}/transactions/export", ExportTransactionsHandler(svc.TransactionExporter))
+	stream.Get("/api/v1/wallet/{walletID}/events", WalletEventsHandler(svc.TransactionEvents))
+
+	timeout := svc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	auth := r.With(svc.authMiddleware(), svc.rateLimitMiddleware(), middleware.Timeout(timeout))
 
 	auth.Get("/api/v1/wallet/{walletID}", GetWalletHandler(svc.WalletGetter))
-	auth.Get("/api/v1/wallet/{walletID}/transactions", TransactionListHandler(svc.TransactionLister))
+	auth.Get("/api/v1/wallet/{walletID}/balance", GetBalanceHandler(svc.BalanceGetter))
+	auth.Get("/api/v1/wallet/{walletID}/statements/{month}", StatementHandler(svc.Statements))
+	auth.Get("/api/v1/wallet/{walletID}/transactions", TransactionListHandler(svc.TransactionLister, svc.PageLimits))
 	auth.Post("/api/v1/wallet/{walletID}/transfer", CreateTransferHandler(svc.TransferCreator))
+	auth.Post("/api/v1/wallet/{walletID}/transfer/{transferID}/cancel", CancelTransferHandler(svc.TransferCanceller))
+	auth.Post("/api/v1/wallet/{walletID}/transfers/batch", CreateBatchHandler(svc.BatchTransferer))
+	auth.Get("/api/v1/wallet/{walletID}/transfers/batch/{batchID}", GetBatchHandler(svc.BatchTransferer))
+	auth.Post("/api/v1/wallet/{walletID}/standing-orders", CreateStandingOrderHandler(svc.StandingOrders))
+	auth.Get("/api/v1/wallet/{walletID}/standing-orders", ListStandingOrdersHandler(svc.StandingOrders))
+	auth.Get("/api/v1/wallet/{walletID}/standing-orders/{orderID}", GetStandingOrderHandler(svc.StandingOrders))
+	auth.Patch("/api/v1/wallet/{walletID}/standing-orders/{orderID}", UpdateStandingOrderHandler(svc.StandingOrders))
+	auth.Delete("/api/v1/wallet/{walletID}/standing-orders/{orderID}", CancelStandingOrderHandler(svc.StandingOrders))
+	auth.Get("/api/v1/wallet/{walletID}/standing-orders/{orderID}/runs", ListStandingOrderRunsHandler(svc.StandingOrders))
+	auth.Post("/api/v1/payment-requests", CreatePaymentRequestHandler(svc.PaymentRequests))
+	auth.Get("/api/v1/payment-requests", ListPaymentRequestsHandler(svc.PaymentRequests))
+	auth.Get("/api/v1/payment-requests/{requestID}", GetPaymentRequestHandler(svc.PaymentRequests))
+	auth.Post("/api/v1/payment-requests/{requestID}/accept", AcceptPaymentRequestHandler(svc.PaymentRequests))
+	auth.Post("/api/v1/payment-requests/{requestID}/decline", DeclinePaymentRequestHandler(svc.PaymentRequests))
+	auth.Post("/api/v1/webhooks", CreateWebhookHandler(svc.Webhooks))
+	auth.Get("/api/v1/webhooks", ListWebhooksHandler(svc.Webhooks))
+	auth.Delete("/api/v1/webhooks/{subscriptionID}", DeleteWebhookHandler(svc.Webhooks))
+	auth.Get("/api/v1/webhooks/{subscriptionID}/deliveries", ListWebhookDeliveriesHandler(svc.Webhooks))
 
 	return r
 }
 
+const problemContentType = "application/problem+json"
+
 var (
 	readRequestBodyErr = apiError{
 		Status: http.StatusBadRequest,
-		Key:    "ReadRequestBodyError",
+		Code:   "unreadable_body",
+		Title:  "Unreadable request body",
 		error:  errors.New("cannot read request body"),
 	}
 	unmarshalBodyErr = apiError{
 		Status: http.StatusBadRequest,
-		Key:    "UnmarshalErr",
+		Code:   "malformed_body",
+		Title:  "Malformed request body",
 		error:  errors.New("cannot unmarshal request body"),
 	}
 	unauthorizedErr = apiError{
 		Status: http.StatusUnauthorized,
-		Key:    "UnauthorizedErr",
+		Code:   "unauthorized",
+		Title:  "Unauthorized",
 		error:  errors.New("unauthorized"),
 	}
 	forbiddenErr = apiError{
 		Status: http.StatusForbidden,
-		Key:    "ForbiddenErr",
+		Code:   "forbidden",
+		Title:  "Forbidden",
 		error:  errors.New("forbidden"),
 	}
 	notFoundErr = apiError{
 		Status: http.StatusNotFound,
-		Key:    "NotFoundErr",
+		Code:   "not_found",
+		Title:  "Not found",
 		error:  errors.New("not found"),
 	}
 	methodNotAllowedErr = apiError{
-		Status: http.StatusNotFound,
-		Key:    "MethodNotAllowedErr",
+		Status: http.StatusMethodNotAllowed,
+		Code:   "method_not_allowed",
+		Title:  "Method not allowed",
 		error:  errors.New("method not allowed"),
 	}
-	badRequestErr = apiError{
-		Status: http.StatusBadRequest,
-		Key:    "BadRequestErr",
-		error:  errors.New("bad request"),
+	notAcceptableErr = apiError{
+		Status: http.StatusNotAcceptable,
+		Code:   "not_acceptable",
+		Title:  "Not acceptable",
+		error:  errors.New("not acceptable, supported formats: application/json, text/csv, text/plain"),
 	}
-	unprocessableEntityErr = apiError{
+	invalidParameterErr = apiError{
 		Status: http.StatusUnprocessableEntity,
-		Key:    "UnprocessableEntityErr",
-		error:  errors.New("unprocessable entity"),
+		Code:   "invalid_parameter",
+		Title:  "Invalid parameter",
+		error:  errors.New("invalid parameter"),
 	}
 	serverErr = apiError{
 		Status: http.StatusInternalServerError,
-		Key:    "InternalServerErr",
+		Code:   "internal_error",
+		Title:  "Internal server error",
 		error:  errors.New("internal server error"),
 	}
 )
 
 type apiError struct {
 	// Status HTTP status code of the error.
-	Status int `json:"status_code"`
-	// Key returns a code that uniquely identifies an error.
-	Key string `json:"key"`
-	// Contains the API error message displayed to the user.
-	Message string `json:"error"`
-	// Contains the API error.
-	error `json:"-"`
+	Status int
+	// Code stable code that uniquely identifies the type of problem.
+	Code string
+	// Title short summary of the type of problem.
+	Title string
+	// Details lists the problems of the request fields, if any.
+	Details []fieldError
+	// Contains the API error, its message is displayed to the user.
+	error
 }
 
 // Msg can be use to override the message error
@@ -115,6 +192,31 @@ func (e apiError) Err(err error) apiError {
 	return e
 }
 
+// withDetails sets the problems of the request fields.
+func (e apiError) withDetails(details []fieldError) apiError {
+	e.Details = details
+	return e
+}
+
+// problem the RFC 7807 problem details of an API error.
+type problem struct {
+	// Type URI that identifies the type of problem.
+	Type string `json:"type"`
+	// Title short summary of the type of problem.
+	Title string `json:"title"`
+	// Status HTTP status code of the error.
+	Status int `json:"status"`
+	// Detail explanation of this occurrence of the problem.
+	Detail string `json:"detail"`
+	// Instance ID of the request where the problem occurred.
+	Instance string `json:"instance"`
+	// Code stable code of the type of problem.
+	Code string `json:"code"`
+	// Details lists the problems of the request fields, if any. The
+	// member keeps the name it had before the problem details format.
+	Details []fieldError `json:"details,omitempty"`
+}
+
 func writeError(w http.ResponseWriter, req *http.Request, apiError apiError, err error) {
 	var e *zerolog.Event
 	if apiError.Status >= 500 {
@@ -123,17 +225,28 @@ func writeError(w http.ResponseWriter, req *http.Request, apiError apiError, err
 		e = log.Warn()
 	}
 
+	requestID := middleware.GetReqID(req.Context())
+	if sc := trace.SpanContextFromContext(req.Context()); sc.IsValid() {
+		e = e.Str("trace_id", sc.TraceID().String())
+	}
 	e.Stack().
-		Str("request_id", middleware.GetReqID(req.Context())).
+		Str("request_id", requestID).
 		Str("url", req.URL.String()).
-		Str("key", apiError.Key).
+		Str("code", apiError.Code).
 		Err(apiError).
 		Err(err).
 		Msg("API error")
 
-	apiError.Message = apiError.Error()
-
-	writeResponse(w, apiError.Status, apiError)
+	w.Header().Set("Content-Type", problemContentType)
+	writeResponse(w, apiError.Status, problem{
+		Type:     "urn:paybile:problem:" + apiError.Code,
+		Title:    apiError.Title,
+		Status:   apiError.Status,
+		Detail:   apiError.Error(),
+		Instance: requestID,
+		Code:     apiError.Code,
+		Details:  apiError.Details,
+	})
 }
 
 func writeResponse(w http.ResponseWriter, statusCode int, response interface{}) {
@@ -172,12 +285,11 @@ func (svc *ApiService) authMiddleware() func(http.Handler) http.Handler {
 			ctx := r.Context()
 			u, err := svc.LoginService.Login(r.Context(), user, pass)
 			if err != nil {
-				switch errors.Unwrap(err) {
-				case service.ErrUserNotFound:
-					writeError(w, r, unauthorizedErr, err)
-				default:
-					writeError(w, r, forbiddenErr, err)
+				var locked *service.LoginLockedError
+				if errors.As(err, &locked) {
+					w.Header().Set("Retry-After", retryAfter(locked.Until))
 				}
+				writeServiceError(w, r, err)
 				return
 			}
 
@@ -185,7 +297,19 @@ func (svc *ApiService) authMiddleware() func(http.Handler) http.Handler {
 			ctx = context.WithValue(ctx, userIDCtxKey{}, u)
 			r = r.WithContext(ctx)
 
-			next.ServeHTTP(w, r)
+			// The user is authenticated, so a forbidden
+			// response means it cannot access the resource.
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			if ww.Status() == http.StatusForbidden {
+				service.Audit(ctx, svc.Auditor, service.AuditEntry{
+					Actor:    u.ID.String(),
+					Action:   service.AuditAccessDenied,
+					Resource: r.Method + " " + r.URL.Path,
+					Outcome:  service.AuditDenied,
+				})
+			}
 		})
 	}
 }
@@ -201,6 +325,36 @@ func (svc *ApiService) requestIDResponseHeader() func(http.Handler) http.Handler
 	}
 }
 
+// retryAfter returns the seconds until the given
+// time, rounded up, for the Retry-After header.
+func retryAfter(t time.Time) string {
+	seconds := int(math.Ceil(time.Until(t).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
+// auditMetadata stores the metadata of the request in the
+// context, so the audited actions can be traced back to it.
+func (svc *ApiService) auditMetadata() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+			ctx := service.ContextWithAuditMetadata(r.Context(), service.AuditMetadata{
+				IP:        ip,
+				UserAgent: r.UserAgent(),
+				RequestID: middleware.GetReqID(r.Context()),
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func requestUser(r *http.Request) service.User {
 	return r.Context().Value(userIDCtxKey{}).(service.User)
 }
@@ -208,12 +362,3 @@ func requestUser(r *http.Request) service.User {
 func urlParam(r *http.Request, key string) string {
 	return chi.URLParam(r, key)
 }
-
-// wrappedErrOrParent returns inner wrapped error if any.
-func wrappedErrOrParent(err error) error {
-	if wrappedErr := errors.Unwrap(err); wrappedErr != nil {
-		return wrappedErrOrParent(wrappedErr)
-	}
-
-	return err
-}
@@ -0,0 +1,184 @@
+package http_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	servicemocks "github.com/hmoragrega/paybile/service/mocks"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRouterErrors(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name       string
+		req        http.Request
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "route not found",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: "/api/v1/foo"},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
+		},
+		{
+			name: "method not allowed",
+			req: http.Request{
+				Method: http.MethodPut,
+				URL:    &url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67"},
+			},
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"type":"urn:paybile:problem:method_not_allowed","title":"Method not allowed","status":405,"detail":"method not allowed","instance":"test-request","code":"method_not_allowed"}`,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			api := httptransport.ApiService{}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got, want := res.Header.Get("Content-Type"), "application/problem+json"; got != want {
+				t.Fatalf("unexpected content type: \n got:  %+v \n want: %+v", got, want)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestServiceErrors(t *testing.T) {
+	t.Parallel()
+	var (
+		user     = service.User{ID: uuid.New()}
+		walletID = uuid.New()
+		auth     = base64.StdEncoding.EncodeToString([]byte("foo:bar"))
+	)
+	for code, err := range service.Errors() {
+		code, err := code, err
+		t.Run(string(code), func(t *testing.T) {
+			t.Parallel()
+			ls := &mocks.LoginService{}
+			ls.On("Login", mock.Anything, "foo", "bar").Return(user, nil)
+			wg := &mocks.WalletGetter{}
+			wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, fmt.Errorf("cannot get wallet: %w", err))
+
+			api := httptransport.ApiService{
+				WalletGetter: wg,
+				LoginService: ls,
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/wallet/"+walletID.String(), nil)
+			req.Header.Set("Authorization", "Basic "+auth)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			var got struct {
+				Status int    `json:"status"`
+				Title  string `json:"title"`
+				Code   string `json:"code"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("cannot unmarshal problem: %v", err)
+			}
+			if got.Status >= http.StatusInternalServerError || got.Status != rr.Code || got.Title == "" {
+				t.Fatalf("unregistered service error: %+v", got)
+			}
+			if got.Code != string(code) {
+				t.Fatalf("unexpected code: got: %q, want: %q", got.Code, code)
+			}
+		})
+	}
+}
+
+func TestAccessDeniedAudit(t *testing.T) {
+	t.Parallel()
+	var (
+		user     = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		path     = "/api/v1/wallet/" + walletID.String()
+	)
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, "foo", "bar").Return(user, nil)
+	wg := &mocks.WalletGetter{}
+	wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, service.ErrWalletAccessDenied)
+	a := &servicemocks.Auditor{}
+	a.On("Audit", mock.Anything, service.AuditEntry{
+		Actor:    user.ID.String(),
+		Action:   service.AuditAccessDenied,
+		Resource: "GET " + path,
+		Outcome:  service.AuditDenied,
+		AuditMetadata: service.AuditMetadata{
+			IP:        "192.0.2.1",
+			UserAgent: "curl/7.64.1",
+			RequestID: testRequestID,
+		},
+	}).Return(nil)
+
+	api := httptransport.ApiService{
+		LoginService: ls,
+		WalletGetter: wg,
+		Auditor:      a,
+	}
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.SetBasicAuth("foo", "bar")
+	req.Header.Set("User-Agent", "curl/7.64.1")
+	req.Header.Set("X-Request-Id", testRequestID)
+	rr := httptest.NewRecorder()
+	api.Handler().ServeHTTP(rr, req)
+
+	if got, want := rr.Code, http.StatusForbidden; got != want {
+		t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, want)
+	}
+	a.AssertExpectations(t)
+}
+
+func TestLoginLocked(t *testing.T) {
+	t.Parallel()
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, "foo", "bar").Return(service.User{}, fmt.Errorf("cannot login user: %w", &service.LoginLockedError{
+		Until: time.Now().Add(90 * time.Second),
+	}))
+
+	api := httptransport.ApiService{LoginService: ls}
+	req := http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67"},
+		Header: http.Header{},
+	}
+	req.SetBasicAuth("foo", "bar")
+	rr := httptest.NewRecorder()
+	api.Handler().ServeHTTP(rr, withRequestID(&req))
+	res := rr.Result()
+
+	if got, want := res.StatusCode, http.StatusLocked; got != want {
+		t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := res.Header.Get("Retry-After"), "90"; got != want {
+		t.Fatalf("unexpected retry after: \n got:  %+v \n want: %+v", got, want)
+	}
+	wantBody := `{"type":"urn:paybile:problem:login_locked","title":"Login locked","status":423,"detail":"too many failed login attempts","instance":"test-request","code":"login_locked"}`
+	if got := rr.Body.String(); got != wantBody {
+		t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, wantBody)
+	}
+	assertOpenAPIResponse(t, &req, res)
+}
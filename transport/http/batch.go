@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+type BatchRequest struct {
+	// Mode optional, "atomic" by default.
+	Mode      *string                `json:"mode"`
+	Transfers []BatchTransferRequest `json:"transfers"`
+}
+
+type BatchTransferRequest struct {
+	DestinationWalletID *string  `json:"destination_wallet_id"`
+	Amount              *float64 `json:"amount"`
+	Message             *string  `json:"message"`
+}
+
+type BatchTransferer interface {
+	TransferBatch(ctx context.Context, req service.BatchRequest) (service.Batch, error)
+	GetBatch(ctx context.Context, user service.User, walletID, batchID uuid.UUID) (service.Batch, error)
+}
+
+func CreateBatchHandler(svc BatchTransferer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var x BatchRequest
+		if !decodeBody(w, r, &x) {
+			return
+		}
+
+		originWalletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		req := service.BatchRequest{
+			Issuer:         requestUser(r),
+			OriginWalletID: originWalletID,
+			Mode:           service.BatchAtomic,
+		}
+		if x.Mode != nil {
+			req.Mode = service.BatchMode(*x.Mode)
+		}
+		var v validator
+		v.required("transfers", len(x.Transfers) > 0)
+		for i, t := range x.Transfers {
+			field := fmt.Sprintf("transfers[%d].", i)
+			v.required(field+"destination_wallet_id", t.DestinationWalletID != nil)
+			destinationWalletID := v.uuid(field+"destination_wallet_id", t.DestinationWalletID)
+			v.required(field+"amount", t.Amount != nil)
+			v.amount(field+"amount", t.Amount)
+			v.message(field+"message", t.Message)
+			if t.Amount == nil {
+				continue
+			}
+			req.Items = append(req.Items, service.BatchItem{
+				DestinationWalletID: destinationWalletID,
+				Amount:              *t.Amount,
+				Message:             t.Message,
+			})
+		}
+		if !v.valid(w, r) {
+			return
+		}
+
+		batch, err := svc.TransferBatch(r.Context(), req)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusCreated, batch)
+	}
+}
+
+func GetBatchHandler(svc BatchTransferer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+		batchID, err := uuid.Parse(urlParam(r, "batchID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		batch, err := svc.GetBatch(r.Context(), requestUser(r), walletID, batchID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, batch)
+	}
+}
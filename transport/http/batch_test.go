@@ -0,0 +1,197 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBatchHandlers(t *testing.T) {
+	t.Parallel()
+	var (
+		user          = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		login         = "foo"
+		pass          = "bar"
+		auth          = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		headers       = map[string][]string{"Authorization": {"Basic " + auth}}
+		walletID      = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		destinationID = uuid.MustParse("ba2428bd-88bb-44aa-9428-688d41817dc5")
+		batchID       = uuid.MustParse("3c8e1a57-6f1d-4a0e-9d3c-1e5b7f2a9c40")
+		message       = "salary"
+		basePath      = "/api/v1/wallet/" + walletID.String() + "/transfers/batch"
+		batchPath     = basePath + "/" + batchID.String()
+		batch         = service.Batch{
+			ID:             batchID,
+			IssuerID:       user.ID,
+			OriginWalletID: walletID,
+			Mode:           service.BatchBestEffort,
+			Date:           time.Now(),
+			Transfers: []service.Transfer{{
+				ID:                  uuid.MustParse("8d2f6b1e-4a55-4c0c-8f67-7f0f2d0e5c33"),
+				IssuerID:            user.ID,
+				OriginWalletID:      walletID,
+				DestinationWalletID: destinationID,
+				Amount:              500,
+				Message:             &message,
+				Status:              service.TransferCompleted,
+				BatchID:             &batchID,
+			}},
+		}
+		request = service.BatchRequest{
+			Issuer:         user,
+			OriginWalletID: walletID,
+			Mode:           service.BatchBestEffort,
+			Items: []service.BatchItem{{
+				DestinationWalletID: destinationID,
+				Amount:              500,
+				Message:             &message,
+			}},
+		}
+	)
+	batchJSON, _ := json.Marshal(&batch)
+
+	buildReq := func(method string, path string, body string) http.Request {
+		r, _ := http.NewRequest(method, "", bytes.NewBuffer([]byte(body)))
+		r.Header = headers
+		r.URL = &url.URL{Path: path}
+		return *r
+	}
+
+	tt := []struct {
+		name       string
+		req        http.Request
+		expect     func(bt *mocks.BatchTransferer, ls *mocks.LoginService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: basePath},
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "missing transfers",
+			req:  buildReq(http.MethodPost, basePath, `{"mode": "atomic"}`),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"transfers","code":"required","message":"missing transfers"}]}`,
+		},
+		{
+			name: "missing amount",
+			req: buildReq(http.MethodPost, basePath, `{"transfers": [
+				{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 5},
+				{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5"}]}`),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"transfers[1].amount","code":"required","message":"missing transfers[1].amount"}]}`,
+		},
+		{
+			name: "invalid destination",
+			req:  buildReq(http.MethodPost, basePath, `{"transfers": [{"destination_wallet_id": "foo", "amount": 5}]}`),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"transfers[0].destination_wallet_id","code":"invalid_format","message":"transfers[0].destination_wallet_id is not a valid ID"}]}`,
+		},
+		{
+			name: "invalid batch",
+			req:  buildReq(http.MethodPost, basePath, `{"transfers": [{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": -5}]}`),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bt.On("TransferBatch", mock.Anything, mock.Anything).
+					Return(service.Batch{}, fmt.Errorf("%w: transfer 0: %v", service.ErrInvalidBatch, service.ErrInvalidTransactionAmount))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_batch","title":"Invalid batch","status":422,"detail":"invalid batch: transfer 0: invalid transaction amount","instance":"test-request","code":"invalid_batch"}`,
+		},
+		{
+			name: "insufficient funds",
+			req:  buildReq(http.MethodPost, basePath, `{"transfers": [{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 500}]}`),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bt.On("TransferBatch", mock.Anything, mock.Anything).
+					Return(service.Batch{}, fmt.Errorf("cannot transfer batch: %w", service.ErrInsufficientFunds))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:insufficient_funds","title":"Insufficient funds","status":422,"detail":"origin has insufficient funds","instance":"test-request","code":"insufficient_funds"}`,
+		},
+		{
+			name: "create ok",
+			req: buildReq(http.MethodPost, basePath, `{"mode": "best_effort", "transfers": [
+				{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 500, "message": "salary"}]}`),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bt.On("TransferBatch", mock.Anything, request).Return(batch, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   string(batchJSON),
+		},
+		{
+			name: "get not found",
+			req:  buildReq(http.MethodGet, batchPath, ""),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bt.On("GetBatch", mock.Anything, user, walletID, batchID).Return(service.Batch{}, service.ErrBatchNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:batch_not_found","title":"Batch not found","status":404,"detail":"batch not found","instance":"test-request","code":"batch_not_found"}`,
+		},
+		{
+			name: "get ok",
+			req:  buildReq(http.MethodGet, batchPath, ""),
+			expect: func(bt *mocks.BatchTransferer, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bt.On("GetBatch", mock.Anything, user, walletID, batchID).Return(batch, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(batchJSON),
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			bt := &mocks.BatchTransferer{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(bt, ls)
+			}
+
+			api := httptransport.ApiService{
+				BatchTransferer: bt,
+				LoginService:    ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/hmoragrega/paybile/service"
+)
+
+// serviceErrors maps the codes of the service errors to the API
+// errors returned to the clients.
+//
+// When the API error has no message, the detail displayed to the
+// user is the message of the service error.
+var serviceErrors = map[service.ErrorCode]apiError{
+	// users
+	service.CodeUnauthorized: unauthorizedErr,
+	service.CodeForbidden:    forbiddenErr,
+	service.CodeLoginLocked:  {Status: http.StatusLocked, Title: "Login locked"},
+
+	// wallets and transfers
+	service.CodeWalletNotFound:         {Status: http.StatusNotFound, Title: "Wallet not found"},
+	service.CodeWalletAccessDenied:     {Status: http.StatusForbidden, Title: "Wallet access denied"},
+	service.CodeInvalidAmount:          {Status: http.StatusUnprocessableEntity, Title: "Invalid amount"},
+	service.CodeInsufficientFunds:      {Status: http.StatusUnprocessableEntity, Title: "Insufficient funds"},
+	service.CodeSameWallets:            {Status: http.StatusUnprocessableEntity, Title: "Same origin and destination wallets"},
+	service.CodeInvalidExecutionDate:   {Status: http.StatusUnprocessableEntity, Title: "Invalid execution date"},
+	service.CodeTransferNotFound:       {Status: http.StatusNotFound, Title: "Transfer not found"},
+	service.CodeTransferNotCancellable: {Status: http.StatusConflict, Title: "Transfer not cancellable"},
+	service.CodeTransactionNotFound:    {Status: http.StatusNotFound, Title: "Transaction not found"},
+
+	// lists
+	service.CodeInvalidListOrder:   {Status: http.StatusUnprocessableEntity, Title: "Invalid list order"},
+	service.CodeInvalidPerPage:     {Status: http.StatusUnprocessableEntity, Title: "Invalid items per page"},
+	service.CodeMaxPerPageExceeded: {Status: http.StatusUnprocessableEntity, Title: "Too many items per page"},
+	service.CodeInvalidExpand:      {Status: http.StatusUnprocessableEntity, Title: "Invalid expand option"},
+
+	// batches
+	service.CodeInvalidBatch:  {Status: http.StatusUnprocessableEntity, Title: "Invalid batch"},
+	service.CodeBatchNotFound: {Status: http.StatusNotFound, Title: "Batch not found"},
+
+	// standing orders
+	service.CodeInvalidRecurrence:      {Status: http.StatusUnprocessableEntity, Title: "Invalid recurrence"},
+	service.CodeStandingOrderNotFound:  {Status: http.StatusNotFound, Title: "Standing order not found"},
+	service.CodeStandingOrderNotActive: {Status: http.StatusConflict, Title: "Standing order not active"},
+
+	// payment requests
+	service.CodePaymentRequestNotFound:   {Status: http.StatusNotFound, Title: "Payment request not found"},
+	service.CodePaymentRequestNotPending: {Status: http.StatusConflict, Title: "Payment request not pending"},
+	service.CodePaymentRequestExpired:    {Status: http.StatusConflict, Title: "Payment request expired"},
+	service.CodeInvalidExpirationDate:    {Status: http.StatusUnprocessableEntity, Title: "Invalid expiration date"},
+
+	// webhooks
+	service.CodeInvalidWebhook:  {Status: http.StatusUnprocessableEntity, Title: "Invalid webhook"},
+	service.CodeWebhookNotFound: {Status: http.StatusNotFound, Title: "Webhook not found"},
+}
+
+// writeServiceError writes the API error registered for the
+// service error, or an internal server error if there is none.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, serviceAPIError(err), err)
+}
+
+// serviceAPIError returns the API error of the service error code.
+func serviceAPIError(err error) apiError {
+	code, public, ok := service.ErrorCodeOf(err)
+	if !ok {
+		return serverErr
+	}
+	apiErr, ok := serviceErrors[code]
+	if !ok {
+		return serverErr
+	}
+	apiErr.Code = string(code)
+	if apiErr.error == nil {
+		apiErr.error = public
+	}
+
+	return apiErr
+}
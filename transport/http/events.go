@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	// eventStreamHeartbeat interval of the comments that keep the idle connections open.
+	eventStreamHeartbeat = 15 * time.Second
+)
+
+type TransactionSubscriber interface {
+	SubscribeTransactions(ctx context.Context, user service.User, walletID uuid.UUID, lastID *uuid.UUID) (<-chan service.Transaction, error)
+}
+
+// WalletEventsHandler streams the wallet transactions as Server-Sent Events
+// as they are committed. The event ID is the transaction ID, so clients
+// reconnecting with the Last-Event-ID header receive the ones they missed.
+func WalletEventsHandler(svc TransactionSubscriber) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		var lastID *uuid.UUID
+		if x := r.Header.Get("Last-Event-ID"); x != "" {
+			id, err := uuid.Parse(x)
+			if err != nil {
+				writeError(w, r, invalidParameterErr.Err(errors.New("Last-Event-ID is not a valid transaction ID")), err)
+				return
+			}
+			lastID = &id
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, r, serverErr, errors.New("streaming is not supported"))
+			return
+		}
+
+		events, err := svc.SubscribeTransactions(r.Context(), requestUser(r), walletID, lastID)
+		if errors.Is(err, service.ErrTransactionNotFound) {
+			writeError(w, r, invalidParameterErr.Err(errors.New("Last-Event-ID is not a transaction of the wallet")), err)
+			return
+		}
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", eventStreamContentType+"; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		t := time.NewTicker(eventStreamHeartbeat)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-t.C:
+				_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			case tx, ok := <-events:
+				if !ok {
+					return
+				}
+				b, err := json.Marshal(tx)
+				if err != nil {
+					return
+				}
+				_, _ = fmt.Fprintf(w, "id: %s\nevent: transaction\ndata: %s\n\n", tx.ID, b)
+			}
+			flusher.Flush()
+		}
+	}
+}
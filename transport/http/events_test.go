@@ -0,0 +1,158 @@
+package http_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWalletEventsHandler(t *testing.T) {
+	t.Parallel()
+	var (
+		user     = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID = uuid.MustParse("2f9b76dd-f689-456e-9080-6789718018a5")
+		lastID   = uuid.MustParse("73b3e1a4-9a2c-4bd4-a1d8-32a9ad7ba0f6")
+		login    = "foo"
+		pass     = "bar"
+		auth     = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		path     = "/api/v1/wallet/" + walletID.String() + "/events"
+		list     = []service.Transaction{{
+			ID:      uuid.MustParse("9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc"),
+			Amount:  20,
+			Balance: 20,
+			Type:    service.DepositType,
+			Date:    time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC),
+		}, {
+			ID:      uuid.MustParse("4bce4401-6b35-4fa1-94b9-ac5ce05d29b1"),
+			Amount:  -7.25,
+			Balance: 12.75,
+			Type:    service.TransferType,
+			Date:    time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC),
+		}}
+	)
+
+	// stream returns a closed channel with the given transactions.
+	stream := func(list []service.Transaction) <-chan service.Transaction {
+		ch := make(chan service.Transaction, len(list))
+		for _, x := range list {
+			ch <- x
+		}
+		close(ch)
+		return ch
+	}
+
+	tt := []struct {
+		name            string
+		headers         http.Header
+		expect          func(ts *mocks.TransactionSubscriber, ls *mocks.LoginService)
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "unauthorized",
+			wantStatus:      http.StatusUnauthorized,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "invalid last event ID",
+			headers: map[string][]string{
+				"Authorization": {"Basic " + auth},
+				"Last-Event-Id": {"foo"},
+			},
+			expect: func(ts *mocks.TransactionSubscriber, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"Last-Event-ID is not a valid transaction ID","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name: "last event is not a transaction of the wallet",
+			headers: map[string][]string{
+				"Authorization": {"Basic " + auth},
+				"Last-Event-Id": {lastID.String()},
+			},
+			expect: func(ts *mocks.TransactionSubscriber, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				ts.On("SubscribeTransactions", mock.Anything, user, walletID, &lastID).Return(nil, service.ErrTransactionNotFound)
+			},
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"Last-Event-ID is not a transaction of the wallet","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name:    "wallet access denied",
+			headers: map[string][]string{"Authorization": {"Basic " + auth}},
+			expect: func(ts *mocks.TransactionSubscriber, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				ts.On("SubscribeTransactions", mock.Anything, user, walletID, (*uuid.UUID)(nil)).Return(nil, service.ErrWalletAccessDenied)
+			},
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
+		},
+		{
+			name: "events",
+			headers: map[string][]string{
+				"Authorization": {"Basic " + auth},
+				"Last-Event-Id": {lastID.String()},
+			},
+			expect: func(ts *mocks.TransactionSubscriber, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				ts.On("SubscribeTransactions", mock.Anything, user, walletID, &lastID).Return(stream(list), nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/event-stream; charset=utf-8",
+			wantBody: "id: 9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc\nevent: transaction\n" +
+				`data: {"id":"9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc","amount":20,"balance":20,"transaction_type":"deposit","reference_id":null,"date":"2020-09-20T10:00:00Z"}` + "\n\n" +
+				"id: 4bce4401-6b35-4fa1-94b9-ac5ce05d29b1\nevent: transaction\n" +
+				`data: {"id":"4bce4401-6b35-4fa1-94b9-ac5ce05d29b1","amount":-7.25,"balance":12.75,"transaction_type":"transfer","reference_id":null,"date":"2020-09-20T11:10:00Z"}` + "\n\n",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ts := &mocks.TransactionSubscriber{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(ts, ls)
+			}
+
+			api := httptransport.ApiService{
+				TransactionEvents: ts,
+				LoginService:      ls,
+			}
+			req := http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path},
+				Header: tc.headers,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := res.Header.Get("Content-Type"); got != tc.wantContentType {
+				t.Fatalf("unexpected content type: \n got:  %+v \n want: %+v", got, tc.wantContentType)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %q \n want: %q", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &req, res)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/rs/zerolog/log"
+)
+
+// exportFlushEvery number of transactions written between flushes.
+const exportFlushEvery = 100
+
+type TransactionExporter interface {
+	ExportTransactions(ctx context.Context, user service.User, walletID uuid.UUID, fn func(service.Transaction) error) error
+}
+
+// transactionEncoder writes the transactions of an export one at a time.
+type transactionEncoder interface {
+	Begin() error
+	Encode(t service.Transaction) error
+	Flush() error
+}
+
+// ExportTransactionsHandler streams the whole transaction history of the wallet
+// as CSV or NDJSON. The response is written as the rows are read from the
+// database, so once it has started errors can only abort the connection.
+func ExportTransactionsHandler(svc TransactionExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		var (
+			enc         transactionEncoder
+			contentType string
+			format      = r.URL.Query().Get("format")
+		)
+		switch format {
+		case "", "csv":
+			format, contentType, enc = "csv", csvContentType, &csvTransactionEncoder{w: csv.NewWriter(w)}
+		case "ndjson":
+			contentType, enc = "application/x-ndjson", &ndjsonTransactionEncoder{enc: json.NewEncoder(w)}
+		default:
+			writeError(w, r, invalidParameterErr.Err(errors.New("format must be csv or ndjson")), nil)
+			return
+		}
+
+		var (
+			flusher, _ = w.(http.Flusher)
+			started    bool
+			count      int
+		)
+		begin := func() error {
+			started = true
+			w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, walletID, format))
+			w.WriteHeader(http.StatusOK)
+			return enc.Begin()
+		}
+		flush := func() error {
+			if err := enc.Flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		}
+
+		err = svc.ExportTransactions(r.Context(), requestUser(r), walletID, func(t service.Transaction) error {
+			if !started {
+				if err := begin(); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+			if count++; count%exportFlushEvery == 0 {
+				return flush()
+			}
+			return nil
+		})
+		if err == nil && !started {
+			err = begin()
+		}
+		if err == nil {
+			err = flush()
+		}
+		if err == nil {
+			return
+		}
+
+		if !started {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		// The status has been sent already, abort the response
+		// so the client does not take a partial export as complete.
+		log.Error().
+			Str("request_id", middleware.GetReqID(r.Context())).
+			Str("url", r.URL.String()).
+			Int("exported", count).
+			Err(err).
+			Msg("transaction export interrupted")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+type csvTransactionEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTransactionEncoder) Begin() error {
+	return e.w.Write([]string{"id", "date", "type", "amount", "balance", "reference_id"})
+}
+
+func (e *csvTransactionEncoder) Encode(t service.Transaction) error {
+	return e.w.Write([]string{
+		t.ID.String(),
+		formatDate(t.Date),
+		string(t.Type),
+		exactAmount(t.Amount),
+		exactAmount(t.Balance),
+		uuidOrEmpty(t.ReferenceID),
+	})
+}
+
+func (e *csvTransactionEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonTransactionEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonTransactionEncoder) Begin() error {
+	return nil
+}
+
+// Encode writes the transaction as JSON followed by a new line.
+func (e *ndjsonTransactionEncoder) Encode(t service.Transaction) error {
+	return e.enc.Encode(t)
+}
+
+func (e *ndjsonTransactionEncoder) Flush() error {
+	return nil
+}
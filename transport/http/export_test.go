@@ -0,0 +1,204 @@
+package http_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportTransactionsHandler(t *testing.T) {
+	t.Parallel()
+	var (
+		user        = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID    = uuid.MustParse("2f9b76dd-f689-456e-9080-6789718018a5")
+		referenceID = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
+		login       = "foo"
+		pass        = "bar"
+		auth        = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		headers     = map[string][]string{"Authorization": {"Basic " + auth}}
+		path        = "/api/v1/wallet/" + walletID.String() + "/transactions/export"
+		list        = []service.Transaction{{
+			ID:      uuid.MustParse("9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc"),
+			Amount:  20,
+			Balance: 20,
+			Type:    service.DepositType,
+			Date:    time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC),
+		}, {
+			ID:          uuid.MustParse("4bce4401-6b35-4fa1-94b9-ac5ce05d29b1"),
+			Amount:      -7.25,
+			Balance:     12.75,
+			Type:        service.TransferType,
+			ReferenceID: &referenceID,
+			Date:        time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC),
+		}}
+	)
+
+	// stream calls the export function with the given transactions.
+	stream := func(list []service.Transaction) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			fn := args.Get(3).(func(service.Transaction) error)
+			for _, x := range list {
+				if fn(x) != nil {
+					return
+				}
+			}
+		}
+	}
+
+	tt := []struct {
+		name            string
+		query           string
+		headers         http.Header
+		expect          func(te *mocks.TransactionExporter, ls *mocks.LoginService)
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "unauthorized",
+			wantStatus:      http.StatusUnauthorized,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name:    "invalid format",
+			query:   "format=xml",
+			headers: headers,
+			expect: func(te *mocks.TransactionExporter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"format must be csv or ndjson","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name:    "wallet access denied",
+			headers: headers,
+			expect: func(te *mocks.TransactionExporter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				te.On("ExportTransactions", mock.Anything, user, walletID, mock.Anything).Return(service.ErrWalletAccessDenied)
+			},
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
+		},
+		{
+			name:    "empty csv",
+			headers: headers,
+			expect: func(te *mocks.TransactionExporter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				te.On("ExportTransactions", mock.Anything, user, walletID, mock.Anything).Return(nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "id,date,type,amount,balance,reference_id\n",
+		},
+		{
+			name:    "csv",
+			query:   "format=csv",
+			headers: headers,
+			expect: func(te *mocks.TransactionExporter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				te.On("ExportTransactions", mock.Anything, user, walletID, mock.Anything).
+					Run(stream(list)).
+					Return(nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody: "id,date,type,amount,balance,reference_id\n" +
+				"9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc,2020-09-20T10:00:00Z,deposit,20,20,\n" +
+				"4bce4401-6b35-4fa1-94b9-ac5ce05d29b1,2020-09-20T11:10:00Z,transfer,-7.25,12.75,97ca2b73-7988-4247-82d4-f6ba723a99c9\n",
+		},
+		{
+			name:    "ndjson",
+			query:   "format=ndjson",
+			headers: headers,
+			expect: func(te *mocks.TransactionExporter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				te.On("ExportTransactions", mock.Anything, user, walletID, mock.Anything).
+					Run(stream(list)).
+					Return(nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson; charset=utf-8",
+			wantBody: `{"id":"9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc","amount":20,"balance":20,"transaction_type":"deposit","reference_id":null,"date":"2020-09-20T10:00:00Z"}` + "\n" +
+				`{"id":"4bce4401-6b35-4fa1-94b9-ac5ce05d29b1","amount":-7.25,"balance":12.75,"transaction_type":"transfer","reference_id":"97ca2b73-7988-4247-82d4-f6ba723a99c9","date":"2020-09-20T11:10:00Z"}` + "\n",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			te := &mocks.TransactionExporter{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(te, ls)
+			}
+
+			api := httptransport.ApiService{
+				TransactionExporter: te,
+				LoginService:        ls,
+			}
+			req := http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path, RawQuery: tc.query},
+				Header: tc.headers,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := res.Header.Get("Content-Type"); got != tc.wantContentType {
+				t.Fatalf("unexpected content type: \n got:  %+v \n want: %+v", got, tc.wantContentType)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %q \n want: %q", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &req, res)
+		})
+	}
+}
+
+func TestExportTransactionsHandlerAbortsInterruptedExport(t *testing.T) {
+	t.Parallel()
+	var (
+		user     = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID = uuid.MustParse("2f9b76dd-f689-456e-9080-6789718018a5")
+		te       = &mocks.TransactionExporter{}
+		ls       = &mocks.LoginService{}
+	)
+	ls.On("Login", mock.Anything, "foo", "bar").Return(user, nil)
+	te.On("ExportTransactions", mock.Anything, user, walletID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			fn := args.Get(3).(func(service.Transaction) error)
+			_ = fn(service.Transaction{ID: uuid.New()})
+		}).
+		Return(errors.New("connection lost"))
+
+	api := httptransport.ApiService{
+		TransactionExporter: te,
+		LoginService:        ls,
+	}
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/wallet/"+walletID.String()+"/transactions/export", nil)
+	req.SetBasicAuth("foo", "bar")
+
+	defer func() {
+		if got := recover(); got != http.ErrAbortHandler {
+			t.Fatalf("unexpected recovered value: got %v, want %v", got, http.ErrAbortHandler)
+		}
+	}()
+	api.Handler().ServeHTTP(httptest.NewRecorder(), req)
+}
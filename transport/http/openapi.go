@@ -0,0 +1,17 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OpenAPIHandler serves the OpenAPI document of the API.
+func OpenAPIHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(openAPISpec)); err != nil {
+			log.Error().Err(err).Msg("cannot write response")
+		}
+	}
+}
@@ -0,0 +1,2902 @@
+package http
+
+// openAPISpec OpenAPI document of the API, it must describe every
+// route of ApiService.Handler; the handler tests validate against it.
+const openAPISpec = `{
+  "openapi": "3.1.0",
+  "info": {
+    "title": "Paybile API",
+    "description": "Wallets, transactions and transfers between them.",
+    "version": "1.0.0"
+  },
+  "servers": [
+    {
+      "url": "http://localhost:8080"
+    }
+  ],
+  "security": [
+    {
+      "basicAuth": []
+    }
+  ],
+  "paths": {
+    "/api/v1/health": {
+      "get": {
+        "operationId": "health",
+        "summary": "Liveness check, it does not check the dependencies.",
+        "security": [],
+        "tags": [
+          "service"
+        ],
+        "responses": {
+          "200": {
+            "description": "The service is up.",
+            "content": {
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/livez": {
+      "get": {
+        "operationId": "livez",
+        "summary": "Liveness probe, the service can answer requests.",
+        "security": [],
+        "tags": [
+          "service"
+        ],
+        "responses": {
+          "200": {
+            "description": "The service is alive.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProbeStatus"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/readyz": {
+      "get": {
+        "operationId": "readyz",
+        "summary": "Readiness probe, the database is reachable and migrated, and the service is not shutting down.",
+        "security": [],
+        "tags": [
+          "service"
+        ],
+        "responses": {
+          "200": {
+            "description": "The service is ready.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProbeStatus"
+                }
+              }
+            }
+          },
+          "503": {
+            "$ref": "#/components/responses/ServiceUnavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/openapi.json": {
+      "get": {
+        "operationId": "openAPI",
+        "summary": "This OpenAPI document.",
+        "security": [],
+        "tags": [
+          "service"
+        ],
+        "responses": {
+          "200": {
+            "description": "The OpenAPI document.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object"
+                }
+              }
+            }
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "getWallet",
+        "summary": "Returns the wallet and its balance.",
+        "tags": [
+          "wallets"
+        ],
+        "responses": {
+          "200": {
+            "description": "The wallet.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Wallet"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/balance": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "getBalance",
+        "summary": "Returns the balance of the wallet after its last transaction at or before the given date.",
+        "tags": [
+          "wallets"
+        ],
+        "parameters": [
+          {
+            "name": "at",
+            "in": "query",
+            "description": "Date of the balance, now by default.",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The balance.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WalletBalance"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/statements/{month}": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        },
+        {
+          "name": "month",
+          "in": "path",
+          "required": true,
+          "description": "Month of the statement.",
+          "schema": {
+            "type": "string",
+            "pattern": "^[0-9]{4}-[0-9]{2}$",
+            "example": "2020-09"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "getStatement",
+        "summary": "Returns the monthly statement of the wallet.",
+        "tags": [
+          "wallets"
+        ],
+        "responses": {
+          "200": {
+            "description": "The statement in the negotiated format.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Statement"
+                }
+              },
+              "text/csv": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/transactions": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "listTransactions",
+        "summary": "Returns a page of the wallet transactions ordered by date.",
+        "tags": [
+          "transactions"
+        ],
+        "parameters": [
+          {
+            "name": "per_page",
+            "in": "query",
+            "description": "Number of results, 20 by default.",
+            "schema": {
+              "type": "integer",
+              "minimum": 1,
+              "maximum": 50
+            }
+          },
+          {
+            "name": "from_id",
+            "in": "query",
+            "description": "Transaction to start the page from.",
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "order",
+            "in": "query",
+            "description": "Order of the results.",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "asc",
+                "desc"
+              ]
+            }
+          },
+          {
+            "name": "expand",
+            "in": "query",
+            "description": "Embeds the originating transfer in the transfer transactions.",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "transfer"
+              ]
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The transactions.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TransactionList"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/transactions/export": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "exportTransactions",
+        "summary": "Streams the whole transaction history of the wallet, without timeout.",
+        "tags": [
+          "transactions"
+        ],
+        "parameters": [
+          {
+            "name": "format",
+            "in": "query",
+            "description": "Format of the export.",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "csv",
+                "ndjson"
+              ],
+              "default": "csv"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The transactions, one per line.",
+            "content": {
+              "text/csv": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/x-ndjson": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/events": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "walletEvents",
+        "summary": "Streams the wallet transactions as Server-Sent Events as they are committed.",
+        "tags": [
+          "transactions"
+        ],
+        "parameters": [
+          {
+            "name": "Last-Event-ID",
+            "in": "header",
+            "description": "Last transaction received, to resume the stream.",
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Stream of transaction events, the event ID is the transaction ID.",
+            "content": {
+              "text/event-stream": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/transfer": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "post": {
+        "operationId": "createTransfer",
+        "summary": "Transfers funds to another wallet, or schedules the transfer if execute_at is given.",
+        "tags": [
+          "transfers"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/TransferRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "The transfer.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Transfer"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "413": {
+            "$ref": "#/components/responses/PayloadTooLarge"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/transfer/{transferID}/cancel": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        },
+        {
+          "name": "transferID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the scheduled transfer.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "post": {
+        "operationId": "cancelTransfer",
+        "summary": "Cancels a scheduled transfer.",
+        "tags": [
+          "transfers"
+        ],
+        "responses": {
+          "200": {
+            "description": "The cancelled transfer.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Transfer"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/transfers/batch": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "post": {
+        "operationId": "createBatch",
+        "summary": "Transfers funds from the wallet to many destinations.",
+        "tags": [
+          "transfers"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/BatchRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "The batch.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Batch"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "413": {
+            "$ref": "#/components/responses/PayloadTooLarge"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/transfers/batch/{batchID}": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        },
+        {
+          "name": "batchID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the batch.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "getBatch",
+        "summary": "Returns the batch and its transfers.",
+        "tags": [
+          "transfers"
+        ],
+        "responses": {
+          "200": {
+            "description": "The batch.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Batch"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/standing-orders": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "post": {
+        "operationId": "createStandingOrder",
+        "summary": "Creates a recurring transfer.",
+        "tags": [
+          "standing orders"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/StandingOrderRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "The standing order.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StandingOrder"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "413": {
+            "$ref": "#/components/responses/PayloadTooLarge"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      },
+      "get": {
+        "operationId": "listStandingOrders",
+        "summary": "Returns the standing orders of the wallet.",
+        "tags": [
+          "standing orders"
+        ],
+        "responses": {
+          "200": {
+            "description": "The standing orders.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StandingOrderList"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/standing-orders/{orderID}": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        },
+        {
+          "name": "orderID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the standing order.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "getStandingOrder",
+        "summary": "Returns the standing order.",
+        "tags": [
+          "standing orders"
+        ],
+        "responses": {
+          "200": {
+            "description": "The standing order.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StandingOrder"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      },
+      "patch": {
+        "operationId": "updateStandingOrder",
+        "summary": "Updates the amount or the message of an active standing order.",
+        "tags": [
+          "standing orders"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/StandingOrderUpdateRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "The standing order.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StandingOrder"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "413": {
+            "$ref": "#/components/responses/PayloadTooLarge"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      },
+      "delete": {
+        "operationId": "cancelStandingOrder",
+        "summary": "Cancels an active standing order.",
+        "tags": [
+          "standing orders"
+        ],
+        "responses": {
+          "200": {
+            "description": "The cancelled standing order.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StandingOrder"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/wallet/{walletID}/standing-orders/{orderID}/runs": {
+      "parameters": [
+        {
+          "name": "walletID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the wallet.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        },
+        {
+          "name": "orderID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the standing order.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "listStandingOrderRuns",
+        "summary": "Returns the executions of the standing order.",
+        "tags": [
+          "standing orders"
+        ],
+        "responses": {
+          "200": {
+            "description": "The executions.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StandingOrderRunList"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/payment-requests": {
+      "post": {
+        "operationId": "createPaymentRequest",
+        "summary": "Requests funds from the wallet of another user.",
+        "tags": [
+          "payment requests"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/PaymentRequestRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "The payment request.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PaymentRequest"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "413": {
+            "$ref": "#/components/responses/PayloadTooLarge"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      },
+      "get": {
+        "operationId": "listPaymentRequests",
+        "summary": "Returns the payment requests of the user.",
+        "tags": [
+          "payment requests"
+        ],
+        "parameters": [
+          {
+            "name": "direction",
+            "in": "query",
+            "description": "Requests received (incoming) or sent (outgoing).",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "incoming",
+                "outgoing"
+              ],
+              "default": "incoming"
+            }
+          },
+          {
+            "name": "status",
+            "in": "query",
+            "description": "Filters the requests by status.",
+            "schema": {
+              "$ref": "#/components/schemas/PaymentRequestStatus"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The payment requests.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PaymentRequestList"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/payment-requests/{requestID}": {
+      "parameters": [
+        {
+          "name": "requestID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the payment request.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "getPaymentRequest",
+        "summary": "Returns the payment request, to its requester or its payer.",
+        "tags": [
+          "payment requests"
+        ],
+        "responses": {
+          "200": {
+            "description": "The payment request.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PaymentRequest"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/payment-requests/{requestID}/accept": {
+      "parameters": [
+        {
+          "name": "requestID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the payment request.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "post": {
+        "operationId": "acceptPaymentRequest",
+        "summary": "Transfers the requested funds.",
+        "tags": [
+          "payment requests"
+        ],
+        "responses": {
+          "200": {
+            "description": "The accepted payment request.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PaymentRequest"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/payment-requests/{requestID}/decline": {
+      "parameters": [
+        {
+          "name": "requestID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the payment request.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "post": {
+        "operationId": "declinePaymentRequest",
+        "summary": "Rejects the payment request.",
+        "tags": [
+          "payment requests"
+        ],
+        "responses": {
+          "200": {
+            "description": "The declined payment request.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PaymentRequest"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks": {
+      "post": {
+        "operationId": "createWebhook",
+        "summary": "Subscribes an endpoint to the events of the wallets of the user.",
+        "tags": [
+          "webhooks"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/WebhookSubscriptionRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "The subscription.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookSubscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "413": {
+            "$ref": "#/components/responses/PayloadTooLarge"
+          },
+          "422": {
+            "$ref": "#/components/responses/UnprocessableEntity"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      },
+      "get": {
+        "operationId": "listWebhooks",
+        "summary": "Returns the subscriptions of the user.",
+        "tags": [
+          "webhooks"
+        ],
+        "responses": {
+          "200": {
+            "description": "The subscriptions.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookList"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks/{subscriptionID}": {
+      "parameters": [
+        {
+          "name": "subscriptionID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the webhook subscription.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "delete": {
+        "operationId": "deleteWebhook",
+        "summary": "Deletes the subscription and its pending deliveries.",
+        "tags": [
+          "webhooks"
+        ],
+        "responses": {
+          "204": {
+            "description": "The subscription has been deleted."
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks/{subscriptionID}/deliveries": {
+      "parameters": [
+        {
+          "name": "subscriptionID",
+          "in": "path",
+          "required": true,
+          "description": "ID of the webhook subscription.",
+          "schema": {
+            "type": "string",
+            "format": "uuid"
+          }
+        }
+      ],
+      "get": {
+        "operationId": "listWebhookDeliveries",
+        "summary": "Returns the latest 50 deliveries of the subscription and their attempts.",
+        "tags": [
+          "webhooks"
+        ],
+        "responses": {
+          "200": {
+            "description": "The deliveries.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookDeliveryList"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "423": {
+            "$ref": "#/components/responses/Locked"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/ServerError"
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "basicAuth": {
+        "type": "http",
+        "scheme": "basic"
+      }
+    },
+    "responses": {
+      "BadRequest": {
+        "description": "The request is malformed.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "Unauthorized": {
+        "description": "Missing or unknown credentials.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "Forbidden": {
+        "description": "Wrong credentials or access to a resource of another user.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "NotFound": {
+        "description": "The resource does not exist.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "NotAcceptable": {
+        "description": "None of the accepted formats is supported.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "Conflict": {
+        "description": "The current state of the resource does not allow the operation.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "PayloadTooLarge": {
+        "description": "The request body exceeds 1MB.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "Locked": {
+        "description": "Too many failed login attempts, the login or the IP cannot login until the Retry-After seconds have passed.",
+        "headers": {
+          "Retry-After": {
+            "description": "Seconds to wait before trying to login again.",
+            "schema": {
+              "type": "integer"
+            }
+          }
+        },
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "TooManyRequests": {
+        "description": "The client exceeded the rate limit of the route, it can retry after the Retry-After seconds.",
+        "headers": {
+          "RateLimit-Limit": {
+            "description": "Requests allowed in a burst.",
+            "schema": {
+              "type": "integer"
+            }
+          },
+          "RateLimit-Remaining": {
+            "description": "Requests allowed right away.",
+            "schema": {
+              "type": "integer"
+            }
+          },
+          "RateLimit-Reset": {
+            "description": "Seconds until all the requests are allowed again.",
+            "schema": {
+              "type": "integer"
+            }
+          },
+          "Retry-After": {
+            "description": "Seconds to wait before retrying the request.",
+            "schema": {
+              "type": "integer"
+            }
+          }
+        },
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "UnprocessableEntity": {
+        "description": "The request is not valid.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "ServerError": {
+        "description": "Unexpected error.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "ServiceUnavailable": {
+        "description": "The service cannot serve requests.",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      }
+    },
+    "schemas": {
+      "ProbeStatus": {
+        "type": "object",
+        "required": [
+          "status"
+        ],
+        "properties": {
+          "status": {
+            "type": "string",
+            "enum": [
+              "alive",
+              "ready"
+            ]
+          }
+        }
+      },
+      "Problem": {
+        "type": "object",
+        "description": "RFC 7807 problem details.",
+        "required": [
+          "type",
+          "title",
+          "status",
+          "detail",
+          "instance",
+          "code"
+        ],
+        "properties": {
+          "type": {
+            "type": "string",
+            "format": "uri",
+            "description": "URI of the type of problem, urn:paybile:problem:{code}."
+          },
+          "title": {
+            "type": "string",
+            "description": "Short summary of the type of problem."
+          },
+          "status": {
+            "type": "integer"
+          },
+          "detail": {
+            "type": "string",
+            "description": "Explanation of this occurrence of the problem."
+          },
+          "instance": {
+            "type": "string",
+            "description": "ID of the request, also sent in the X-Request-Id header."
+          },
+          "code": {
+            "type": "string",
+            "enum": [
+              "unreadable_body",
+              "malformed_body",
+              "invalid_body",
+              "body_too_large",
+              "unauthorized",
+              "forbidden",
+              "login_locked",
+              "rate_limited",
+              "not_found",
+              "method_not_allowed",
+              "not_acceptable",
+              "invalid_parameter",
+              "internal_error",
+              "not_ready",
+              "wallet_not_found",
+              "wallet_access_denied",
+              "invalid_amount",
+              "insufficient_funds",
+              "same_wallets",
+              "invalid_execution_date",
+              "transfer_not_found",
+              "transfer_not_cancellable",
+              "transaction_not_found",
+              "invalid_list_order",
+              "invalid_per_page",
+              "max_per_page_exceeded",
+              "invalid_expand",
+              "invalid_batch",
+              "batch_not_found",
+              "invalid_recurrence",
+              "standing_order_not_found",
+              "standing_order_not_active",
+              "payment_request_not_found",
+              "payment_request_not_pending",
+              "payment_request_expired",
+              "invalid_expiration_date",
+              "invalid_webhook",
+              "webhook_not_found"
+            ],
+            "description": "Stable code of the type of problem."
+          },
+          "details": {
+            "type": "array",
+            "description": "Problems of the request body fields, if any.",
+            "items": {
+              "$ref": "#/components/schemas/FieldError"
+            }
+          }
+        }
+      },
+      "FieldError": {
+        "type": "object",
+        "required": [
+          "field",
+          "code",
+          "message"
+        ],
+        "properties": {
+          "field": {
+            "type": "string",
+            "description": "JSON path of the field, like transfers[0].amount."
+          },
+          "code": {
+            "type": "string",
+            "enum": [
+              "required",
+              "invalid_format",
+              "invalid_type",
+              "unknown_field",
+              "too_long",
+              "too_precise",
+              "out_of_range"
+            ]
+          },
+          "message": {
+            "type": "string"
+          }
+        }
+      },
+      "Wallet": {
+        "type": "object",
+        "required": [
+          "id",
+          "user_id",
+          "balance"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "user_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "balance": {
+            "type": "number"
+          }
+        }
+      },
+      "WalletBalance": {
+        "type": "object",
+        "required": [
+          "wallet_id",
+          "balance",
+          "at"
+        ],
+        "properties": {
+          "wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "balance": {
+            "type": "number"
+          },
+          "at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "TransactionType": {
+        "type": "string",
+        "enum": [
+          "transfer",
+          "deposit"
+        ]
+      },
+      "Transaction": {
+        "type": "object",
+        "required": [
+          "id",
+          "amount",
+          "balance",
+          "transaction_type",
+          "reference_id",
+          "date"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number"
+          },
+          "balance": {
+            "type": "number"
+          },
+          "transaction_type": {
+            "$ref": "#/components/schemas/TransactionType"
+          },
+          "reference_id": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "uuid",
+            "description": "ID of the transfer that originated the transaction."
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "transfer": {
+            "$ref": "#/components/schemas/TransactionTransfer",
+            "description": "Details of the originating transfer, only with expand=transfer."
+          }
+        }
+      },
+      "TransactionTransfer": {
+        "type": "object",
+        "required": [
+          "counterparty_wallet_id",
+          "issuer_id",
+          "message"
+        ],
+        "properties": {
+          "counterparty_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "issuer_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ]
+          }
+        }
+      },
+      "TransactionList": {
+        "type": "object",
+        "required": [
+          "results",
+          "next_id"
+        ],
+        "properties": {
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Transaction"
+            }
+          },
+          "next_id": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "uuid",
+            "description": "ID of the first transaction of the next page."
+          }
+        }
+      },
+      "Statement": {
+        "type": "object",
+        "required": [
+          "wallet_id",
+          "from",
+          "to",
+          "opening_balance",
+          "closing_balance",
+          "transactions"
+        ],
+        "properties": {
+          "wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "from": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "to": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "opening_balance": {
+            "type": "number"
+          },
+          "closing_balance": {
+            "type": "number"
+          },
+          "transactions": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/StatementLine"
+            }
+          }
+        }
+      },
+      "StatementLine": {
+        "type": "object",
+        "required": [
+          "id",
+          "amount",
+          "balance",
+          "transaction_type",
+          "reference_id",
+          "date",
+          "counterparty_wallet_id",
+          "message"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number"
+          },
+          "balance": {
+            "type": "number"
+          },
+          "transaction_type": {
+            "$ref": "#/components/schemas/TransactionType"
+          },
+          "reference_id": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "uuid"
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "counterparty_wallet_id": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "uuid"
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ]
+          }
+        }
+      },
+      "TransferStatus": {
+        "type": "string",
+        "enum": [
+          "completed",
+          "scheduled",
+          "cancelled",
+          "failed"
+        ]
+      },
+      "Transfer": {
+        "type": "object",
+        "required": [
+          "id",
+          "issuer_id",
+          "origin_wallet_id",
+          "destination_wallet_id",
+          "amount",
+          "message",
+          "date",
+          "status"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "issuer_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "origin_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "destination_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number"
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ]
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "status": {
+            "$ref": "#/components/schemas/TransferStatus"
+          },
+          "execute_at": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Execution date of the scheduled transfers."
+          },
+          "failure_reason": {
+            "type": "string",
+            "description": "Reason of the failed transfers."
+          },
+          "batch_id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Batch of the transfer, if any."
+          }
+        }
+      },
+      "TransferRequest": {
+        "type": "object",
+        "required": [
+          "destination_wallet_id",
+          "amount",
+          "message"
+        ],
+        "properties": {
+          "destination_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number",
+            "description": "Amount to transfer, greater than zero.",
+            "multipleOf": 0.0001
+          },
+          "message": {
+            "type": "string",
+            "maxLength": 255
+          },
+          "execute_at": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "date-time",
+            "description": "Optional future date to schedule the transfer."
+          }
+        },
+        "additionalProperties": false
+      },
+      "BatchMode": {
+        "type": "string",
+        "enum": [
+          "atomic",
+          "best_effort"
+        ]
+      },
+      "Batch": {
+        "type": "object",
+        "required": [
+          "id",
+          "issuer_id",
+          "origin_wallet_id",
+          "mode",
+          "date",
+          "transfers"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "issuer_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "origin_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "mode": {
+            "$ref": "#/components/schemas/BatchMode"
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "transfers": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Transfer"
+            }
+          }
+        }
+      },
+      "BatchRequest": {
+        "type": "object",
+        "required": [
+          "transfers"
+        ],
+        "properties": {
+          "mode": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "enum": [
+              "atomic",
+              "best_effort",
+              null
+            ],
+            "description": "Optional, atomic by default."
+          },
+          "transfers": {
+            "type": "array",
+            "minItems": 1,
+            "maxItems": 500,
+            "items": {
+              "type": "object",
+              "required": [
+                "destination_wallet_id",
+                "amount",
+                "message"
+              ],
+              "properties": {
+                "destination_wallet_id": {
+                  "type": "string",
+                  "format": "uuid"
+                },
+                "amount": {
+                  "type": "number",
+                  "multipleOf": 0.0001
+                },
+                "message": {
+                  "type": "string",
+                  "maxLength": 255
+                }
+              },
+              "additionalProperties": false
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "Recurrence": {
+        "type": "object",
+        "required": [
+          "frequency",
+          "start_date",
+          "end_date",
+          "count"
+        ],
+        "properties": {
+          "frequency": {
+            "type": "string",
+            "enum": [
+              "daily",
+              "weekly",
+              "monthly"
+            ]
+          },
+          "day_of_month": {
+            "type": "integer",
+            "minimum": 1,
+            "maximum": 31,
+            "description": "Day of the monthly executions, clamped to the last day of shorter months."
+          },
+          "start_date": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Date of the first execution, its time is used for all the executions."
+          },
+          "end_date": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "date-time",
+            "description": "Optional date after which there are no more executions."
+          },
+          "count": {
+            "type": [
+              "integer",
+              "null"
+            ],
+            "minimum": 1,
+            "description": "Optional maximum number of executions."
+          }
+        }
+      },
+      "StandingOrderStatus": {
+        "type": "string",
+        "enum": [
+          "active",
+          "cancelled",
+          "finished"
+        ]
+      },
+      "StandingOrder": {
+        "type": "object",
+        "required": [
+          "id",
+          "issuer_id",
+          "origin_wallet_id",
+          "destination_wallet_id",
+          "amount",
+          "message",
+          "recurrence",
+          "status",
+          "runs",
+          "next_run_at",
+          "created_at"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "issuer_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "origin_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "destination_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number"
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ]
+          },
+          "recurrence": {
+            "$ref": "#/components/schemas/Recurrence"
+          },
+          "status": {
+            "$ref": "#/components/schemas/StandingOrderStatus"
+          },
+          "runs": {
+            "type": "integer"
+          },
+          "next_run_at": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "date-time"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "StandingOrderList": {
+        "type": "object",
+        "required": [
+          "results"
+        ],
+        "properties": {
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/StandingOrder"
+            }
+          }
+        }
+      },
+      "StandingOrderRequest": {
+        "type": "object",
+        "required": [
+          "destination_wallet_id",
+          "amount",
+          "message",
+          "recurrence"
+        ],
+        "properties": {
+          "destination_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number",
+            "multipleOf": 0.0001
+          },
+          "message": {
+            "type": "string",
+            "maxLength": 255
+          },
+          "recurrence": {
+            "$ref": "#/components/schemas/Recurrence"
+          }
+        },
+        "additionalProperties": false
+      },
+      "StandingOrderUpdateRequest": {
+        "type": "object",
+        "required": [],
+        "properties": {
+          "amount": {
+            "type": [
+              "number",
+              "null"
+            ],
+            "multipleOf": 0.0001
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "maxLength": 255
+          }
+        },
+        "additionalProperties": false
+      },
+      "StandingOrderRun": {
+        "type": "object",
+        "required": [
+          "id",
+          "standing_order_id",
+          "transfer_id",
+          "scheduled_at",
+          "date",
+          "status",
+          "failure_reason"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "standing_order_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "transfer_id": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "uuid",
+            "description": "Transfer created by the execution, null if it failed."
+          },
+          "scheduled_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "status": {
+            "$ref": "#/components/schemas/TransferStatus"
+          },
+          "failure_reason": {
+            "type": [
+              "string",
+              "null"
+            ]
+          }
+        }
+      },
+      "StandingOrderRunList": {
+        "type": "object",
+        "required": [
+          "results"
+        ],
+        "properties": {
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/StandingOrderRun"
+            }
+          }
+        }
+      },
+      "PaymentRequestStatus": {
+        "type": "string",
+        "enum": [
+          "pending",
+          "accepted",
+          "declined",
+          "expired"
+        ]
+      },
+      "PaymentRequest": {
+        "type": "object",
+        "required": [
+          "id",
+          "requester_id",
+          "requester_wallet_id",
+          "payer_id",
+          "payer_wallet_id",
+          "amount",
+          "message",
+          "status",
+          "transfer_id",
+          "expires_at",
+          "created_at",
+          "updated_at"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "requester_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "requester_wallet_id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Wallet that receives the funds."
+          },
+          "payer_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "payer_wallet_id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Wallet that sends the funds."
+          },
+          "amount": {
+            "type": "number"
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ]
+          },
+          "status": {
+            "$ref": "#/components/schemas/PaymentRequestStatus"
+          },
+          "transfer_id": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "uuid",
+            "description": "Transfer created when the request is accepted."
+          },
+          "expires_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "updated_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "PaymentRequestList": {
+        "type": "object",
+        "required": [
+          "results"
+        ],
+        "properties": {
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/PaymentRequest"
+            }
+          }
+        }
+      },
+      "PaymentRequestRequest": {
+        "type": "object",
+        "required": [
+          "wallet_id",
+          "payer_wallet_id",
+          "amount"
+        ],
+        "properties": {
+          "wallet_id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Wallet of the requester that receives the funds."
+          },
+          "payer_wallet_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "amount": {
+            "type": "number",
+            "multipleOf": 0.0001
+          },
+          "message": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "maxLength": 255
+          },
+          "expires_at": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "date-time",
+            "description": "Optional expiration date, 7 days by default."
+          }
+        },
+        "additionalProperties": false
+      },
+      "EventType": {
+        "type": "string",
+        "enum": [
+          "transfer.created",
+          "deposit.created"
+        ]
+      },
+      "WebhookSubscription": {
+        "type": "object",
+        "required": [
+          "id",
+          "user_id",
+          "url",
+          "event_types",
+          "created_at"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "user_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "url": {
+            "type": "string",
+            "format": "uri"
+          },
+          "event_types": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/EventType"
+            }
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "WebhookList": {
+        "type": "object",
+        "required": [
+          "results"
+        ],
+        "properties": {
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/WebhookSubscription"
+            }
+          }
+        }
+      },
+      "WebhookSubscriptionRequest": {
+        "type": "object",
+        "required": [
+          "url",
+          "secret",
+          "event_types"
+        ],
+        "properties": {
+          "url": {
+            "type": "string",
+            "format": "uri",
+            "description": "Absolute http or https URL of the endpoint."
+          },
+          "secret": {
+            "type": "string",
+            "minLength": 16,
+            "description": "Secret to sign the payloads, it is never returned."
+          },
+          "event_types": {
+            "type": "array",
+            "minItems": 1,
+            "items": {
+              "$ref": "#/components/schemas/EventType"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "WebhookDelivery": {
+        "type": "object",
+        "required": [
+          "id",
+          "subscription_id",
+          "event_id",
+          "event_type",
+          "payload",
+          "status",
+          "attempts",
+          "next_attempt_at",
+          "created_at",
+          "delivered_at",
+          "log"
+        ],
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "subscription_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "event_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "event_type": {
+            "$ref": "#/components/schemas/EventType"
+          },
+          "payload": {
+            "type": "object"
+          },
+          "status": {
+            "type": "string",
+            "enum": [
+              "pending",
+              "delivered",
+              "dead"
+            ]
+          },
+          "attempts": {
+            "type": "integer"
+          },
+          "next_attempt_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "delivered_at": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "format": "date-time"
+          },
+          "log": {
+            "type": [
+              "array",
+              "null"
+            ],
+            "items": {
+              "$ref": "#/components/schemas/WebhookDeliveryAttempt"
+            }
+          }
+        }
+      },
+      "WebhookDeliveryAttempt": {
+        "type": "object",
+        "required": [
+          "attempt",
+          "status_code",
+          "error",
+          "duration_ms",
+          "date"
+        ],
+        "properties": {
+          "attempt": {
+            "type": "integer"
+          },
+          "status_code": {
+            "type": [
+              "integer",
+              "null"
+            ],
+            "description": "Response status, null if the request failed."
+          },
+          "error": {
+            "type": [
+              "string",
+              "null"
+            ]
+          },
+          "duration_ms": {
+            "type": "integer"
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "WebhookDeliveryList": {
+        "type": "object",
+        "required": [
+          "results"
+        ],
+        "properties": {
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/WebhookDelivery"
+            }
+          }
+        }
+      }
+    }
+  }
+}`
@@ -0,0 +1,168 @@
+package http_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  map[string]interface{}
+	openAPIErr  error
+)
+
+// openAPI returns the OpenAPI document served by the API.
+func openAPI(t *testing.T) map[string]interface{} {
+	t.Helper()
+	openAPIOnce.Do(func() {
+		api := httptransport.ApiService{}
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+		rr := httptest.NewRecorder()
+		api.Handler().ServeHTTP(rr, req)
+		openAPIErr = json.Unmarshal(rr.Body.Bytes(), &openAPIDoc)
+	})
+	if openAPIErr != nil {
+		t.Fatalf("cannot decode the OpenAPI document: %v", openAPIErr)
+	}
+	return openAPIDoc
+}
+
+// assertOpenAPIResponse validates the response against the
+// operation of the OpenAPI document that matches the request.
+func assertOpenAPIResponse(t *testing.T, req *http.Request, res *http.Response) {
+	t.Helper()
+	doc := openAPI(t)
+
+	path, ok := openAPIPath(doc, req.URL.Path)
+	if !ok {
+		t.Fatalf("path %s is not documented", req.URL.Path)
+	}
+	method := strings.ToLower(req.Method)
+	if method == "" {
+		method = "get"
+	}
+	op, ok := object(object(doc["paths"])[path])[method]
+	if !ok {
+		t.Fatalf("operation %s %s is not documented", req.Method, path)
+	}
+	r, ok := object(object(op)["responses"])[strconv.Itoa(res.StatusCode)]
+	if !ok {
+		t.Fatalf("status %d of %s %s is not documented", res.StatusCode, req.Method, path)
+	}
+	if ref, ok := object(r)["$ref"].(string); ok {
+		r = object(object(doc["components"])["responses"])[strings.TrimPrefix(ref, "#/components/responses/")]
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("cannot read the response body: %v", err)
+	}
+	content := object(object(r)["content"])
+	if len(content) == 0 {
+		if len(body) > 0 {
+			t.Fatalf("undocumented body of the status %d of %s %s", res.StatusCode, req.Method, path)
+		}
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	media, ok := content[mediaType]
+	if !ok {
+		t.Fatalf("content type %s of %s %s is not documented", mediaType, req.Method, path)
+	}
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return
+	}
+
+	// The referenced schemas are resolved against the components of the document.
+	schema := map[string]interface{}{"components": doc["components"]}
+	for k, v := range object(object(media)["schema"]) {
+		schema[k] = v
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema), gojsonschema.NewBytesLoader(body))
+	if err != nil {
+		t.Fatalf("cannot validate the response of %s %s: %v", req.Method, path, err)
+	}
+	if !result.Valid() {
+		t.Fatalf("the response of %s %s does not match the OpenAPI document: %v\n%s", req.Method, path, result.Errors(), body)
+	}
+}
+
+// openAPIPath returns the documented path template that matches the path.
+func openAPIPath(doc map[string]interface{}, path string) (string, bool) {
+	var (
+		segments = strings.Split(path, "/")
+		match    string
+		literals = -1
+	)
+	for tpl := range object(doc["paths"]) {
+		parts := strings.Split(tpl, "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		n := 0
+		for i, p := range parts {
+			if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+				continue
+			}
+			if p != segments[i] {
+				n = -1
+				break
+			}
+			n++
+		}
+		if n > literals {
+			match, literals = tpl, n
+		}
+	}
+	return match, literals >= 0
+}
+
+func object(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	t.Parallel()
+	var (
+		doc    = openAPI(t)
+		api    = httptransport.ApiService{}
+		routes = make(map[string]bool)
+	)
+
+	err := chi.Walk(api.Handler().(chi.Routes), func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		op := strings.ToLower(method) + " " + route
+		routes[op] = true
+		if _, ok := object(object(doc["paths"])[route])[strings.ToLower(method)]; !ok {
+			t.Errorf("route %s %s is not documented", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("cannot walk the routes: %v", err)
+	}
+
+	for path, item := range object(doc["paths"]) {
+		for method := range object(item) {
+			// The health and the probes are middlewares, not routes.
+			if method == "parameters" || path == "/api/v1/health" || path == httptransport.LivenessPath || path == httptransport.ReadinessPath {
+				continue
+			}
+			if !routes[method+" "+path] {
+				t.Errorf("operation %s %s is documented but not routed", method, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+type PaymentRequestRequest struct {
+	// WalletID requester's wallet that will receive the funds.
+	WalletID      *string  `json:"wallet_id"`
+	PayerWalletID *string  `json:"payer_wallet_id"`
+	Amount        *float64 `json:"amount"`
+	Message       *string  `json:"message"`
+	// ExpiresAt optional RFC3339 expiration date.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type PaymentRequestManager interface {
+	CreatePaymentRequest(ctx context.Context, req service.PaymentRequestRequest) (service.PaymentRequest, error)
+	GetPaymentRequest(ctx context.Context, user service.User, requestID uuid.UUID) (service.PaymentRequest, error)
+	ListPaymentRequests(
+		ctx context.Context,
+		user service.User,
+		direction service.PaymentRequestDirection,
+		status *service.PaymentRequestStatus,
+	) ([]service.PaymentRequest, error)
+	AcceptPaymentRequest(ctx context.Context, user service.User, requestID uuid.UUID) (service.PaymentRequest, error)
+	DeclinePaymentRequest(ctx context.Context, user service.User, requestID uuid.UUID) (service.PaymentRequest, error)
+}
+
+type PaymentRequestList struct {
+	Results []service.PaymentRequest `json:"results"`
+}
+
+func CreatePaymentRequestHandler(svc PaymentRequestManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var x PaymentRequestRequest
+		if !decodeBody(w, r, &x) {
+			return
+		}
+
+		var v validator
+		v.required("wallet_id", x.WalletID != nil)
+		walletID := v.uuid("wallet_id", x.WalletID)
+		v.required("payer_wallet_id", x.PayerWalletID != nil)
+		payerWalletID := v.uuid("payer_wallet_id", x.PayerWalletID)
+		v.required("amount", x.Amount != nil)
+		v.amount("amount", x.Amount)
+		v.message("message", x.Message)
+		if !v.valid(w, r) {
+			return
+		}
+
+		p, err := svc.CreatePaymentRequest(r.Context(), service.PaymentRequestRequest{
+			Requester:     requestUser(r),
+			WalletID:      walletID,
+			PayerWalletID: payerWalletID,
+			Amount:        *x.Amount,
+			Message:       x.Message,
+			ExpiresAt:     x.ExpiresAt,
+		})
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusCreated, p)
+	}
+}
+
+func ListPaymentRequestsHandler(svc PaymentRequestManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			direction = service.PaymentRequestDirection(r.URL.Query().Get("direction"))
+			status    *service.PaymentRequestStatus
+		)
+
+		switch direction {
+		case "":
+			direction = service.IncomingPaymentRequests
+		case service.IncomingPaymentRequests, service.OutgoingPaymentRequests:
+		default:
+			writeError(w, r, invalidParameterErr.Err(errors.New("direction must be incoming or outgoing")), nil)
+			return
+		}
+		if s := r.URL.Query().Get("status"); s != "" {
+			x := service.PaymentRequestStatus(s)
+			switch x {
+			case service.PaymentRequestPending,
+				service.PaymentRequestAccepted,
+				service.PaymentRequestDeclined,
+				service.PaymentRequestExpired:
+				status = &x
+			default:
+				writeError(w, r, invalidParameterErr.Err(errors.New("status must be pending, accepted, declined or expired")), nil)
+				return
+			}
+		}
+
+		l, err := svc.ListPaymentRequests(r.Context(), requestUser(r), direction, status)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, PaymentRequestList{Results: l})
+	}
+}
+
+func GetPaymentRequestHandler(svc PaymentRequestManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID, ok := paymentRequestParams(w, r)
+		if !ok {
+			return
+		}
+
+		p, err := svc.GetPaymentRequest(r.Context(), requestUser(r), requestID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, p)
+	}
+}
+
+func AcceptPaymentRequestHandler(svc PaymentRequestManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID, ok := paymentRequestParams(w, r)
+		if !ok {
+			return
+		}
+
+		p, err := svc.AcceptPaymentRequest(r.Context(), requestUser(r), requestID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, p)
+	}
+}
+
+func DeclinePaymentRequestHandler(svc PaymentRequestManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID, ok := paymentRequestParams(w, r)
+		if !ok {
+			return
+		}
+
+		p, err := svc.DeclinePaymentRequest(r.Context(), requestUser(r), requestID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, p)
+	}
+}
+
+// paymentRequestParams parses the payment request ID, writing a not found error if invalid.
+func paymentRequestParams(w http.ResponseWriter, r *http.Request) (requestID uuid.UUID, ok bool) {
+	requestID, err := uuid.Parse(urlParam(r, "requestID"))
+	if err != nil {
+		writeError(w, r, notFoundErr, nil)
+		return requestID, false
+	}
+
+	return requestID, true
+}
@@ -0,0 +1,221 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPaymentRequestHandlers(t *testing.T) {
+	t.Parallel()
+	var (
+		user        = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		login       = "foo"
+		pass        = "bar"
+		auth        = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		headers     = map[string][]string{"Authorization": {"Basic " + auth}}
+		requestID   = uuid.MustParse("0b5e0f4c-3b4b-44e1-a0a5-8b3f1f3f86a1")
+		expiresAt   = time.Date(2030, 1, 31, 10, 0, 0, 0, time.UTC)
+		message     = "dinner"
+		basePath    = "/api/v1/payment-requests"
+		requestPath = basePath + "/" + requestID.String()
+		pending     = service.PaymentRequestPending
+		p           = service.PaymentRequest{
+			ID:                requestID,
+			RequesterID:       user.ID,
+			RequesterWalletID: uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67"),
+			PayerID:           uuid.MustParse("6aacb72a-264d-4bc3-b2f9-9fb26a78a449"),
+			PayerWalletID:     uuid.MustParse("ba2428bd-88bb-44aa-9428-688d41817dc5"),
+			Amount:            20,
+			Message:           &message,
+			Status:            service.PaymentRequestPending,
+			ExpiresAt:         expiresAt,
+			CreatedAt:         time.Now(),
+			UpdatedAt:         time.Now(),
+		}
+	)
+	requestJSON, _ := json.Marshal(&p)
+	listJSON, _ := json.Marshal(&httptransport.PaymentRequestList{Results: []service.PaymentRequest{p}})
+
+	buildReq := func(method string, path string, body string) http.Request {
+		r, _ := http.NewRequest(method, "", bytes.NewBuffer([]byte(body)))
+		r.Header = headers
+		r.URL, _ = url.Parse(path)
+		return *r
+	}
+
+	tt := []struct {
+		name       string
+		req        http.Request
+		expect     func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: basePath},
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "create missing payer wallet",
+			req:  buildReq(http.MethodPost, basePath, `{"wallet_id": "b272dc21-e006-4a41-a120-2b8f26b61a67", "amount": 20}`),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"payer_wallet_id","code":"required","message":"missing payer_wallet_id"}]}`,
+		},
+		{
+			name: "create invalid expiration date",
+			req: buildReq(http.MethodPost, basePath, `{"wallet_id": "b272dc21-e006-4a41-a120-2b8f26b61a67",
+				"payer_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 20, "expires_at": "2020-01-01T00:00:00Z"}`),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("CreatePaymentRequest", mock.Anything, mock.Anything).Return(service.PaymentRequest{}, service.ErrInvalidExpirationDate)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_expiration_date","title":"Invalid expiration date","status":422,"detail":"expiration date must be in the future","instance":"test-request","code":"invalid_expiration_date"}`,
+		},
+		{
+			name: "create ok",
+			req: buildReq(http.MethodPost, basePath, `{"wallet_id": "b272dc21-e006-4a41-a120-2b8f26b61a67",
+				"payer_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 20, "message": "dinner", "expires_at": "2030-01-31T10:00:00Z"}`),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("CreatePaymentRequest", mock.Anything, service.PaymentRequestRequest{
+					Requester:     user,
+					WalletID:      p.RequesterWalletID,
+					PayerWalletID: p.PayerWalletID,
+					Amount:        20,
+					Message:       &message,
+					ExpiresAt:     &expiresAt,
+				}).Return(p, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   string(requestJSON),
+		},
+		{
+			name: "list invalid direction",
+			req:  buildReq(http.MethodGet, basePath+"?direction=foo", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"direction must be incoming or outgoing","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name: "list incoming pending",
+			req:  buildReq(http.MethodGet, basePath+"?status=pending", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("ListPaymentRequests", mock.Anything, user, service.IncomingPaymentRequests, &pending).
+					Return([]service.PaymentRequest{p}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(listJSON),
+		},
+		{
+			name: "get not found",
+			req:  buildReq(http.MethodGet, requestPath, ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("GetPaymentRequest", mock.Anything, user, requestID).Return(service.PaymentRequest{}, service.ErrPaymentRequestNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:payment_request_not_found","title":"Payment request not found","status":404,"detail":"payment request not found","instance":"test-request","code":"payment_request_not_found"}`,
+		},
+		{
+			name: "accept expired",
+			req:  buildReq(http.MethodPost, requestPath+"/accept", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("AcceptPaymentRequest", mock.Anything, user, requestID).Return(service.PaymentRequest{}, service.ErrPaymentRequestExpired)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"urn:paybile:problem:payment_request_expired","title":"Payment request expired","status":409,"detail":"payment request has expired","instance":"test-request","code":"payment_request_expired"}`,
+		},
+		{
+			name: "accept insufficient funds",
+			req:  buildReq(http.MethodPost, requestPath+"/accept", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("AcceptPaymentRequest", mock.Anything, user, requestID).
+					Return(service.PaymentRequest{}, fmt.Errorf("cannot transfer funds: %w", service.ErrInsufficientFunds))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:insufficient_funds","title":"Insufficient funds","status":422,"detail":"origin has insufficient funds","instance":"test-request","code":"insufficient_funds"}`,
+		},
+		{
+			name: "accept ok",
+			req:  buildReq(http.MethodPost, requestPath+"/accept", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("AcceptPaymentRequest", mock.Anything, user, requestID).Return(p, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(requestJSON),
+		},
+		{
+			name: "decline not pending",
+			req:  buildReq(http.MethodPost, requestPath+"/decline", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("DeclinePaymentRequest", mock.Anything, user, requestID).Return(service.PaymentRequest{}, service.ErrPaymentRequestNotPending)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"urn:paybile:problem:payment_request_not_pending","title":"Payment request not pending","status":409,"detail":"payment request is not pending","instance":"test-request","code":"payment_request_not_pending"}`,
+		},
+		{
+			name: "decline ok",
+			req:  buildReq(http.MethodPost, requestPath+"/decline", ""),
+			expect: func(pm *mocks.PaymentRequestManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				pm.On("DeclinePaymentRequest", mock.Anything, user, requestID).Return(p, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(requestJSON),
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			pm := &mocks.PaymentRequestManager{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(pm, ls)
+			}
+
+			api := httptransport.ApiService{
+				PaymentRequests: pm,
+				LoginService:    ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Paths of the probes, they are served before the API
+// middlewares so they are neither traced nor audited.
+const (
+	LivenessPath  = "/livez"
+	ReadinessPath = "/readyz"
+)
+
+// DefaultReadinessTimeout of the readiness checks.
+const DefaultReadinessTimeout = 2 * time.Second
+
+var (
+	notReadyErr = apiError{
+		Status: http.StatusServiceUnavailable,
+		Code:   "not_ready",
+		Title:  "Not ready",
+		error:  errors.New("the service is not ready"),
+	}
+	drainingErr = notReadyErr.Err(errors.New("the service is shutting down"))
+)
+
+type ReadinessChecker interface {
+	// CheckReady returns an error if the service cannot serve requests.
+	CheckReady(ctx context.Context) error
+}
+
+type probeResponse struct {
+	Status string `json:"status"`
+}
+
+// Drain marks the service as not ready, so the load balancer stops
+// sending requests before the server is shut down. It cannot be undone.
+func (svc *ApiService) Drain() {
+	atomic.StoreInt32(&svc.draining, 1)
+}
+
+// probes serves the liveness and the readiness probes. The service is
+// alive while it can answer requests, and ready when it is not draining
+// and the readiness checks succeed.
+func (svc *ApiService) probes() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			switch r.URL.Path {
+			case LivenessPath:
+				writeResponse(w, http.StatusOK, probeResponse{Status: "alive"})
+			case ReadinessPath:
+				svc.readiness(w, r)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+func (svc *ApiService) readiness(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&svc.draining) == 1 {
+		writeError(w, r, drainingErr, nil)
+		return
+	}
+	if svc.Readiness != nil {
+		timeout := svc.ReadinessTimeout
+		if timeout <= 0 {
+			timeout = DefaultReadinessTimeout
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		// The cause is logged but not disclosed, the probe is public.
+		if err := svc.Readiness.CheckReady(ctx); err != nil {
+			writeError(w, r, notReadyErr, err)
+			return
+		}
+	}
+
+	writeResponse(w, http.StatusOK, probeResponse{Status: "ready"})
+}
@@ -0,0 +1,101 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProbes(t *testing.T) {
+	t.Parallel()
+	withDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+		_, ok := ctx.Deadline()
+		return ok
+	})
+
+	tt := []struct {
+		name       string
+		path       string
+		expect     func(rc *mocks.ReadinessChecker)
+		drain      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "alive",
+			path:       httptransport.LivenessPath,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"alive"}`,
+		},
+		{
+			name:       "alive while draining",
+			path:       httptransport.LivenessPath,
+			drain:      true,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"alive"}`,
+		},
+		{
+			name: "ready",
+			path: httptransport.ReadinessPath,
+			expect: func(rc *mocks.ReadinessChecker) {
+				rc.On("CheckReady", withDeadline).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ready"}`,
+		},
+		{
+			name: "not ready",
+			path: httptransport.ReadinessPath,
+			expect: func(rc *mocks.ReadinessChecker) {
+				rc.On("CheckReady", withDeadline).Return(errors.New("cannot ping database"))
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"type":"urn:paybile:problem:not_ready","title":"Not ready","status":503,"detail":"the service is not ready","instance":"test-request","code":"not_ready"}`,
+		},
+		{
+			name:       "draining",
+			path:       httptransport.ReadinessPath,
+			drain:      true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"type":"urn:paybile:problem:not_ready","title":"Not ready","status":503,"detail":"the service is shutting down","instance":"test-request","code":"not_ready"}`,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			rc := &mocks.ReadinessChecker{}
+			if tc.expect != nil {
+				tc.expect(rc)
+			}
+			api := httptransport.ApiService{Readiness: rc}
+			if tc.drain {
+				api.Drain()
+			}
+
+			req := http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: tc.path},
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &req, res)
+			rc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/hmoragrega/paybile/service"
+	"github.com/rs/zerolog/log"
+)
+
+var rateLimitedErr = apiError{
+	Status: http.StatusTooManyRequests,
+	Code:   "rate_limited",
+	Title:  "Too many requests",
+	error:  errors.New("too many requests"),
+}
+
+// Routes that move funds, they usually have stricter policies.
+const (
+	TransferRoute      = "POST /api/v1/wallet/{walletID}/transfer"
+	BatchTransferRoute = "POST /api/v1/wallet/{walletID}/transfers/batch"
+)
+
+// RateLimitPolicy token bucket that holds up to Limit requests
+// and is refilled completely every Period. A zero limit
+// disables the rate limit of the route.
+type RateLimitPolicy struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimitResult outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	// Allowed the bucket had a token for the request.
+	Allowed bool
+	// Remaining requests allowed right away.
+	Remaining int
+	// Reset time when the bucket will be full again.
+	Reset time.Time
+	// RetryAt time when the next token will be available
+	// if the request was not allowed.
+	RetryAt time.Time
+}
+
+type RateLimitStore interface {
+	// Take takes a token from the bucket of the key at the given time.
+	Take(ctx context.Context, key string, p RateLimitPolicy, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimiter limits the requests of each user, or IP if the
+// request is not authenticated, to every route.
+type RateLimiter struct {
+	Store RateLimitStore
+	// IP policy of all the requests of a client IP, it is applied
+	// before the authentication so the requests with wrong
+	// credentials are limited too.
+	IP RateLimitPolicy
+	// Default policy of the routes without one.
+	Default RateLimitPolicy
+	// Routes policies by method and route pattern,
+	// like "POST /api/v1/wallet/{walletID}/transfer".
+	Routes map[string]RateLimitPolicy
+}
+
+// Policy returns the policy of the route, or the default one.
+func (l *RateLimiter) Policy(route string) RateLimitPolicy {
+	if p, ok := l.Routes[route]; ok {
+		return p
+	}
+	return l.Default
+}
+
+// ipRateLimitMiddleware rejects the requests of the client IPs that exceed
+// the IP policy, it runs before the authentication. The headers are only
+// set on the rejected requests, the route limits describe the rest.
+func (svc *ApiService) ipRateLimitMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := svc.RateLimiter
+			if l == nil || l.IP.Limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now()
+			ip := service.AuditMetadataFromContext(r.Context()).IP
+			res, err := l.Store.Take(r.Context(), "ip:"+ip, l.IP, now)
+			if err != nil {
+				log.Error().Err(err).Str("ip", ip).Msg("cannot rate limit request")
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !res.Allowed {
+				setRateLimitHeaders(w.Header(), l.IP, res, now)
+				writeError(w, r, rateLimitedErr, nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitMiddleware rejects the requests that exceed the policy of
+// the route. It must be used inline in the routes, so the route pattern
+// and the user are known. The requests are allowed if the store fails.
+func (svc *ApiService) rateLimitMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := svc.RateLimiter
+			if l == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route := r.Method + " " + chi.RouteContext(r.Context()).RoutePattern()
+			p := l.Policy(route)
+			if p.Limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now()
+			res, err := l.Store.Take(r.Context(), route+" "+rateLimitKey(r), p, now)
+			if err != nil {
+				log.Error().Err(err).Str("route", route).Msg("cannot rate limit request")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			setRateLimitHeaders(w.Header(), p, res, now)
+			if !res.Allowed {
+				writeError(w, r, rateLimitedErr, nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setRateLimitHeaders sets the headers of the bucket, and
+// when to retry if the request was not allowed.
+func setRateLimitHeaders(h http.Header, p RateLimitPolicy, res RateLimitResult, now time.Time) {
+	h.Set("RateLimit-Limit", strconv.Itoa(p.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(secondsUntil(now, res.Reset)))
+	if !res.Allowed {
+		h.Set("Retry-After", strconv.Itoa(secondsUntil(now, res.RetryAt)))
+	}
+}
+
+// rateLimitKey identifies the client of the request, the
+// authenticated user or the IP if there is none.
+func rateLimitKey(r *http.Request) string {
+	if u, ok := r.Context().Value(userIDCtxKey{}).(service.User); ok {
+		return "user:" + u.ID.String()
+	}
+	return "ip:" + service.AuditMetadataFromContext(r.Context()).IP
+}
+
+// secondsUntil returns the seconds from now until the given time, rounded up.
+func secondsUntil(now, t time.Time) int {
+	if !t.After(now) {
+		return 0
+	}
+	return int(math.Ceil(t.Sub(now).Seconds()))
+}
+
+// rateLimitBucket state of a token bucket.
+type rateLimitBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket with the tokens accrued since it was
+// updated and takes one for the request, if there is any.
+func (p RateLimitPolicy) take(b *rateLimitBucket, now time.Time) RateLimitResult {
+	limit := float64(p.Limit)
+	perToken := p.Period / time.Duration(p.Limit)
+
+	if b.updated.IsZero() {
+		b.tokens = limit
+	} else if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(limit, b.tokens+float64(elapsed)/float64(perToken))
+	}
+	b.updated = now
+
+	var res RateLimitResult
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAt = now.Add(time.Duration((1 - b.tokens) * float64(perToken)))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = now.Add(time.Duration((limit - b.tokens) * float64(perToken)))
+
+	return res
+}
+
+// MemoryRateLimitStore keeps the token buckets in memory, the
+// limits are not shared with other instances of the service.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	swept   time.Time
+}
+
+type memoryBucket struct {
+	rateLimitBucket
+	// full time when the bucket is full again, it
+	// can be forgotten after it.
+	full time.Time
+}
+
+// memorySweepInterval how often the full buckets are removed.
+const memorySweepInterval = time.Minute
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, p RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buckets == nil {
+		s.buckets = make(map[string]*memoryBucket)
+	}
+	if now.Sub(s.swept) >= memorySweepInterval {
+		for k, b := range s.buckets {
+			if !b.full.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		s.buckets[key] = b
+	}
+	res := p.take(&b.rateLimitBucket, now)
+	b.full = res.Reset
+
+	return res, nil
+}
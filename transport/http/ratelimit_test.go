@@ -0,0 +1,293 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+	var (
+		userA    = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		userB    = service.User{ID: uuid.MustParse("a0d4b8ec-5b8a-4f0e-9f51-6c3f3fb4a1a2")}
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+	)
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, "user_a", "pass").Return(userA, nil)
+	ls.On("Login", mock.Anything, "user_b", "pass").Return(userB, nil)
+	wg := &mocks.WalletGetter{}
+	wg.On("GetWallet", mock.Anything, mock.Anything, walletID).Return(service.Wallet{ID: walletID}, nil)
+
+	api := httptransport.ApiService{
+		LoginService: ls,
+		WalletGetter: wg,
+		RateLimiter: &httptransport.RateLimiter{
+			Store:   &httptransport.MemoryRateLimitStore{},
+			Default: httptransport.RateLimitPolicy{Limit: 2, Period: time.Minute},
+			Routes: map[string]httptransport.RateLimitPolicy{
+				"GET /api/v1/openapi.json": {},
+			},
+		},
+	}
+	handler := api.Handler()
+
+	tt := []struct {
+		name          string
+		login         string
+		path          string
+		wantStatus    int
+		wantLimit     string
+		wantRemaining string
+		wantReset     string
+		wantRetry     string
+	}{
+		{
+			name:          "first request of user A",
+			login:         "user_a",
+			path:          "/api/v1/wallet/" + walletID.String(),
+			wantStatus:    http.StatusOK,
+			wantLimit:     "2",
+			wantRemaining: "1",
+			wantReset:     "30",
+		},
+		{
+			name:          "second request of user A",
+			login:         "user_a",
+			path:          "/api/v1/wallet/" + walletID.String(),
+			wantStatus:    http.StatusOK,
+			wantLimit:     "2",
+			wantRemaining: "0",
+			wantReset:     "60",
+		},
+		{
+			name:          "user A is rate limited",
+			login:         "user_a",
+			path:          "/api/v1/wallet/" + walletID.String(),
+			wantStatus:    http.StatusTooManyRequests,
+			wantLimit:     "2",
+			wantRemaining: "0",
+			wantReset:     "60",
+			wantRetry:     "30",
+		},
+		{
+			name:          "user B has its own limit",
+			login:         "user_b",
+			path:          "/api/v1/wallet/" + walletID.String(),
+			wantStatus:    http.StatusOK,
+			wantLimit:     "2",
+			wantRemaining: "1",
+			wantReset:     "30",
+		},
+		{
+			name:       "route without limit",
+			path:       "/api/v1/openapi.json",
+			wantStatus: http.StatusOK,
+		},
+	}
+	// The requests depend on the previous ones, they cannot run in parallel.
+	for _, tc := range tt {
+		req := http.Request{
+			Method: http.MethodGet,
+			URL:    &url.URL{Path: tc.path},
+			Header: http.Header{},
+		}
+		if tc.login != "" {
+			req.SetBasicAuth(tc.login, "pass")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, withRequestID(&req))
+		res := rr.Result()
+
+		if got := res.StatusCode; got != tc.wantStatus {
+			t.Fatalf("%s: unexpected status: \n got:  %+v \n want: %+v", tc.name, got, tc.wantStatus)
+		}
+		for header, want := range map[string]string{
+			"RateLimit-Limit":     tc.wantLimit,
+			"RateLimit-Remaining": tc.wantRemaining,
+			"RateLimit-Reset":     tc.wantReset,
+			"Retry-After":         tc.wantRetry,
+		} {
+			if got := res.Header.Get(header); got != want {
+				t.Fatalf("%s: unexpected %s header: \n got:  %+v \n want: %+v", tc.name, header, got, want)
+			}
+		}
+		if tc.wantStatus == http.StatusTooManyRequests {
+			wantBody := `{"type":"urn:paybile:problem:rate_limited","title":"Too many requests","status":429,"detail":"too many requests","instance":"test-request","code":"rate_limited"}`
+			if got := rr.Body.String(); got != wantBody {
+				t.Fatalf("%s: unexpected body: \n got:  %+v \n want: %+v", tc.name, got, wantBody)
+			}
+		}
+		assertOpenAPIResponse(t, &req, res)
+	}
+}
+
+func TestIPRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+	var (
+		user     = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+	)
+
+	ls := &mocks.LoginService{}
+	ls.On("Login", mock.Anything, "user_a", "pass").Return(user, nil)
+	ls.On("Login", mock.Anything, "user_a", "wrong").Return(service.User{}, service.ErrInvalidPassword)
+	wg := &mocks.WalletGetter{}
+	wg.On("GetWallet", mock.Anything, mock.Anything, walletID).Return(service.Wallet{ID: walletID}, nil)
+
+	api := httptransport.ApiService{
+		LoginService: ls,
+		WalletGetter: wg,
+		RateLimiter: &httptransport.RateLimiter{
+			Store:   &httptransport.MemoryRateLimitStore{},
+			IP:      httptransport.RateLimitPolicy{Limit: 2, Period: time.Minute},
+			Default: httptransport.RateLimitPolicy{Limit: 10, Period: time.Minute},
+		},
+	}
+	handler := api.Handler()
+
+	tt := []struct {
+		name          string
+		remoteAddr    string
+		password      string
+		wantStatus    int
+		wantLimit     string
+		wantRemaining string
+		wantRetry     string
+	}{
+		{
+			name:       "first failed login of the IP",
+			remoteAddr: "10.0.0.1:1234",
+			password:   "wrong",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:          "second request of the IP",
+			remoteAddr:    "10.0.0.1:1234",
+			password:      "pass",
+			wantStatus:    http.StatusOK,
+			wantLimit:     "10",
+			wantRemaining: "9",
+		},
+		{
+			name:          "the IP is rate limited before the authentication",
+			remoteAddr:    "10.0.0.1:1234",
+			password:      "wrong",
+			wantStatus:    http.StatusTooManyRequests,
+			wantLimit:     "2",
+			wantRemaining: "0",
+			wantRetry:     "30",
+		},
+		{
+			name:          "another IP has its own limit",
+			remoteAddr:    "10.0.0.2:1234",
+			password:      "pass",
+			wantStatus:    http.StatusOK,
+			wantLimit:     "10",
+			wantRemaining: "8",
+		},
+	}
+	// The requests depend on the previous ones, they cannot run in parallel.
+	for _, tc := range tt {
+		req := http.Request{
+			Method:     http.MethodGet,
+			URL:        &url.URL{Path: "/api/v1/wallet/" + walletID.String()},
+			Header:     http.Header{},
+			RemoteAddr: tc.remoteAddr,
+		}
+		req.SetBasicAuth("user_a", tc.password)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, withRequestID(&req))
+		res := rr.Result()
+
+		if got := res.StatusCode; got != tc.wantStatus {
+			t.Fatalf("%s: unexpected status: \n got:  %+v \n want: %+v", tc.name, got, tc.wantStatus)
+		}
+		for header, want := range map[string]string{
+			"RateLimit-Limit":     tc.wantLimit,
+			"RateLimit-Remaining": tc.wantRemaining,
+			"Retry-After":         tc.wantRetry,
+		} {
+			if got := res.Header.Get(header); got != want {
+				t.Fatalf("%s: unexpected %s header: \n got:  %+v \n want: %+v", tc.name, header, got, want)
+			}
+		}
+		assertOpenAPIResponse(t, &req, res)
+	}
+	ls.AssertNumberOfCalls(t, "Login", 3)
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx   = context.Background()
+		store = &httptransport.MemoryRateLimitStore{}
+		p     = httptransport.RateLimitPolicy{Limit: 2, Period: 10 * time.Second}
+		start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	tt := []struct {
+		name string
+		key  string
+		at   time.Duration
+		want httptransport.RateLimitResult
+	}{
+		{
+			name: "full bucket",
+			key:  "a",
+			want: httptransport.RateLimitResult{Allowed: true, Remaining: 1, Reset: start.Add(5 * time.Second)},
+		},
+		{
+			name: "last token",
+			key:  "a",
+			want: httptransport.RateLimitResult{Allowed: true, Remaining: 0, Reset: start.Add(10 * time.Second)},
+		},
+		{
+			name: "empty bucket",
+			key:  "a",
+			at:   time.Second,
+			want: httptransport.RateLimitResult{
+				Remaining: 0,
+				Reset:     start.Add(10 * time.Second),
+				RetryAt:   start.Add(5 * time.Second),
+			},
+		},
+		{
+			name: "other key",
+			key:  "b",
+			at:   time.Second,
+			want: httptransport.RateLimitResult{Allowed: true, Remaining: 1, Reset: start.Add(6 * time.Second)},
+		},
+		{
+			name: "refilled token",
+			key:  "a",
+			at:   5 * time.Second,
+			want: httptransport.RateLimitResult{Allowed: true, Remaining: 0, Reset: start.Add(15 * time.Second)},
+		},
+		{
+			name: "refilled bucket",
+			key:  "a",
+			at:   time.Hour,
+			want: httptransport.RateLimitResult{Allowed: true, Remaining: 1, Reset: start.Add(time.Hour + 5*time.Second)},
+		},
+	}
+	for _, tc := range tt {
+		got, err := store.Take(ctx, tc.key, p, start.Add(tc.at))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: unexpected result: \n got:  %+v \n want: %+v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+type StandingOrderRequest struct {
+	DestinationWalletID *string             `json:"destination_wallet_id"`
+	Amount              *float64            `json:"amount"`
+	Message             *string             `json:"message"`
+	Recurrence          *service.Recurrence `json:"recurrence"`
+}
+
+type StandingOrderUpdateRequest struct {
+	Amount  *float64 `json:"amount"`
+	Message *string  `json:"message"`
+}
+
+type StandingOrderManager interface {
+	CreateStandingOrder(ctx context.Context, req service.StandingOrderRequest) (service.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, user service.User, walletID, orderID uuid.UUID) (service.StandingOrder, error)
+	ListStandingOrders(ctx context.Context, user service.User, walletID uuid.UUID) ([]service.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, user service.User, walletID, orderID uuid.UUID, upd service.StandingOrderUpdate) (service.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, user service.User, walletID, orderID uuid.UUID) (service.StandingOrder, error)
+	ListStandingOrderRuns(ctx context.Context, user service.User, walletID, orderID uuid.UUID) ([]service.StandingOrderRun, error)
+}
+
+type StandingOrderList struct {
+	Results []service.StandingOrder `json:"results"`
+}
+
+type StandingOrderRunList struct {
+	Results []service.StandingOrderRun `json:"results"`
+}
+
+func CreateStandingOrderHandler(svc StandingOrderManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var x StandingOrderRequest
+		if !decodeBody(w, r, &x) {
+			return
+		}
+
+		var v validator
+		v.required("destination_wallet_id", x.DestinationWalletID != nil)
+		destinationWalletID := v.uuid("destination_wallet_id", x.DestinationWalletID)
+		v.required("amount", x.Amount != nil)
+		v.amount("amount", x.Amount)
+		v.message("message", x.Message)
+		v.required("recurrence", x.Recurrence != nil)
+		if !v.valid(w, r) {
+			return
+		}
+		originWalletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		o, err := svc.CreateStandingOrder(r.Context(), service.StandingOrderRequest{
+			Issuer:              requestUser(r),
+			OriginWalletID:      originWalletID,
+			DestinationWalletID: destinationWalletID,
+			Amount:              *x.Amount,
+			Message:             x.Message,
+			Recurrence:          *x.Recurrence,
+		})
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusCreated, o)
+	}
+}
+
+func ListStandingOrdersHandler(svc StandingOrderManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		l, err := svc.ListStandingOrders(r.Context(), requestUser(r), walletID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, StandingOrderList{Results: l})
+	}
+}
+
+func GetStandingOrderHandler(svc StandingOrderManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, orderID, ok := standingOrderParams(w, r)
+		if !ok {
+			return
+		}
+
+		o, err := svc.GetStandingOrder(r.Context(), requestUser(r), walletID, orderID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, o)
+	}
+}
+
+func UpdateStandingOrderHandler(svc StandingOrderManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var x StandingOrderUpdateRequest
+		if !decodeBody(w, r, &x) {
+			return
+		}
+
+		var v validator
+		v.amount("amount", x.Amount)
+		v.message("message", x.Message)
+		if !v.valid(w, r) {
+			return
+		}
+		walletID, orderID, ok := standingOrderParams(w, r)
+		if !ok {
+			return
+		}
+
+		o, err := svc.UpdateStandingOrder(r.Context(), requestUser(r), walletID, orderID, service.StandingOrderUpdate{
+			Amount:  x.Amount,
+			Message: x.Message,
+		})
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, o)
+	}
+}
+
+func CancelStandingOrderHandler(svc StandingOrderManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, orderID, ok := standingOrderParams(w, r)
+		if !ok {
+			return
+		}
+
+		o, err := svc.CancelStandingOrder(r.Context(), requestUser(r), walletID, orderID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, o)
+	}
+}
+
+func ListStandingOrderRunsHandler(svc StandingOrderManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, orderID, ok := standingOrderParams(w, r)
+		if !ok {
+			return
+		}
+
+		l, err := svc.ListStandingOrderRuns(r.Context(), requestUser(r), walletID, orderID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, StandingOrderRunList{Results: l})
+	}
+}
+
+// standingOrderParams parses the wallet and standing
+// order IDs, writing a not found error if invalid.
+func standingOrderParams(w http.ResponseWriter, r *http.Request) (walletID, orderID uuid.UUID, ok bool) {
+	walletID, err := uuid.Parse(urlParam(r, "walletID"))
+	if err != nil {
+		writeError(w, r, notFoundErr, nil)
+		return walletID, orderID, false
+	}
+	orderID, err = uuid.Parse(urlParam(r, "orderID"))
+	if err != nil {
+		writeError(w, r, notFoundErr, nil)
+		return walletID, orderID, false
+	}
+
+	return walletID, orderID, true
+}
@@ -0,0 +1,252 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStandingOrderHandlers(t *testing.T) {
+	t.Parallel()
+	var (
+		user      = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		login     = "foo"
+		pass      = "bar"
+		auth      = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		headers   = map[string][]string{"Authorization": {"Basic " + auth}}
+		walletID  = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		orderID   = uuid.MustParse("0b5e0f4c-3b4b-44e1-a0a5-8b3f1f3f86a1")
+		start     = time.Date(2030, 1, 31, 10, 0, 0, 0, time.UTC)
+		message   = "rent"
+		amount    = 500.0
+		basePath  = "/api/v1/wallet/" + walletID.String() + "/standing-orders"
+		orderPath = basePath + "/" + orderID.String()
+		order     = service.StandingOrder{
+			ID:                  orderID,
+			IssuerID:            user.ID,
+			OriginWalletID:      walletID,
+			DestinationWalletID: uuid.MustParse("ba2428bd-88bb-44aa-9428-688d41817dc5"),
+			Amount:              amount,
+			Message:             &message,
+			Recurrence: service.Recurrence{
+				Frequency: service.Monthly,
+				StartDate: start,
+			},
+			Status:    service.StandingOrderActive,
+			NextRunAt: &start,
+			CreatedAt: time.Now(),
+		}
+		run = service.StandingOrderRun{
+			ID:              uuid.MustParse("8d2f6b1e-4a55-4c0c-8f67-7f0f2d0e5c33"),
+			StandingOrderID: orderID,
+			ScheduledAt:     start,
+			Date:            start,
+			Status:          service.TransferFailed,
+			FailureReason:   &message,
+		}
+	)
+	orderJSON, _ := json.Marshal(&order)
+	listJSON, _ := json.Marshal(&httptransport.StandingOrderList{Results: []service.StandingOrder{order}})
+	runsJSON, _ := json.Marshal(&httptransport.StandingOrderRunList{Results: []service.StandingOrderRun{run}})
+
+	buildReq := func(method string, path string, body string) http.Request {
+		r, _ := http.NewRequest(method, "", bytes.NewBuffer([]byte(body)))
+		r.Header = headers
+		r.URL = &url.URL{Path: path}
+		return *r
+	}
+
+	tt := []struct {
+		name       string
+		req        http.Request
+		expect     func(sm *mocks.StandingOrderManager, ls *mocks.LoginService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: basePath},
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "create missing recurrence",
+			req:  buildReq(http.MethodPost, basePath, `{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 500}`),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"recurrence","code":"required","message":"missing recurrence"}]}`,
+		},
+		{
+			name: "create invalid recurrence",
+			req: buildReq(http.MethodPost, basePath, `{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 500,
+				"recurrence": {"frequency": "yearly", "start_date": "2030-01-31T10:00:00Z"}}`),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("CreateStandingOrder", mock.Anything, mock.Anything).Return(service.StandingOrder{}, service.ErrInvalidRecurrence)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_recurrence","title":"Invalid recurrence","status":422,"detail":"invalid recurrence","instance":"test-request","code":"invalid_recurrence"}`,
+		},
+		{
+			name: "create ok",
+			req: buildReq(http.MethodPost, basePath, `{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 500,
+				"message": "rent", "recurrence": {"frequency": "monthly", "start_date": "2030-01-31T10:00:00Z"}}`),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("CreateStandingOrder", mock.Anything, service.StandingOrderRequest{
+					Issuer:              user,
+					OriginWalletID:      walletID,
+					DestinationWalletID: order.DestinationWalletID,
+					Amount:              amount,
+					Message:             &message,
+					Recurrence:          order.Recurrence,
+				}).Return(order, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   string(orderJSON),
+		},
+		{
+			name: "list ok",
+			req:  buildReq(http.MethodGet, basePath, ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("ListStandingOrders", mock.Anything, user, walletID).Return([]service.StandingOrder{order}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(listJSON),
+		},
+		{
+			name: "get invalid order id",
+			req:  buildReq(http.MethodGet, basePath+"/foo", ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
+		},
+		{
+			name: "get not found",
+			req:  buildReq(http.MethodGet, orderPath, ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("GetStandingOrder", mock.Anything, user, walletID, orderID).Return(service.StandingOrder{}, service.ErrStandingOrderNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:standing_order_not_found","title":"Standing order not found","status":404,"detail":"standing order not found","instance":"test-request","code":"standing_order_not_found"}`,
+		},
+		{
+			name: "get access denied",
+			req:  buildReq(http.MethodGet, orderPath, ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("GetStandingOrder", mock.Anything, user, walletID, orderID).Return(service.StandingOrder{}, service.ErrWalletAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
+		},
+		{
+			name: "get ok",
+			req:  buildReq(http.MethodGet, orderPath, ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("GetStandingOrder", mock.Anything, user, walletID, orderID).Return(order, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(orderJSON),
+		},
+		{
+			name: "update not active",
+			req:  buildReq(http.MethodPatch, orderPath, `{"amount": 500}`),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("UpdateStandingOrder", mock.Anything, user, walletID, orderID, service.StandingOrderUpdate{Amount: &amount}).
+					Return(service.StandingOrder{}, service.ErrStandingOrderNotActive)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"urn:paybile:problem:standing_order_not_active","title":"Standing order not active","status":409,"detail":"standing order is not active","instance":"test-request","code":"standing_order_not_active"}`,
+		},
+		{
+			name: "update ok",
+			req:  buildReq(http.MethodPatch, orderPath, `{"amount": 500}`),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("UpdateStandingOrder", mock.Anything, user, walletID, orderID, service.StandingOrderUpdate{Amount: &amount}).
+					Return(order, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(orderJSON),
+		},
+		{
+			name: "cancel error",
+			req:  buildReq(http.MethodDelete, orderPath, ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("CancelStandingOrder", mock.Anything, user, walletID, orderID).Return(service.StandingOrder{}, fmt.Errorf("dummy"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"type":"urn:paybile:problem:internal_error","title":"Internal server error","status":500,"detail":"internal server error","instance":"test-request","code":"internal_error"}`,
+		},
+		{
+			name: "cancel ok",
+			req:  buildReq(http.MethodDelete, orderPath, ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("CancelStandingOrder", mock.Anything, user, walletID, orderID).Return(order, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(orderJSON),
+		},
+		{
+			name: "runs ok",
+			req:  buildReq(http.MethodGet, orderPath+"/runs", ""),
+			expect: func(sm *mocks.StandingOrderManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sm.On("ListStandingOrderRuns", mock.Anything, user, walletID, orderID).Return([]service.StandingOrderRun{run}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(runsJSON),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sm := &mocks.StandingOrderManager{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(sm, ls)
+			}
+
+			api := httptransport.ApiService{
+				StandingOrders: sm,
+				LoginService:   ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+const (
+	jsonContentType = "application/json"
+	csvContentType  = "text/csv"
+	textContentType = "text/plain"
+)
+
+type StatementGenerator interface {
+	Statement(ctx context.Context, user service.User, walletID uuid.UUID, month time.Time) (service.Statement, error)
+}
+
+// statementEncoders encode the statement in each of the supported formats.
+var statementEncoders = map[string]func(s service.Statement) ([]byte, error){
+	jsonContentType: encodeStatementJSON,
+	csvContentType:  encodeStatementCSV,
+	textContentType: encodeStatementText,
+}
+
+func StatementHandler(svc StatementGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		month, err := time.Parse("2006-01", urlParam(r, "month"))
+		if err != nil {
+			writeError(w, r, invalidParameterErr.Err(errors.New("month must have the format yyyy-mm")), err)
+			return
+		}
+
+		contentType, ok := negotiateContentType(r.Header.Get("Accept"), jsonContentType, csvContentType, textContentType)
+		if !ok {
+			writeError(w, r, notAcceptableErr, nil)
+			return
+		}
+
+		s, err := svc.Statement(r.Context(), requestUser(r), walletID, month)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		b, err := statementEncoders[contentType](s)
+		if err != nil {
+			writeError(w, r, serverErr, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	}
+}
+
+// negotiateContentType returns the first of the offered content types
+// with the highest quality in the Accept header. An empty header
+// accepts anything, so the first offer is returned.
+func negotiateContentType(accept string, offers ...string) (contentType string, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	var best float64
+	for _, offer := range offers {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if !matchMediaType(mediaType, offer) {
+				continue
+			}
+			q := 1.0
+			if x, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(x, 64); err != nil {
+					continue
+				}
+			}
+			if q > best {
+				best, contentType = q, offer
+			}
+		}
+	}
+
+	return contentType, best > 0
+}
+
+func matchMediaType(mediaType, offer string) bool {
+	if mediaType == "*/*" || mediaType == offer {
+		return true
+	}
+
+	return strings.HasSuffix(mediaType, "/*") &&
+		strings.HasPrefix(offer, strings.TrimSuffix(mediaType, "*"))
+}
+
+func encodeStatementJSON(s service.Statement) ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func encodeStatementCSV(s service.Statement) ([]byte, error) {
+	var b bytes.Buffer
+	cw := csv.NewWriter(&b)
+
+	_ = cw.Write([]string{"date", "id", "type", "amount", "balance", "reference_id", "counterparty_wallet_id", "message"})
+	_ = cw.Write([]string{formatDate(s.From), "", "opening_balance", "", exactAmount(s.OpeningBalance), "", "", ""})
+	for _, l := range s.Lines {
+		_ = cw.Write([]string{
+			formatDate(l.Date),
+			l.ID.String(),
+			string(l.Type),
+			exactAmount(l.Amount),
+			exactAmount(l.Balance),
+			uuidOrEmpty(l.ReferenceID),
+			uuidOrEmpty(l.CounterpartyWalletID),
+			strOrEmpty(l.Message),
+		})
+	}
+	_ = cw.Write([]string{formatDate(s.To), "", "closing_balance", "", exactAmount(s.ClosingBalance), "", "", ""})
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, fmt.Errorf("cannot write CSV statement: %v", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+func encodeStatementText(s service.Statement) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "Statement of wallet %s\n", s.WalletID)
+	fmt.Fprintf(&b, "Period: %s - %s\n\n", formatDate(s.From), formatDate(s.To))
+	fmt.Fprintf(&b, "Opening balance: %s\n\n", formatAmount(s.OpeningBalance))
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Date\tType\tAmount\tBalance\tCounterparty\tMessage")
+	for _, l := range s.Lines {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			formatDate(l.Date),
+			l.Type,
+			formatAmount(l.Amount),
+			formatAmount(l.Balance),
+			uuidOrEmpty(l.CounterpartyWalletID),
+			strOrEmpty(l.Message),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, fmt.Errorf("cannot write text statement: %v", err)
+	}
+
+	fmt.Fprintf(&b, "\nClosing balance: %s\n", formatAmount(s.ClosingBalance))
+
+	return b.Bytes(), nil
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatAmount formats the amount with two decimals for humans.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// exactAmount formats the amount without losing decimals for machines.
+func exactAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+func uuidOrEmpty(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
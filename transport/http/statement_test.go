@@ -0,0 +1,198 @@
+package http_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatementHandler(t *testing.T) {
+	t.Parallel()
+	var (
+		user          = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID      = uuid.MustParse("2f9b76dd-f689-456e-9080-6789718018a5")
+		counterpartID = uuid.MustParse("4e1d841d-e53f-4785-ba4d-99df05f11eee")
+		referenceID   = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
+		login         = "foo"
+		pass          = "bar"
+		auth          = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		path          = "/api/v1/wallet/" + walletID.String() + "/statements/2020-09"
+		month         = time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+		message       = "dinner, drinks"
+		statement     = service.Statement{
+			WalletID:       walletID,
+			From:           month,
+			To:             month.AddDate(0, 1, 0),
+			OpeningBalance: 0,
+			ClosingBalance: 12.75,
+			Lines: []service.StatementLine{{
+				Transaction: service.Transaction{
+					ID:      uuid.MustParse("9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc"),
+					Amount:  20,
+					Balance: 20,
+					Type:    service.DepositType,
+					Date:    time.Date(2020, 9, 20, 10, 0, 0, 0, time.UTC),
+				},
+			}, {
+				Transaction: service.Transaction{
+					ID:          uuid.MustParse("4bce4401-6b35-4fa1-94b9-ac5ce05d29b1"),
+					Amount:      -7.25,
+					Balance:     12.75,
+					Type:        service.TransferType,
+					ReferenceID: &referenceID,
+					Date:        time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC),
+				},
+				CounterpartyWalletID: &counterpartID,
+				Message:              &message,
+			}},
+		}
+	)
+
+	buildReq := func(path, accept string) http.Request {
+		u, _ := url.Parse(path)
+		return http.Request{
+			Method: http.MethodGet,
+			URL:    u,
+			Header: map[string][]string{
+				"Authorization": {"Basic " + auth},
+				"Accept":        {accept},
+			},
+		}
+	}
+
+	tt := []struct {
+		name            string
+		req             http.Request
+		expect          func(sg *mocks.StatementGenerator, ls *mocks.LoginService)
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path},
+			},
+			wantStatus:      http.StatusUnauthorized,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "invalid month",
+			req:  buildReq("/api/v1/wallet/"+walletID.String()+"/statements/2020-9", ""),
+			expect: func(sg *mocks.StatementGenerator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"month must have the format yyyy-mm","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name: "not acceptable",
+			req:  buildReq(path, "application/pdf"),
+			expect: func(sg *mocks.StatementGenerator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:not_acceptable","title":"Not acceptable","status":406,"detail":"not acceptable, supported formats: application/json, text/csv, text/plain","instance":"test-request","code":"not_acceptable"}`,
+		},
+		{
+			name: "wallet access denied",
+			req:  buildReq(path, ""),
+			expect: func(sg *mocks.StatementGenerator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sg.On("Statement", mock.Anything, user, walletID, month).Return(service.Statement{}, service.ErrWalletAccessDenied)
+			},
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
+		},
+		{
+			name: "json by default",
+			req:  buildReq(path, "*/*"),
+			expect: func(sg *mocks.StatementGenerator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sg.On("Statement", mock.Anything, user, walletID, month).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody: `{"wallet_id":"2f9b76dd-f689-456e-9080-6789718018a5","from":"2020-09-01T00:00:00Z","to":"2020-10-01T00:00:00Z",` +
+				`"opening_balance":0,"closing_balance":12.75,"transactions":[` +
+				`{"id":"9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc","amount":20,"balance":20,"transaction_type":"deposit","reference_id":null,"date":"2020-09-20T10:00:00Z","counterparty_wallet_id":null,"message":null},` +
+				`{"id":"4bce4401-6b35-4fa1-94b9-ac5ce05d29b1","amount":-7.25,"balance":12.75,"transaction_type":"transfer","reference_id":"97ca2b73-7988-4247-82d4-f6ba723a99c9","date":"2020-09-20T11:10:00Z","counterparty_wallet_id":"4e1d841d-e53f-4785-ba4d-99df05f11eee","message":"dinner, drinks"}]}`,
+		},
+		{
+			name: "csv",
+			req:  buildReq(path, "text/csv"),
+			expect: func(sg *mocks.StatementGenerator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sg.On("Statement", mock.Anything, user, walletID, month).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody: "date,id,type,amount,balance,reference_id,counterparty_wallet_id,message\n" +
+				"2020-09-01T00:00:00Z,,opening_balance,,0,,,\n" +
+				"2020-09-20T10:00:00Z,9177ad78-e5d5-4d3c-be8c-e0e1f44bbdcc,deposit,20,20,,,\n" +
+				"2020-09-20T11:10:00Z,4bce4401-6b35-4fa1-94b9-ac5ce05d29b1,transfer,-7.25,12.75,97ca2b73-7988-4247-82d4-f6ba723a99c9,4e1d841d-e53f-4785-ba4d-99df05f11eee,\"dinner, drinks\"\n" +
+				"2020-10-01T00:00:00Z,,closing_balance,,12.75,,,\n",
+		},
+		{
+			name: "plain text preferred by quality",
+			req:  buildReq(path, "application/json;q=0.5, text/plain"),
+			expect: func(sg *mocks.StatementGenerator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				sg.On("Statement", mock.Anything, user, walletID, month).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody: "Statement of wallet 2f9b76dd-f689-456e-9080-6789718018a5\n" +
+				"Period: 2020-09-01T00:00:00Z - 2020-10-01T00:00:00Z\n\n" +
+				"Opening balance: 0.00\n\n" +
+				"Date                  Type      Amount  Balance  Counterparty                          Message\n" +
+				"2020-09-20T10:00:00Z  deposit   20.00   20.00                                          \n" +
+				"2020-09-20T11:10:00Z  transfer  -7.25   12.75    4e1d841d-e53f-4785-ba4d-99df05f11eee  dinner, drinks\n" +
+				"\nClosing balance: 12.75\n",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sg := &mocks.StatementGenerator{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(sg, ls)
+			}
+
+			api := httptransport.ApiService{
+				Statements:   sg,
+				LoginService: ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := res.Header.Get("Content-Type"); got != tc.wantContentType {
+				t.Fatalf("unexpected content type: \n got:  %+v \n want: %+v", got, tc.wantContentType)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %q \n want: %q", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}
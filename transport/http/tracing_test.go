@@ -0,0 +1,63 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	req := withRequestID(&http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67"},
+	})
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+
+	api := httptransport.ApiService{}
+	rr := httptest.NewRecorder()
+	api.Handler().ServeHTTP(rr, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("unexpected number of spans: %d", len(spans))
+	}
+	span := spans[0]
+	if got, want := span.Name, "GET /api/v1/wallet/{walletID}"; got != want {
+		t.Fatalf("unexpected span name: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := span.SpanContext.TraceID().String(), traceID; got != want {
+		t.Fatalf("unexpected trace ID: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := span.Parent.SpanID().String(), parentSpanID; got != want {
+		t.Fatalf("unexpected parent span: \n got:  %+v \n want: %+v", got, want)
+	}
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if got, want := attrs["http.request_id"].AsString(), testRequestID; got != want {
+		t.Fatalf("unexpected request ID: \n got:  %+v \n want: %+v", got, want)
+	}
+	if got, want := attrs["http.status_code"].AsInt64(), int64(http.StatusUnauthorized); got != want {
+		t.Fatalf("unexpected status code: \n got:  %+v \n want: %+v", got, want)
+	}
+}
@@ -13,12 +13,13 @@ type TransactionLister interface {
 	ListTransactions(ctx context.Context, user service.User, walletID uuid.UUID, opt service.ListOptions) (l service.TransactionList, err error)
 }
 
-func TransactionListHandler(svc TransactionLister) http.HandlerFunc {
+func TransactionListHandler(svc TransactionLister, limits service.PageLimits) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
 			order   = r.URL.Query().Get("order")
 			perPage = r.URL.Query().Get("per_page")
 			fromID  = r.URL.Query().Get("from_id")
+			expand  = r.URL.Query().Get("expand")
 			user    = requestUser(r)
 		)
 
@@ -30,21 +31,26 @@ func TransactionListHandler(svc TransactionLister) http.HandlerFunc {
 
 		opts := service.ListOptions{
 			Order:   service.ParseListOrderOrDefault(order),
-			PerPage: service.ParsePerPageOrDefault(perPage),
+			PerPage: limits.ParsePerPageOrDefault(perPage),
 		}
 
 		if fromID != "" {
 			if x, err := uuid.Parse(fromID); err != nil {
-				writeError(w, r, unprocessableEntityErr.Err(errors.New("from_id is not a valid UUID")), err)
+				writeError(w, r, invalidParameterErr.Err(errors.New("from_id is not a valid UUID")), err)
 				return
 			} else {
 				opts.FromID = &x
 			}
 		}
 
+		if opts.ExpandTransfer, err = service.ParseExpandTransfer(expand); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
 		l, err := svc.ListTransactions(r.Context(), user, walletID, opts)
 		if err != nil {
-			writeError(w, r, serverErr, err)
+			writeServiceError(w, r, err)
 			return
 		}
 
@@ -32,15 +32,36 @@ func TestTransactionListHandler(t *testing.T) {
 				ID:      uuid.MustParse("53237090-0d16-4447-93df-394df1e4c7c8"),
 				Amount:  10,
 				Balance: 20,
+				Type:    service.DepositType,
 				Date:    time.Now(),
 			}},
 		}
 	)
 	listBody, _ := json.Marshal(&list)
+	var (
+		referenceID  = uuid.MustParse("97ca2b73-7988-4247-82d4-f6ba723a99c9")
+		message      = "dinner"
+		expandedList = service.TransactionList{
+			Results: []service.Transaction{{
+				ID:          uuid.MustParse("53237090-0d16-4447-93df-394df1e4c7c8"),
+				Amount:      -10,
+				Balance:     20,
+				Type:        service.TransferType,
+				ReferenceID: &referenceID,
+				Date:        time.Date(2020, 9, 20, 11, 10, 0, 0, time.UTC),
+				Transfer: &service.TransactionTransfer{
+					CounterpartyWalletID: uuid.MustParse("4e1d841d-e53f-4785-ba4d-99df05f11eee"),
+					IssuerID:             user.ID,
+					Message:              &message,
+				},
+			}},
+		}
+	)
 
 	tt := []struct {
 		name       string
 		req        http.Request
+		limits     service.PageLimits
 		expect     func(tl *mocks.TransactionLister, ls *mocks.LoginService)
 		wantStatus int
 		wantBody   string
@@ -52,7 +73,7 @@ func TestTransactionListHandler(t *testing.T) {
 				URL:    &url.URL{Path: "/api/v1/wallet/foo/transactions"},
 			},
 			wantStatus: http.StatusUnauthorized,
-			wantBody:   `{"status_code":401,"key":"UnauthorizedErr","error":"unauthorized"}`,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
 		},
 		{
 			name: "invalid origin wallet id",
@@ -65,7 +86,7 @@ func TestTransactionListHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusNotFound,
-			wantBody:   `{"status_code":404,"key":"NotFoundErr","error":"not found"}`,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
 		},
 		{
 			name: "invalid from id",
@@ -78,7 +99,20 @@ func TestTransactionListHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusUnprocessableEntity,
-			wantBody:   `{"status_code":422,"key":"UnprocessableEntityErr","error":"from_id is not a valid UUID"}`,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"from_id is not a valid UUID","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name: "invalid expand",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: "/api/v1/wallet/f8ee8d07-a56a-49f1-87ee-b4377c8142bb/transactions", RawQuery: "expand=issuer"},
+				Header: headers,
+			},
+			expect: func(tl *mocks.TransactionLister, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_expand","title":"Invalid expand option","status":422,"detail":"invalid expand option: \"issuer\" is not a valid expand option. Valid values: transfer","instance":"test-request","code":"invalid_expand"}`,
 		},
 		{
 			name: "list error",
@@ -92,7 +126,7 @@ func TestTransactionListHandler(t *testing.T) {
 				tl.On("ListTransactions", mock.Anything, user, walletID, mock.Anything).Return(list, fmt.Errorf("dummy"))
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   `{"status_code":500,"key":"InternalServerErr","error":"internal server error"}`,
+			wantBody:   `{"type":"urn:paybile:problem:internal_error","title":"Internal server error","status":500,"detail":"internal server error","instance":"test-request","code":"internal_error"}`,
 		},
 		{
 			name: "list ok",
@@ -115,6 +149,48 @@ func TestTransactionListHandler(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantBody:   string(listBody),
 		},
+		{
+			name: "list expanded with transfer",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL: &url.URL{
+					Path:     "/api/v1/wallet/f8ee8d07-a56a-49f1-87ee-b4377c8142bb/transactions",
+					RawQuery: "expand=transfer",
+				},
+				Header: headers,
+			},
+			expect: func(tl *mocks.TransactionLister, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				tl.On("ListTransactions", mock.Anything, user, walletID, service.ListOptions{
+					PerPage:        service.DefaultPerPage,
+					ExpandTransfer: true,
+				}).Return(expandedList, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"results":[{"id":"53237090-0d16-4447-93df-394df1e4c7c8","amount":-10,"balance":20,"transaction_type":"transfer",` +
+				`"reference_id":"97ca2b73-7988-4247-82d4-f6ba723a99c9","date":"2020-09-20T11:10:00Z","transfer":{` +
+				`"counterparty_wallet_id":"4e1d841d-e53f-4785-ba4d-99df05f11eee","issuer_id":"f4c34307-e7af-4add-a39b-b65d5627830c","message":"dinner"}}],"next_id":null}`,
+		},
+		{
+			name: "configured page limits",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL: &url.URL{
+					Path:     "/api/v1/wallet/f8ee8d07-a56a-49f1-87ee-b4377c8142bb/transactions",
+					RawQuery: "per_page=15",
+				},
+				Header: headers,
+			},
+			limits: service.PageLimits{Default: 5, Max: 10},
+			expect: func(tl *mocks.TransactionLister, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				tl.On("ListTransactions", mock.Anything, user, walletID, service.ListOptions{
+					PerPage: 5,
+				}).Return(list, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(listBody),
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -127,9 +203,10 @@ func TestTransactionListHandler(t *testing.T) {
 			api := httptransport.ApiService{
 				TransactionLister: tl,
 				LoginService:      ls,
+				PageLimits:        tc.limits,
 			}
 			rr := httptest.NewRecorder()
-			api.Handler().ServeHTTP(rr, &tc.req)
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
 			res := rr.Result()
 
 			if got := res.StatusCode; got != tc.wantStatus {
@@ -138,6 +215,7 @@ func TestTransactionListHandler(t *testing.T) {
 			if got := rr.Body.String(); got != tc.wantBody {
 				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
 			}
+			assertOpenAPIResponse(t, &tc.req, res)
 		})
 	}
 }
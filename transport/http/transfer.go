@@ -2,10 +2,8 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hmoragrega/paybile/service"
@@ -15,36 +13,33 @@ type TransferRequest struct {
 	DestinationWalletID *string  `json:"destination_wallet_id"`
 	Amount              *float64 `json:"amount"`
 	Message             *string  `json:"message"`
+	// ExecuteAt optional RFC3339 date to schedule the transfer.
+	ExecuteAt *time.Time `json:"execute_at"`
 }
 
 type TransferCreator interface {
 	TransferFunds(ctx context.Context, req service.TransferRequest) (t service.Transfer, err error)
 }
 
+type TransferCanceller interface {
+	CancelTransfer(ctx context.Context, user service.User, walletID, transferID uuid.UUID) (t service.Transfer, err error)
+}
+
 func CreateTransferHandler(svc TransferCreator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			writeError(w, r, readRequestBodyErr, err)
-			return
-		}
-
 		var x TransferRequest
-		if err = json.Unmarshal(b, &x); err != nil {
-			writeError(w, r, unmarshalBodyErr, err)
+		if !decodeBody(w, r, &x) {
 			return
 		}
 
-		if x.DestinationWalletID == nil {
-			writeError(w, r, badRequestErr.Err(errors.New("missing destination_wallet_id")), err)
-			return
-		}
-		if x.Amount == nil {
-			writeError(w, r, badRequestErr.Err(errors.New("missing amount")), err)
-			return
-		}
-		if x.Message == nil {
-			writeError(w, r, badRequestErr.Err(errors.New("missing message")), err)
+		var v validator
+		v.required("destination_wallet_id", x.DestinationWalletID != nil)
+		destinationWalletID := v.uuid("destination_wallet_id", x.DestinationWalletID)
+		v.required("amount", x.Amount != nil)
+		v.amount("amount", x.Amount)
+		v.required("message", x.Message != nil)
+		v.message("message", x.Message)
+		if !v.valid(w, r) {
 			return
 		}
 		originWalletID, err := uuid.Parse(urlParam(r, "walletID"))
@@ -52,11 +47,6 @@ func CreateTransferHandler(svc TransferCreator) http.HandlerFunc {
 			writeError(w, r, notFoundErr, nil)
 			return
 		}
-		destinationWalletID, err := uuid.Parse(*x.DestinationWalletID)
-		if err != nil {
-			writeError(w, r, badRequestErr.Err(errors.New("destination_id is not a valid user ID")), err)
-			return
-		}
 
 		req := service.TransferRequest{
 			Issuer:              requestUser(r),
@@ -64,26 +54,39 @@ func CreateTransferHandler(svc TransferCreator) http.HandlerFunc {
 			DestinationWalletID: destinationWalletID,
 			Amount:              *x.Amount,
 			Message:             x.Message,
+			ExecuteAt:           x.ExecuteAt,
 		}
 
 		ctx := r.Context()
 		t, err := svc.TransferFunds(ctx, req)
 		if err != nil {
-			switch wrappedErr := wrappedErrOrParent(err); wrappedErr {
-			case service.ErrInsufficientFunds,
-				service.ErrInvalidTransactionAmount,
-				service.ErrSameTransferWallets:
-				writeError(w, r, unprocessableEntityErr.Err(wrappedErr), err)
-			case service.ErrWalletNotFound:
-				writeError(w, r, notFoundErr.Err(wrappedErr), err)
-			case service.ErrWalletAccessDenied:
-				writeError(w, r, forbiddenErr.Err(wrappedErr), err)
-			default:
-				writeError(w, r, serverErr, err)
-			}
+			writeServiceError(w, r, err)
 			return
 		}
 
 		writeResponse(w, http.StatusCreated, t)
 	}
 }
+
+func CancelTransferHandler(svc TransferCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+		transferID, err := uuid.Parse(urlParam(r, "transferID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		t, err := svc.CancelTransfer(r.Context(), requestUser(r), walletID, transferID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, t)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,10 +36,24 @@ func TestCreateTransferHandler(t *testing.T) {
 			Amount:              12.34,
 			Message:             &message,
 			Date:                time.Now(),
+			Status:              service.TransferCompleted,
+		}
+		executeAt = time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+		scheduled = service.Transfer{
+			ID:                  transfer.ID,
+			IssuerID:            user.ID,
+			OriginWalletID:      transfer.OriginWalletID,
+			DestinationWalletID: transfer.DestinationWalletID,
+			Amount:              12.34,
+			Message:             &message,
+			Date:                time.Now(),
+			Status:              service.TransferScheduled,
+			ExecuteAt:           &executeAt,
 		}
 	)
 
 	transferJSON, _ := json.Marshal(&transfer)
+	scheduledJSON, _ := json.Marshal(&scheduled)
 	buildReq := func(method string, url *url.URL, headers map[string][]string, body string) http.Request {
 		r, _ := http.NewRequest(method, "", bytes.NewBuffer([]byte(body)))
 		r.Header = headers
@@ -60,7 +75,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				URL:    &url.URL{Path: "/api/v1/wallet/foo/transfer"},
 			},
 			wantStatus: http.StatusUnauthorized,
-			wantBody:   `{"status_code":401,"key":"UnauthorizedErr","error":"unauthorized"}`,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
 		},
 		{
 			name: "unmarshal error",
@@ -74,7 +89,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"status_code":400,"key":"UnmarshalErr","error":"cannot unmarshal request body"}`,
+			wantBody:   `{"type":"urn:paybile:problem:malformed_body","title":"Malformed request body","status":400,"detail":"cannot unmarshal request body","instance":"test-request","code":"malformed_body"}`,
 		},
 		{
 			name: "missing destination wallet id",
@@ -88,7 +103,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"status_code":400,"key":"BadRequestErr","error":"missing destination_wallet_id"}`,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"destination_wallet_id","code":"required","message":"missing destination_wallet_id"}]}`,
 		},
 		{
 			name: "missing amount",
@@ -102,7 +117,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"status_code":400,"key":"BadRequestErr","error":"missing amount"}`,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"amount","code":"required","message":"missing amount"}]}`,
 		},
 		{
 			name: "missing message",
@@ -116,7 +131,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"status_code":400,"key":"BadRequestErr","error":"missing message"}`,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"message","code":"required","message":"missing message"}]}`,
 		},
 		{
 			name: "invalid origin wallet id",
@@ -130,7 +145,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusNotFound,
-			wantBody:   `{"status_code":404,"key":"NotFoundErr","error":"not found"}`,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
 		},
 		{
 			name: "invalid destination wallet id",
@@ -144,7 +159,91 @@ func TestCreateTransferHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"status_code":400,"key":"BadRequestErr","error":"destination_id is not a valid user ID"}`,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"destination_wallet_id","code":"invalid_format","message":"destination_wallet_id is not a valid ID"}]}`,
+		},
+		{
+			name: "all the invalid fields",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "foo", "amount": 1.23456, "message": "`+strings.Repeat("a", 256)+`"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"destination_wallet_id","code":"invalid_format","message":"destination_wallet_id is not a valid ID"},{"field":"amount","code":"too_precise","message":"amount must have at most 4 decimals"},{"field":"message","code":"too_long","message":"message must have at most 255 characters"}]}`,
+		},
+		{
+			name: "amount out of range",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "9c541caf-7185-456b-b418-5fa77cfbb687", "amount": 1e15, "message": "foo"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"amount","code":"out_of_range","message":"amount is out of range"}]}`,
+		},
+		{
+			name: "unknown field",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "9c541caf-7185-456b-b418-5fa77cfbb687", "amount": 3, "message": "foo", "foo": "bar"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:malformed_body","title":"Malformed request body","status":400,"detail":"cannot unmarshal request body","instance":"test-request","code":"malformed_body","details":[{"field":"foo","code":"unknown_field","message":"foo is not a known field"}]}`,
+		},
+		{
+			name: "invalid field type",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "9c541caf-7185-456b-b418-5fa77cfbb687", "amount": "3", "message": "foo"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:malformed_body","title":"Malformed request body","status":400,"detail":"cannot unmarshal request body","instance":"test-request","code":"malformed_body","details":[{"field":"amount","code":"invalid_type","message":"amount must be of type float64"}]}`,
+		},
+		{
+			name: "trailing data",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "9c541caf-7185-456b-b418-5fa77cfbb687", "amount": 3, "message": "foo"} {}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:malformed_body","title":"Malformed request body","status":400,"detail":"cannot unmarshal request body","instance":"test-request","code":"malformed_body"}`,
+		},
+		{
+			name: "body too large",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"message": "`+strings.Repeat("a", 1<<20)+`"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"type":"urn:paybile:problem:body_too_large","title":"Request body too large","status":413,"detail":"request body too large","instance":"test-request","code":"body_too_large"}`,
 		},
 		{
 			name: "insufficient funds",
@@ -159,7 +258,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, service.ErrInsufficientFunds)
 			},
 			wantStatus: http.StatusUnprocessableEntity,
-			wantBody:   `{"status_code":422,"key":"UnprocessableEntityErr","error":"origin has insufficient funds"}`,
+			wantBody:   `{"type":"urn:paybile:problem:insufficient_funds","title":"Insufficient funds","status":422,"detail":"origin has insufficient funds","instance":"test-request","code":"insufficient_funds"}`,
 		},
 		{
 			name: "invalid transaction amount",
@@ -174,7 +273,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, service.ErrInvalidTransactionAmount)
 			},
 			wantStatus: http.StatusUnprocessableEntity,
-			wantBody:   `{"status_code":422,"key":"UnprocessableEntityErr","error":"invalid transaction amount"}`,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_amount","title":"Invalid amount","status":422,"detail":"invalid transaction amount","instance":"test-request","code":"invalid_amount"}`,
 		},
 		{
 			name: "same wallet",
@@ -189,7 +288,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, service.ErrSameTransferWallets)
 			},
 			wantStatus: http.StatusUnprocessableEntity,
-			wantBody:   `{"status_code":422,"key":"UnprocessableEntityErr","error":"same origin and destination"}`,
+			wantBody:   `{"type":"urn:paybile:problem:same_wallets","title":"Same origin and destination wallets","status":422,"detail":"same origin and destination","instance":"test-request","code":"same_wallets"}`,
 		},
 		{
 			name: "wallet not found",
@@ -204,7 +303,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, service.ErrWalletNotFound)
 			},
 			wantStatus: http.StatusNotFound,
-			wantBody:   `{"status_code":404,"key":"NotFoundErr","error":"wallet not found"}`,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_not_found","title":"Wallet not found","status":404,"detail":"wallet not found","instance":"test-request","code":"wallet_not_found"}`,
 		},
 		{
 			name: "wallet access denied",
@@ -219,7 +318,7 @@ func TestCreateTransferHandler(t *testing.T) {
 				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, service.ErrWalletAccessDenied)
 			},
 			wantStatus: http.StatusForbidden,
-			wantBody:   `{"status_code":403,"key":"ForbiddenErr","error":"wallet access denied"}`,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
 		},
 		{
 			name: "internal server error",
@@ -234,7 +333,39 @@ func TestCreateTransferHandler(t *testing.T) {
 				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, fmt.Errorf("dummy"))
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   `{"status_code":500,"key":"InternalServerErr","error":"internal server error"}`,
+			wantBody:   `{"type":"urn:paybile:problem:internal_error","title":"Internal server error","status":500,"detail":"internal server error","instance":"test-request","code":"internal_error"}`,
+		},
+		{
+			name: "invalid execution date",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "9c541caf-7185-456b-b418-5fa77cfbb687", "amount": 12.34, "message": "foo", "execute_at": "2020-01-01T10:00:00Z"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				cc.On("TransferFunds", mock.Anything, mock.Anything).Return(service.Transfer{}, service.ErrInvalidExecutionDate)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_execution_date","title":"Invalid execution date","status":422,"detail":"execution date must be in the future","instance":"test-request","code":"invalid_execution_date"}`,
+		},
+		{
+			name: "transfer scheduled",
+			req: buildReq(
+				http.MethodPost,
+				&url.URL{Path: "/api/v1/wallet/b272dc21-e006-4a41-a120-2b8f26b61a67/transfer"},
+				headers,
+				`{"destination_wallet_id": "ba2428bd-88bb-44aa-9428-688d41817dc5", "amount": 12.34, "message": "transfer", "execute_at": "2030-01-01T10:00:00Z"}`,
+			),
+			expect: func(cc *mocks.TransferCreator, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				cc.On("TransferFunds", mock.Anything, mock.MatchedBy(func(req service.TransferRequest) bool {
+					return req.ExecuteAt != nil && req.ExecuteAt.Equal(executeAt)
+				})).Return(scheduled, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   string(scheduledJSON),
 		},
 		{
 			name: "transfer ok",
@@ -265,7 +396,159 @@ func TestCreateTransferHandler(t *testing.T) {
 				LoginService:    ls,
 			}
 			rr := httptest.NewRecorder()
-			api.Handler().ServeHTTP(rr, &tc.req)
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}
+
+func TestCancelTransferHandler(t *testing.T) {
+	t.Parallel()
+	var (
+		user       = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		login      = "foo"
+		pass       = "bar"
+		auth       = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		headers    = map[string][]string{"Authorization": {"Basic " + auth}}
+		walletID   = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		transferID = uuid.MustParse("bc349396-fe96-42ae-ad10-d8e54d148c49")
+		executeAt  = time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+		path       = "/api/v1/wallet/" + walletID.String() + "/transfer/" + transferID.String() + "/cancel"
+		transfer   = service.Transfer{
+			ID:                  transferID,
+			IssuerID:            user.ID,
+			OriginWalletID:      walletID,
+			DestinationWalletID: uuid.MustParse("ba2428bd-88bb-44aa-9428-688d41817dc5"),
+			Amount:              12.34,
+			Date:                time.Now(),
+			Status:              service.TransferCancelled,
+			ExecuteAt:           &executeAt,
+		}
+	)
+	transferJSON, _ := json.Marshal(&transfer)
+
+	tt := []struct {
+		name       string
+		req        http.Request
+		expect     func(c *mocks.TransferCanceller, ls *mocks.LoginService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: path},
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "invalid transfer id",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: "/api/v1/wallet/" + walletID.String() + "/transfer/foo/cancel"},
+				Header: headers,
+			},
+			expect: func(c *mocks.TransferCanceller, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
+		},
+		{
+			name: "transfer not found",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: path},
+				Header: headers,
+			},
+			expect: func(c *mocks.TransferCanceller, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				c.On("CancelTransfer", mock.Anything, user, walletID, transferID).Return(service.Transfer{}, service.ErrTransferNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:transfer_not_found","title":"Transfer not found","status":404,"detail":"transfer not found","instance":"test-request","code":"transfer_not_found"}`,
+		},
+		{
+			name: "wallet access denied",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: path},
+				Header: headers,
+			},
+			expect: func(c *mocks.TransferCanceller, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				c.On("CancelTransfer", mock.Anything, user, walletID, transferID).Return(service.Transfer{}, service.ErrWalletAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
+		},
+		{
+			name: "transfer not cancellable",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: path},
+				Header: headers,
+			},
+			expect: func(c *mocks.TransferCanceller, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				c.On("CancelTransfer", mock.Anything, user, walletID, transferID).Return(service.Transfer{}, service.ErrTransferNotCancellable)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"urn:paybile:problem:transfer_not_cancellable","title":"Transfer not cancellable","status":409,"detail":"only scheduled transfers can be cancelled","instance":"test-request","code":"transfer_not_cancellable"}`,
+		},
+		{
+			name: "internal server error",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: path},
+				Header: headers,
+			},
+			expect: func(c *mocks.TransferCanceller, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				c.On("CancelTransfer", mock.Anything, user, walletID, transferID).Return(service.Transfer{}, fmt.Errorf("dummy"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"type":"urn:paybile:problem:internal_error","title":"Internal server error","status":500,"detail":"internal server error","instance":"test-request","code":"internal_error"}`,
+		},
+		{
+			name: "cancel ok",
+			req: http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Path: path},
+				Header: headers,
+			},
+			expect: func(c *mocks.TransferCanceller, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				c.On("CancelTransfer", mock.Anything, user, walletID, transferID).Return(transfer, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(transferJSON),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &mocks.TransferCanceller{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(c, ls)
+			}
+
+			api := httptransport.ApiService{
+				TransferCanceller: c,
+				LoginService:      ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
 			res := rr.Result()
 
 			if got := res.StatusCode; got != tc.wantStatus {
@@ -274,6 +557,7 @@ func TestCreateTransferHandler(t *testing.T) {
 			if got := rr.Body.String(); got != tc.wantBody {
 				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
 			}
+			assertOpenAPIResponse(t, &tc.req, res)
 		})
 	}
 }
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRequestBodySize maximum size in bytes of a request body.
+	maxRequestBodySize = 1 << 20
+	// maxMessageLength maximum number of characters of a transfer message.
+	maxMessageLength = 255
+	// amountDecimals maximum number of decimals of an amount.
+	amountDecimals = 4
+	// maxAmount amounts are stored as DECIMAL(19,4).
+	maxAmount = 1e15
+)
+
+// Validation error codes.
+const (
+	requiredCode      = "required"
+	invalidFormatCode = "invalid_format"
+	invalidTypeCode   = "invalid_type"
+	unknownFieldCode  = "unknown_field"
+	tooLongCode       = "too_long"
+	tooPreciseCode    = "too_precise"
+	outOfRangeCode    = "out_of_range"
+)
+
+var (
+	requestEntityTooLargeErr = apiError{
+		Status: http.StatusRequestEntityTooLarge,
+		Code:   "body_too_large",
+		Title:  "Request body too large",
+		error:  errors.New("request body too large"),
+	}
+	validationErr = apiError{
+		Status: http.StatusBadRequest,
+		Code:   "invalid_body",
+		Title:  "Invalid request body",
+		error:  errors.New("invalid request body"),
+	}
+)
+
+// fieldError describes a problem with a field of the request body.
+type fieldError struct {
+	// Field JSON path of the field, like "transfers[0].amount".
+	Field string `json:"field"`
+	// Code machine readable reason of the problem.
+	Code string `json:"code"`
+	// Message describes the problem to the user.
+	Message string `json:"message"`
+}
+
+// decodeBody decodes the JSON request body into x, writing
+// the error response if the body cannot be decoded.
+//
+// The body is capped to maxRequestBodySize and unknown fields
+// are rejected.
+func decodeBody(w http.ResponseWriter, r *http.Request, x interface{}) bool {
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+	if err != nil {
+		writeError(w, r, readRequestBodyErr, err)
+		return false
+	}
+	if len(b) > maxRequestBodySize {
+		writeError(w, r, requestEntityTooLargeErr, nil)
+		return false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(x); err != nil {
+		writeError(w, r, unmarshalBodyErr.withDetails(decodeErrorDetails(err)), err)
+		return false
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		writeError(w, r, unmarshalBodyErr, errors.New("unexpected data after the request body"))
+		return false
+	}
+
+	return true
+}
+
+// decodeErrorDetails returns the field errors of a decoding error, if any.
+func decodeErrorDetails(err error) []fieldError {
+	const unknownFieldPrefix = `json: unknown field "`
+	if s := err.Error(); strings.HasPrefix(s, unknownFieldPrefix) {
+		field := strings.TrimSuffix(strings.TrimPrefix(s, unknownFieldPrefix), `"`)
+		return []fieldError{{
+			Field:   field,
+			Code:    unknownFieldCode,
+			Message: field + " is not a known field",
+		}}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []fieldError{{
+			Field:   typeErr.Field,
+			Code:    invalidTypeCode,
+			Message: typeErr.Field + " must be of type " + typeErr.Type.String(),
+		}}
+	}
+
+	return nil
+}
+
+// validator collects the problems of a request body, so
+// all of them can be reported at once.
+type validator struct {
+	details []fieldError
+}
+
+func (v *validator) fail(field, code, message string) {
+	v.details = append(v.details, fieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// required checks that the field is present.
+func (v *validator) required(field string, present bool) {
+	if !present {
+		v.fail(field, requiredCode, "missing "+field)
+	}
+}
+
+// uuid parses the ID of the field, if present.
+func (v *validator) uuid(field string, s *string) uuid.UUID {
+	if s == nil {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(*s)
+	if err != nil {
+		v.fail(field, invalidFormatCode, field+" is not a valid ID")
+	}
+	return id
+}
+
+// amount checks that the amount, if present, can be stored.
+func (v *validator) amount(field string, a *float64) {
+	if a == nil {
+		return
+	}
+	if math.IsNaN(*a) || math.Abs(*a) >= maxAmount {
+		v.fail(field, outOfRangeCode, field+" is out of range")
+		return
+	}
+	s := strconv.FormatFloat(*a, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > amountDecimals {
+		v.fail(field, tooPreciseCode, field+" must have at most "+strconv.Itoa(amountDecimals)+" decimals")
+	}
+}
+
+// message checks the length of the message, if present.
+func (v *validator) message(field string, m *string) {
+	if m != nil && utf8.RuneCountInString(*m) > maxMessageLength {
+		v.fail(field, tooLongCode, field+" must have at most "+strconv.Itoa(maxMessageLength)+" characters")
+	}
+}
+
+// valid writes the validation error response if there
+// are problems and reports whether the request is valid.
+func (v *validator) valid(w http.ResponseWriter, r *http.Request) bool {
+	if len(v.details) == 0 {
+		return true
+	}
+	writeError(w, r, validationErr.withDetails(v.details), nil)
+	return false
+}
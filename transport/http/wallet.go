@@ -2,7 +2,9 @@ package http
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hmoragrega/paybile/service"
@@ -12,6 +14,10 @@ type WalletGetter interface {
 	GetWallet(ctx context.Context, user service.User, walletID uuid.UUID) (service.Wallet, error)
 }
 
+type BalanceGetter interface {
+	BalanceAt(ctx context.Context, user service.User, walletID uuid.UUID, at time.Time) (service.WalletBalance, error)
+}
+
 func GetWalletHandler(svc WalletGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		walletID, err := uuid.Parse(urlParam(r, "walletID"))
@@ -22,17 +28,38 @@ func GetWalletHandler(svc WalletGetter) http.HandlerFunc {
 
 		x, err := svc.GetWallet(r.Context(), requestUser(r), walletID)
 		if err != nil {
-			switch wrappedErr := wrappedErrOrParent(err); wrappedErr {
-			case service.ErrWalletNotFound:
-				writeError(w, r, notFoundErr.Err(wrappedErr), err)
-			case service.ErrWalletAccessDenied:
-				writeError(w, r, forbiddenErr.Err(wrappedErr), err)
-			default:
-				writeError(w, r, serverErr, err)
-			}
+			writeServiceError(w, r, err)
 			return
 		}
 
 		writeResponse(w, http.StatusOK, x)
 	}
 }
+
+// GetBalanceHandler returns the balance of the wallet at the
+// RFC3339 date given in the "at" query parameter, or now if empty.
+func GetBalanceHandler(svc BalanceGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(urlParam(r, "walletID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		at := time.Now().UTC()
+		if x := r.URL.Query().Get("at"); x != "" {
+			if at, err = time.Parse(time.RFC3339, x); err != nil {
+				writeError(w, r, invalidParameterErr.Err(errors.New("at is not a valid RFC3339 date")), err)
+				return
+			}
+		}
+
+		b, err := svc.BalanceAt(r.Context(), requestUser(r), walletID, at)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, b)
+	}
+}
@@ -1,12 +1,14 @@
 package http_test
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hmoragrega/paybile/service"
@@ -21,6 +23,20 @@ func TestMain(m *testing.M) {
 	m.Run()
 }
 
+// testRequestID the request ID of the test requests, it is
+// the instance of the problem details of the errors.
+const testRequestID = "test-request"
+
+// withRequestID returns a copy of the request with a fixed request ID.
+func withRequestID(r *http.Request) *http.Request {
+	r = r.Clone(context.Background())
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set("X-Request-Id", testRequestID)
+	return r
+}
+
 func TestGetWalletHandler(t *testing.T) {
 	t.Parallel()
 	var (
@@ -52,7 +68,7 @@ func TestGetWalletHandler(t *testing.T) {
 				URL:    &url.URL{Path: "/api/v1/wallet/foo"},
 			},
 			wantStatus: http.StatusUnauthorized,
-			wantBody:   `{"status_code":401,"key":"UnauthorizedErr","error":"unauthorized"}`,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
 		},
 		{
 			name: "invalid wallet id",
@@ -65,7 +81,7 @@ func TestGetWalletHandler(t *testing.T) {
 				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
 			},
 			wantStatus: http.StatusNotFound,
-			wantBody:   `{"status_code":404,"key":"NotFoundErr","error":"not found"}`,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
 		},
 		{
 			name: "wallet not found",
@@ -79,7 +95,7 @@ func TestGetWalletHandler(t *testing.T) {
 				wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, service.ErrWalletNotFound)
 			},
 			wantStatus: http.StatusNotFound,
-			wantBody:   `{"status_code":404,"key":"NotFoundErr","error":"wallet not found"}`,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_not_found","title":"Wallet not found","status":404,"detail":"wallet not found","instance":"test-request","code":"wallet_not_found"}`,
 		},
 		{
 			name: "wallet access denied",
@@ -93,7 +109,7 @@ func TestGetWalletHandler(t *testing.T) {
 				wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, service.ErrWalletAccessDenied)
 			},
 			wantStatus: http.StatusForbidden,
-			wantBody:   `{"status_code":403,"key":"ForbiddenErr","error":"wallet access denied"}`,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
 		},
 		{
 			name: "server error",
@@ -107,7 +123,7 @@ func TestGetWalletHandler(t *testing.T) {
 				wg.On("GetWallet", mock.Anything, user, walletID).Return(service.Wallet{}, fmt.Errorf("dummy"))
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   `{"status_code":500,"key":"InternalServerErr","error":"internal server error"}`,
+			wantBody:   `{"type":"urn:paybile:problem:internal_error","title":"Internal server error","status":500,"detail":"internal server error","instance":"test-request","code":"internal_error"}`,
 		},
 		{
 			name: "ok",
@@ -137,7 +153,112 @@ func TestGetWalletHandler(t *testing.T) {
 				LoginService: ls,
 			}
 			rr := httptest.NewRecorder()
-			api.Handler().ServeHTTP(rr, &tc.req)
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}
+
+func TestGetBalanceHandler(t *testing.T) {
+	t.Parallel()
+	var (
+		user     = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		walletID = uuid.MustParse("b272dc21-e006-4a41-a120-2b8f26b61a67")
+		login    = "foo"
+		pass     = "bar"
+		auth     = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		at       = time.Date(2020, 3, 31, 23, 59, 59, 0, time.UTC)
+		path     = "/api/v1/wallet/" + walletID.String() + "/balance"
+		balance  = service.WalletBalance{
+			WalletID: walletID,
+			Balance:  10.99,
+			At:       at,
+		}
+		headers = map[string][]string{
+			"Authorization": {"Basic " + auth},
+		}
+	)
+	tt := []struct {
+		name       string
+		req        http.Request
+		expect     func(bg *mocks.BalanceGetter, ls *mocks.LoginService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path},
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "invalid date",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path, RawQuery: "at=2020-03-31"},
+				Header: headers,
+			},
+			expect: func(bg *mocks.BalanceGetter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_parameter","title":"Invalid parameter","status":422,"detail":"at is not a valid RFC3339 date","instance":"test-request","code":"invalid_parameter"}`,
+		},
+		{
+			name: "wallet access denied",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path},
+				Header: headers,
+			},
+			expect: func(bg *mocks.BalanceGetter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bg.On("BalanceAt", mock.Anything, user, walletID, mock.Anything).Return(service.WalletBalance{}, service.ErrWalletAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"type":"urn:paybile:problem:wallet_access_denied","title":"Wallet access denied","status":403,"detail":"wallet access denied","instance":"test-request","code":"wallet_access_denied"}`,
+		},
+		{
+			name: "ok",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: path, RawQuery: "at=2020-03-31T23:59:59Z"},
+				Header: headers,
+			},
+			expect: func(bg *mocks.BalanceGetter, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				bg.On("BalanceAt", mock.Anything, user, walletID, at).Return(balance, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"wallet_id":"b272dc21-e006-4a41-a120-2b8f26b61a67","balance":10.99,"at":"2020-03-31T23:59:59Z"}`,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			bg := &mocks.BalanceGetter{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(bg, ls)
+			}
+
+			api := httptransport.ApiService{
+				BalanceGetter: bg,
+				LoginService:  ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
 			res := rr.Result()
 
 			if got := res.StatusCode; got != tc.wantStatus {
@@ -146,6 +267,7 @@ func TestGetWalletHandler(t *testing.T) {
 			if got := rr.Body.String(); got != tc.wantBody {
 				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
 			}
+			assertOpenAPIResponse(t, &tc.req, res)
 		})
 	}
 }
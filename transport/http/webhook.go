@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+)
+
+type WebhookSubscriptionRequest struct {
+	URL        *string             `json:"url"`
+	Secret     *string             `json:"secret"`
+	EventTypes []service.EventType `json:"event_types"`
+}
+
+type WebhookManager interface {
+	CreateWebhook(ctx context.Context, req service.WebhookRequest) (service.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, user service.User) ([]service.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, user service.User, subscriptionID uuid.UUID) error
+	ListWebhookDeliveries(ctx context.Context, user service.User, subscriptionID uuid.UUID) ([]service.WebhookDelivery, error)
+}
+
+type WebhookList struct {
+	Results []service.WebhookSubscription `json:"results"`
+}
+
+type WebhookDeliveryList struct {
+	Results []service.WebhookDelivery `json:"results"`
+}
+
+func CreateWebhookHandler(svc WebhookManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var x WebhookSubscriptionRequest
+		if !decodeBody(w, r, &x) {
+			return
+		}
+
+		var v validator
+		v.required("url", x.URL != nil)
+		v.required("secret", x.Secret != nil)
+		if !v.valid(w, r) {
+			return
+		}
+
+		s, err := svc.CreateWebhook(r.Context(), service.WebhookRequest{
+			User:       requestUser(r),
+			URL:        *x.URL,
+			Secret:     *x.Secret,
+			EventTypes: x.EventTypes,
+		})
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusCreated, s)
+	}
+}
+
+func ListWebhooksHandler(svc WebhookManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l, err := svc.ListWebhooks(r.Context(), requestUser(r))
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, WebhookList{Results: l})
+	}
+}
+
+func DeleteWebhookHandler(svc WebhookManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscriptionID, err := uuid.Parse(urlParam(r, "subscriptionID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		if err = svc.DeleteWebhook(r.Context(), requestUser(r), subscriptionID); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusNoContent, nil)
+	}
+}
+
+// ListWebhookDeliveriesHandler returns the delivery log of the subscription.
+func ListWebhookDeliveriesHandler(svc WebhookManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscriptionID, err := uuid.Parse(urlParam(r, "subscriptionID"))
+		if err != nil {
+			writeError(w, r, notFoundErr, nil)
+			return
+		}
+
+		l, err := svc.ListWebhookDeliveries(r.Context(), requestUser(r), subscriptionID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, WebhookDeliveryList{Results: l})
+	}
+}
@@ -0,0 +1,216 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmoragrega/paybile/service"
+	httptransport "github.com/hmoragrega/paybile/transport/http"
+	"github.com/hmoragrega/paybile/transport/http/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	t.Parallel()
+	var (
+		user           = service.User{ID: uuid.MustParse("f4c34307-e7af-4add-a39b-b65d5627830c")}
+		login          = "foo"
+		pass           = "bar"
+		auth           = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", login, pass)))
+		headers        = map[string][]string{"Authorization": {"Basic " + auth}}
+		subscriptionID = uuid.MustParse("5d1b6f0e-2f4e-4c4b-9d3b-6f0c8f0b5a11")
+		basePath       = "/api/v1/webhooks"
+		hookPath       = basePath + "/" + subscriptionID.String()
+		secret         = "0123456789abcdef"
+		request        = service.WebhookRequest{
+			User:       user,
+			URL:        "https://example.com/hooks",
+			Secret:     secret,
+			EventTypes: []service.EventType{service.TransferCreatedEvent},
+		}
+		hook = service.WebhookSubscription{
+			ID:         subscriptionID,
+			UserID:     user.ID,
+			URL:        request.URL,
+			Secret:     secret,
+			EventTypes: request.EventTypes,
+			CreatedAt:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		statusCode = 500
+		failure    = "unexpected response status 500: boom"
+		delivery   = service.WebhookDelivery{
+			ID:             uuid.MustParse("8d2f6b1e-4a55-4c0c-8f67-7f0f2d0e5c33"),
+			SubscriptionID: subscriptionID,
+			EventID:        uuid.MustParse("0b5e0f4c-3b4b-44e1-a0a5-8b3f1f3f86a1"),
+			EventType:      service.TransferCreatedEvent,
+			Payload:        json.RawMessage(`{"amount":10}`),
+			Status:         service.WebhookDeliveryPending,
+			Attempts:       1,
+			NextAttemptAt:  time.Date(2030, 1, 1, 0, 1, 0, 0, time.UTC),
+			CreatedAt:      time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			Log: []service.WebhookDeliveryAttempt{{
+				Attempt:    1,
+				StatusCode: &statusCode,
+				Error:      &failure,
+				DurationMS: 12,
+				Date:       time.Date(2030, 1, 1, 0, 0, 1, 0, time.UTC),
+			}},
+		}
+	)
+	hookJSON := `{"id":"5d1b6f0e-2f4e-4c4b-9d3b-6f0c8f0b5a11","user_id":"f4c34307-e7af-4add-a39b-b65d5627830c",` +
+		`"url":"https://example.com/hooks","event_types":["transfer.created"],"created_at":"2030-01-01T00:00:00Z"}`
+	deliveriesJSON := `{"results":[{"id":"8d2f6b1e-4a55-4c0c-8f67-7f0f2d0e5c33",` +
+		`"subscription_id":"5d1b6f0e-2f4e-4c4b-9d3b-6f0c8f0b5a11","event_id":"0b5e0f4c-3b4b-44e1-a0a5-8b3f1f3f86a1",` +
+		`"event_type":"transfer.created","payload":{"amount":10},"status":"pending","attempts":1,` +
+		`"next_attempt_at":"2030-01-01T00:01:00Z","created_at":"2030-01-01T00:00:00Z","delivered_at":null,` +
+		`"log":[{"attempt":1,"status_code":500,"error":"unexpected response status 500: boom","duration_ms":12,` +
+		`"date":"2030-01-01T00:00:01Z"}]}]}`
+
+	buildReq := func(method string, path string, body string) http.Request {
+		r, _ := http.NewRequest(method, "", bytes.NewBuffer([]byte(body)))
+		r.Header = headers
+		r.URL = &url.URL{Path: path}
+		return *r
+	}
+
+	tt := []struct {
+		name       string
+		req        http.Request
+		expect     func(wm *mocks.WebhookManager, ls *mocks.LoginService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "unauthorized",
+			req: http.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Path: basePath},
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"type":"urn:paybile:problem:unauthorized","title":"Unauthorized","status":401,"detail":"unauthorized","instance":"test-request","code":"unauthorized"}`,
+		},
+		{
+			name: "create missing secret",
+			req:  buildReq(http.MethodPost, basePath, `{"url": "https://example.com/hooks"}`),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_body","title":"Invalid request body","status":400,"detail":"invalid request body","instance":"test-request","code":"invalid_body","details":[{"field":"secret","code":"required","message":"missing secret"}]}`,
+		},
+		{
+			name: "create invalid",
+			req:  buildReq(http.MethodPost, basePath, `{"url": "https://example.com/hooks", "secret": "0123456789abcdef", "event_types": ["transfer.created"]}`),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("CreateWebhook", mock.Anything, request).
+					Return(service.WebhookSubscription{}, fmt.Errorf("%w: no event types", service.ErrInvalidWebhook))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"urn:paybile:problem:invalid_webhook","title":"Invalid webhook","status":422,"detail":"invalid webhook: no event types","instance":"test-request","code":"invalid_webhook"}`,
+		},
+		{
+			name: "create ok",
+			req:  buildReq(http.MethodPost, basePath, `{"url": "https://example.com/hooks", "secret": "0123456789abcdef", "event_types": ["transfer.created"]}`),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("CreateWebhook", mock.Anything, request).Return(hook, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   hookJSON,
+		},
+		{
+			name: "list ok",
+			req:  buildReq(http.MethodGet, basePath, ""),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("ListWebhooks", mock.Anything, user).Return([]service.WebhookSubscription{hook}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"results":[` + hookJSON + `]}`,
+		},
+		{
+			name: "delete invalid ID",
+			req:  buildReq(http.MethodDelete, basePath+"/foo", ""),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:not_found","title":"Not found","status":404,"detail":"not found","instance":"test-request","code":"not_found"}`,
+		},
+		{
+			name: "delete not found",
+			req:  buildReq(http.MethodDelete, hookPath, ""),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("DeleteWebhook", mock.Anything, user, subscriptionID).Return(service.ErrWebhookNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"urn:paybile:problem:webhook_not_found","title":"Webhook not found","status":404,"detail":"webhook not found","instance":"test-request","code":"webhook_not_found"}`,
+		},
+		{
+			name: "delete ok",
+			req:  buildReq(http.MethodDelete, hookPath, ""),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("DeleteWebhook", mock.Anything, user, subscriptionID).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+			wantBody:   "",
+		},
+		{
+			name: "deliveries error",
+			req:  buildReq(http.MethodGet, hookPath+"/deliveries", ""),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("ListWebhookDeliveries", mock.Anything, user, subscriptionID).Return(nil, fmt.Errorf("dummy"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"type":"urn:paybile:problem:internal_error","title":"Internal server error","status":500,"detail":"internal server error","instance":"test-request","code":"internal_error"}`,
+		},
+		{
+			name: "deliveries ok",
+			req:  buildReq(http.MethodGet, hookPath+"/deliveries", ""),
+			expect: func(wm *mocks.WebhookManager, ls *mocks.LoginService) {
+				ls.On("Login", mock.Anything, login, pass).Return(user, nil)
+				wm.On("ListWebhookDeliveries", mock.Anything, user, subscriptionID).
+					Return([]service.WebhookDelivery{delivery}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   deliveriesJSON,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			wm := &mocks.WebhookManager{}
+			ls := &mocks.LoginService{}
+			if tc.expect != nil {
+				tc.expect(wm, ls)
+			}
+
+			api := httptransport.ApiService{
+				Webhooks:     wm,
+				LoginService: ls,
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, withRequestID(&tc.req))
+			res := rr.Result()
+
+			if got := res.StatusCode; got != tc.wantStatus {
+				t.Fatalf("unexpected status: \n got:  %+v \n want: %+v", got, tc.wantStatus)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Fatalf("unexpected body: \n got:  %+v \n want: %+v", got, tc.wantBody)
+			}
+			assertOpenAPIResponse(t, &tc.req, res)
+		})
+	}
+}